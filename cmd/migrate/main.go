@@ -0,0 +1,111 @@
+// migrate 数据库迁移命令：
+//
+//	go run ./cmd/migrate up              执行全部未执行的迁移
+//	go run ./cmd/migrate down [n]        回滚最近 n 个迁移（默认 1）
+//	go run ./cmd/migrate status          查看迁移状态
+//	go run ./cmd/migrate create <name>   在 internal/migration/sql 下创建新的迁移文件
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"copycat/config"
+	"copycat/internal/migration"
+)
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "配置文件路径")
+	dir := flag.String("dir", migration.DefaultDir, "迁移文件目录（create 使用）")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: migrate [flags] up | down [n] | status | create <name>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// create 只生成文件，不需要连接数据库
+	if args[0] == "create" {
+		if len(args) < 2 {
+			log.Fatal("Usage: migrate create <name>")
+		}
+		up, down, err := migration.Create(*dir, args[1])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		fmt.Printf("Created %s\nCreated %s\n", up, down)
+		return
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	db, err := config.InitDatabase(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect database: %v", err)
+	}
+	migrator, err := migration.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatalf("Invalid step count: %s", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			fmt.Printf("Rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("Nothing to roll back")
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to get status: %v", err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, applied)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
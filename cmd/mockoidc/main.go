@@ -0,0 +1,253 @@
+// mockoidc 本地测试用的 OIDC 身份提供方：授权请求直接以命令行指定的用户登录（不需要输入密码），
+// 支持 discovery、JWKS、授权码 + PKCE(S256) 和 RS256 签名的 ID Token。不要在生产环境使用
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	keyID   = "mock-key"
+	codeTTL = 2 * time.Minute
+	idTTL   = 10 * time.Minute
+)
+
+// authCode 授权码及其关联的授权请求
+type authCode struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+type server struct {
+	issuer        string
+	clientID      string
+	clientSecret  string
+	email         string
+	name          string
+	subject       string
+	emailVerified bool
+	key           *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authCode
+}
+
+func main() {
+	addr := flag.String("addr", ":9999", "监听地址")
+	issuer := flag.String("issuer", "http://localhost:9999", "issuer（需与 copycat 的 oidc.issuer 一致）")
+	clientID := flag.String("client-id", "copycat", "客户端 ID")
+	clientSecret := flag.String("client-secret", "", "客户端密钥（为空时不校验）")
+	email := flag.String("email", "dev@example.com", "登录用户的邮箱")
+	name := flag.String("name", "Dev User", "登录用户的姓名")
+	subject := flag.String("sub", "", "登录用户的 sub（默认按邮箱生成）")
+	emailVerified := flag.Bool("email-verified", true, "ID Token 中的 email_verified")
+	flag.Parse()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("Failed to generate signing key: %v", err)
+	}
+	if *subject == "" {
+		sum := sha256.Sum256([]byte(*email))
+		*subject = fmt.Sprintf("mock-%x", sum[:8])
+	}
+
+	s := &server{
+		issuer:        strings.TrimRight(*issuer, "/"),
+		clientID:      *clientID,
+		clientSecret:  *clientSecret,
+		email:         *email,
+		name:          *name,
+		subject:       *subject,
+		emailVerified: *emailVerified,
+		key:           key,
+		codes:         make(map[string]authCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/jwks", s.jwks)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+
+	log.Printf("Mock OIDC provider listening on %s (issuer=%s, user=%s)", *addr, s.issuer, s.email)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
+
+func (s *server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.issuer,
+		"authorization_endpoint":                s.issuer + "/authorize",
+		"token_endpoint":                        s.issuer + "/token",
+		"jwks_uri":                              s.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+	})
+}
+
+func (s *server) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+// authorize 直接以配置的用户完成登录，带授权码重定向回客户端
+func (s *server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	if q.Get("client_id") != s.clientID || redirectURI == "" {
+		http.Error(w, "invalid client_id or redirect_uri", http.StatusBadRequest)
+		return
+	}
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	params := url.Values{"state": {q.Get("state")}}
+	switch {
+	case q.Get("response_type") != "code":
+		params.Set("error", "unsupported_response_type")
+	case q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256":
+		params.Set("error", "invalid_request")
+		params.Set("error_description", "PKCE S256 is required")
+	default:
+		code := randomString()
+		s.mu.Lock()
+		s.codes[code] = authCode{
+			clientID:      s.clientID,
+			redirectURI:   redirectURI,
+			nonce:         q.Get("nonce"),
+			codeChallenge: q.Get("code_challenge"),
+			expiresAt:     time.Now().Add(codeTTL),
+		}
+		s.mu.Unlock()
+		params.Set("code", code)
+	}
+
+	target.RawQuery = params.Encode()
+	log.Printf("authorize: redirecting to %s", redirectURI)
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// token 授权码换取 ID Token，校验客户端、redirect_uri 和 PKCE
+func (s *server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		tokenError(w, http.StatusMethodNotAllowed, "invalid_request", "POST required")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != s.clientID || (s.clientSecret != "" && clientSecret != s.clientSecret) {
+		tokenError(w, http.StatusUnauthorized, "invalid_client", "")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+		return
+	}
+
+	// 授权码只能使用一次
+	s.mu.Lock()
+	code, found := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+	if !found || time.Now().After(code.expiresAt) || code.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "invalid or expired code")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != code.codeChallenge {
+		tokenError(w, http.StatusBadRequest, "invalid_grant", "PKCE verification failed")
+		return
+	}
+
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            s.issuer,
+		"sub":            s.subject,
+		"aud":            s.clientID,
+		"exp":            now.Add(idTTL).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          code.nonce,
+		"email":          s.email,
+		"email_verified": s.emailVerified,
+		"name":           s.name,
+	})
+	idToken.Header["kid"] = keyID
+	signed, err := idToken.SignedString(s.key)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   int(idTTL.Seconds()),
+		"id_token":     signed,
+	})
+}
+
+func tokenError(w http.ResponseWriter, status int, code, description string) {
+	body := map[string]string{"error": code}
+	if description != "" {
+		body["error_description"] = description
+	}
+	writeJSON(w, status, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
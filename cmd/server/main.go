@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
 	"copycat/config"
 	"copycat/internal/api"
-	"copycat/internal/model"
+	"copycat/internal/core/oidc"
+	"copycat/internal/migration"
+	"copycat/internal/repository"
 	"copycat/pkg/logger"
+	"copycat/pkg/mailer"
+	"copycat/pkg/storage"
 )
 
 func main() {
@@ -39,15 +44,56 @@ func main() {
 		log.Fatalf("Failed to connect database: %v", err)
 	}
 
-	// 4. 自动迁移（注意顺序：BatchTask 需要在 Project 之前，因为 Project 有外键引用 BatchTask）
-	if err := config.AutoMigrate(db, &model.User{}, &model.UserSettings{}, &model.BatchTask{}, &model.Project{}); err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+	// 4. 检查数据库迁移：有未执行的迁移时拒绝启动（先执行 go run ./cmd/migrate up，
+	// 或配置 database.migrate_on_start 在启动时自动执行）
+	migrator, err := migration.New(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if cfg.Database.MigrateOnStart {
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		log.Fatalf("Database schema is not up to date, run `go run ./cmd/migrate up` first: %v", err)
+	}
+
+	// 把 admin.emails 中已验证邮箱的账号设为管理员（邮箱未验证的账号验证后需重启生效）
+	if promoted, err := repository.NewUserRepository(db).PromoteAdmins(context.Background(), cfg.Admin.Emails); err != nil {
+		log.Fatalf("Failed to promote admins: %v", err)
+	} else if promoted > 0 {
+		log.Printf("Promoted %d configured admin accounts", promoted)
+	}
+
+	// 5. 初始化文件存储
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to init storage: %v", err)
+	}
+
+	// 6. 初始化邮件发送（注册验证、找回密码）
+	mail, err := mailer.New(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to init mailer: %v", err)
 	}
 
-	// 5. 设置路由
-	r := api.SetupRouter(db)
+	// 7. 初始化 OIDC 单点登录（未启用时为 nil）
+	var sso *oidc.Provider
+	if cfg.OIDC.Enabled {
+		if sso, err = oidc.NewProvider(cfg.OIDC); err != nil {
+			log.Fatalf("Failed to init oidc provider: %v", err)
+		}
+	}
+
+	// 8. 设置路由
+	r := api.SetupRouter(db, store, mail, sso)
+	// 只信任配置的反向代理转发的客户端 IP，否则任何人都能伪造 X-Forwarded-For 绕过按 IP 的登录限流
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
 
-	// 6. 启动服务
+	// 9. 启动服务
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
 	log.Printf("Server listening on http://localhost%s", addr)
 	log.Printf("API Documentation: http://localhost%s/api/v1", addr)
@@ -4,20 +4,35 @@ import (
 	"fmt"
 	"strings"
 
+	"copycat/internal/core/auth"
+	"copycat/internal/core/oidc"
+	"copycat/internal/core/tts"
+	"copycat/internal/core/video"
+	"copycat/pkg/mailer"
+	"copycat/pkg/storage"
+
 	"github.com/spf13/viper"
 )
 
 // Config 应用配置结构体
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	App      AppConfig      `mapstructure:"app"`
-	Database DatabaseConfig `mapstructure:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
+	Server   ServerConfig          `mapstructure:"server"`
+	App      AppConfig             `mapstructure:"app"`
+	Database DatabaseConfig        `mapstructure:"database"`
+	JWT      auth.Config           `mapstructure:"jwt"`
+	Storage  storage.Config        `mapstructure:"storage"`
+	TTS      tts.Config            `mapstructure:"tts"`
+	Video    video.Config          `mapstructure:"video"`
+	Mail     mailer.Config         `mapstructure:"mail"`
+	OIDC     oidc.Config           `mapstructure:"oidc"`
+	Login    auth.LoginGuardConfig `mapstructure:"login"`
+	Admin    AdminConfig           `mapstructure:"admin"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Port int `mapstructure:"port"`
+	Port           int      `mapstructure:"port"`
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // 可信反向代理的 IP 或网段，为空时不信任 X-Forwarded-For
 }
 
 // AppConfig 应用配置
@@ -35,12 +50,13 @@ type DatabaseConfig struct {
 	DBName   string `mapstructure:"dbname"`
 	SSLMode  string `mapstructure:"sslmode"`
 	Timezone string `mapstructure:"timezone"`
+
+	MigrateOnStart bool `mapstructure:"migrate_on_start"` // 启动时自动执行未执行的迁移（默认只检查）
 }
 
-// JWTConfig JWT 配置
-type JWTConfig struct {
-	Secret      string `mapstructure:"secret"`
-	ExpireHours int    `mapstructure:"expire_hours"`
+// AdminConfig 管理员配置
+type AdminConfig struct {
+	Emails []string `mapstructure:"emails"` // 启动时提升为管理员的邮箱（账号需已验证邮箱）
 }
 
 // DSN 返回 PostgreSQL 连接字符串
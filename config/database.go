@@ -48,12 +48,3 @@ func InitDatabase(cfg *DatabaseConfig) (*gorm.DB, error) {
 
 	return db, nil
 }
-
-// AutoMigrate 自动迁移数据库表结构
-func AutoMigrate(db *gorm.DB, models ...interface{}) error {
-	if err := db.AutoMigrate(models...); err != nil {
-		return fmt.Errorf("failed to auto migrate: %w", err)
-	}
-	log.Println("Database migration completed")
-	return nil
-}
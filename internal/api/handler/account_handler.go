@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"copycat/internal/core/auth"
+	"copycat/internal/core/userdata"
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AccountHandler 账号数据导出和注销处理器
+type AccountHandler struct {
+	userRepo repository.UserRepository
+	userdata *userdata.Service
+}
+
+// NewAccountHandler 创建账号数据处理器
+func NewAccountHandler(userRepo repository.UserRepository, userdata *userdata.Service) *AccountHandler {
+	return &AccountHandler{userRepo: userRepo, userdata: userdata}
+}
+
+// DeleteAccountRequest 注销账号请求
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"` // 当前密码（单点登录账号可先通过找回密码设置）
+	Confirm  string `json:"confirm" binding:"required"`  // 再次输入账号邮箱确认
+}
+
+// StartExport 创建数据导出任务，后台打包完成后可下载
+// @Summary 导出账号数据
+// @Tags Account
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.ExportJob}
+// @Router /account/export [post]
+func (h *AccountHandler) StartExport(c *gin.Context) {
+	job, err := h.userdata.StartExport(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		if errors.Is(err, userdata.ErrExportInProgress) {
+			response.BadRequest(c, "已有导出任务正在进行，请稍后再试")
+			return
+		}
+		log.Printf("[API] 创建导出任务失败: %v", err)
+		response.ServerError(c, "创建导出任务失败")
+		return
+	}
+	auditEntry(c).SetTarget("export_job", job.ID)
+	response.SuccessWithMessage(c, "导出任务已创建", job)
+}
+
+// GetExport 获取最近一次导出任务的状态
+// @Summary 查询导出任务
+// @Tags Account
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.ExportJob}
+// @Router /account/export [get]
+func (h *AccountHandler) GetExport(c *gin.Context) {
+	job, err := h.userdata.LatestExport(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		if errors.Is(err, userdata.ErrExportNotFound) {
+			response.NotFound(c, "没有导出任务")
+			return
+		}
+		log.Printf("[API] 查询导出任务失败: %v", err)
+		response.ServerError(c, "查询导出任务失败")
+		return
+	}
+	response.Success(c, job)
+}
+
+// DownloadExport 下载最近一次导出的 zip
+// @Summary 下载导出文件
+// @Tags Account
+// @Security BearerAuth
+// @Produce application/zip
+// @Router /account/export/download [get]
+func (h *AccountHandler) DownloadExport(c *gin.Context) {
+	job, obj, err := h.userdata.OpenExport(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		switch {
+		case errors.Is(err, userdata.ErrExportNotFound):
+			response.NotFound(c, "没有导出任务")
+		case errors.Is(err, userdata.ErrExportNotReady):
+			response.BadRequest(c, "导出尚未完成")
+		case errors.Is(err, userdata.ErrExportExpired):
+			response.NotFound(c, "导出文件已过期，请重新导出")
+		default:
+			log.Printf("[API] 打开导出文件失败: %v", err)
+			response.ServerError(c, "读取导出文件失败")
+		}
+		return
+	}
+	defer obj.Close()
+
+	filename := fmt.Sprintf("copycat-export-%s.zip", job.CreatedAt.Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	http.ServeContent(c.Writer, c.Request, filename, obj.ModTime(), obj)
+}
+
+// DeleteAccount 注销账号，删除用户的全部数据和文件（不可恢复）
+// @Summary 注销账号
+// @Tags Account
+// @Security BearerAuth
+// @Param request body DeleteAccountRequest true "确认信息"
+// @Success 200 {object} response.Response
+// @Router /account [delete]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		response.NotFound(c, "用户不存在")
+		return
+	}
+	if auth.NormalizeEmail(req.Confirm) != auth.NormalizeEmail(user.Email) {
+		response.BadRequest(c, "确认邮箱与账号不一致")
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		response.BadRequest(c, "密码错误")
+		return
+	}
+
+	if err := h.userdata.DeleteAccount(c.Request.Context(), user.ID); err != nil {
+		var soleOwner *userdata.SoleOwnerError
+		if errors.As(err, &soleOwner) {
+			response.BadRequest(c, fmt.Sprintf("你是工作区「%s」的唯一所有者，请先转让所有权或移除其他成员", soleOwner.Workspace))
+			return
+		}
+		log.Printf("[API] 注销账号失败: user=%d err=%v", user.ID, err)
+		response.ServerError(c, "注销账号失败")
+		return
+	}
+
+	entry := auditEntry(c)
+	entry.SetTarget("user", user.ID)
+	entry.AddDetail("email", user.Email)
+	log.Printf("[API] 用户 %d 已注销账号", user.ID)
+	response.SuccessWithMessage(c, "账号已注销", nil)
+}
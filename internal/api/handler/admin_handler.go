@@ -0,0 +1,464 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"copycat/internal/core/auth"
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 系统设置取值范围
+const (
+	maxGenerateCountLimit = 50
+	maxBatchConcurrency   = 20
+)
+
+// AdminHandler 管理员处理器
+type AdminHandler struct {
+	userRepo      repository.UserRepository
+	usageRepo     repository.UsageRepository
+	projectRepo   repository.ProjectRepository
+	batchTaskRepo *repository.BatchTaskRepository
+	systemRepo    repository.SystemSettingsRepository
+	loginRepo     repository.LoginAttemptRepository
+	guard         *auth.LoginGuard
+	tokens        *auth.TokenService
+}
+
+// NewAdminHandler 创建管理员处理器
+func NewAdminHandler(userRepo repository.UserRepository, usageRepo repository.UsageRepository, projectRepo repository.ProjectRepository, batchTaskRepo *repository.BatchTaskRepository, systemRepo repository.SystemSettingsRepository, loginRepo repository.LoginAttemptRepository, guard *auth.LoginGuard, tokens *auth.TokenService) *AdminHandler {
+	return &AdminHandler{
+		userRepo:      userRepo,
+		usageRepo:     usageRepo,
+		projectRepo:   projectRepo,
+		batchTaskRepo: batchTaskRepo,
+		systemRepo:    systemRepo,
+		loginRepo:     loginRepo,
+		guard:         guard,
+		tokens:        tokens,
+	}
+}
+
+// AdminListRequest 管理后台分页查询参数
+type AdminListRequest struct {
+	Keyword  string `form:"keyword"`
+	UserID   int64  `form:"user_id"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// AdminUserDetail 用户详情（含用量统计）
+type AdminUserDetail struct {
+	User  *model.User      `json:"user"`
+	Usage *model.UserUsage `json:"usage"`
+}
+
+// AdminUpdateUserRequest 修改用户状态请求（字段为空表示不修改）
+type AdminUpdateUserRequest struct {
+	Role     *string `json:"role" binding:"omitempty,oneof=user admin"`
+	Disabled *bool   `json:"disabled"`
+}
+
+// SystemSettingsRequest 修改系统设置请求
+type SystemSettingsRequest struct {
+	DefaultLLMProvider   string `json:"default_llm_provider" binding:"required"`
+	DefaultLLMModel      string `json:"default_llm_model" binding:"required,max=100"`
+	DefaultImageLLMModel string `json:"default_image_llm_model" binding:"required,max=100"`
+	DefaultVideoLLMModel string `json:"default_video_llm_model" binding:"required,max=100"`
+	DefaultGenerateCount int    `json:"default_generate_count" binding:"required,min=1"`
+	MaxGenerateCount     int    `json:"max_generate_count" binding:"required,min=1"`
+	BatchConcurrency     int    `json:"batch_concurrency" binding:"required,min=1"`
+}
+
+// ListUsers 分页查询用户，支持按邮箱或昵称搜索
+// @Summary 查询用户列表
+// @Tags Admin
+// @Security BearerAuth
+// @Param keyword query string false "邮箱或昵称关键字"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} response.Response{data=response.PageData}
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	req, ok := bindAdminList(c)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.userRepo.List(c.Request.Context(), req.Keyword, req.Page, req.PageSize)
+	if err != nil {
+		log.Printf("[API] 查询用户列表失败: %v", err)
+		response.ServerError(c, "查询失败")
+		return
+	}
+	response.SuccessWithPage(c, users, total, req.Page, req.PageSize)
+}
+
+// GetUser 获取用户详情和用量统计
+// @Summary 获取用户详情
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=AdminUserDetail}
+// @Router /admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	usage, err := h.usageRepo.GetUserUsage(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Printf("[API] 统计用户用量失败: user=%d err=%v", user.ID, err)
+		response.ServerError(c, "查询失败")
+		return
+	}
+	response.Success(c, AdminUserDetail{User: user, Usage: usage})
+}
+
+// UpdateUser 禁用/启用账号或修改系统角色。禁用后该用户所有已登录的会话立即失效
+// @Summary 修改用户状态
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body AdminUpdateUserRequest true "修改内容"
+// @Success 200 {object} response.Response{data=model.User}
+// @Router /admin/users/{id} [put]
+func (h *AdminHandler) UpdateUser(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	var req AdminUpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 不能禁用自己或取消自己的管理员角色，避免没有管理员可用
+	if user.ID == c.GetInt64("userID") {
+		if (req.Disabled != nil && *req.Disabled) || (req.Role != nil && *req.Role != model.UserRoleAdmin) {
+			response.BadRequest(c, "不能禁用自己或取消自己的管理员角色")
+			return
+		}
+	}
+
+	before := *user
+	disabling := req.Disabled != nil && *req.Disabled && !user.Disabled
+	if req.Disabled != nil {
+		user.Disabled = *req.Disabled
+	}
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
+	if err := h.userRepo.Update(c.Request.Context(), user); err != nil {
+		log.Printf("[API] 修改用户状态失败: user=%d err=%v", user.ID, err)
+		response.ServerError(c, "修改失败")
+		return
+	}
+	if disabling {
+		if err := h.tokens.RevokeAll(c.Request.Context(), user.ID); err != nil {
+			log.Printf("[API] 吊销被禁用用户的令牌失败: user=%d err=%v", user.ID, err)
+			response.ServerError(c, "账号已禁用，但吊销登录会话失败")
+			return
+		}
+	}
+
+	entry := auditEntry(c)
+	entry.SetTarget("user", user.ID)
+	entry.SetChanges(&before, user)
+	log.Printf("[API] 管理员 %d 修改用户 %d: role=%s disabled=%t", c.GetInt64("userID"), user.ID, user.Role, user.Disabled)
+	response.SuccessWithMessage(c, "修改成功", user)
+}
+
+// ListUserBatches 查询用户的批量任务（跨工作区）
+// @Summary 查询用户的批量任务
+// @Tags Admin
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} response.Response{data=response.PageData}
+// @Router /admin/users/{id}/batches [get]
+func (h *AdminHandler) ListUserBatches(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+	req, ok := bindAdminList(c)
+	if !ok {
+		return
+	}
+
+	tasks, total, err := h.batchTaskRepo.FindByUserID(user.ID, req.PageSize, (req.Page-1)*req.PageSize)
+	if err != nil {
+		log.Printf("[API] 查询用户批量任务失败: user=%d err=%v", user.ID, err)
+		response.ServerError(c, "查询失败")
+		return
+	}
+	response.SuccessWithPage(c, tasks, total, req.Page, req.PageSize)
+}
+
+// ListBatchFailures 查询所有用户批量任务中处理失败的条目
+// @Summary 查询批量任务失败条目
+// @Tags Admin
+// @Security BearerAuth
+// @Param user_id query int false "用户ID"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} response.Response{data=response.PageData}
+// @Router /admin/batch-failures [get]
+func (h *AdminHandler) ListBatchFailures(c *gin.Context) {
+	req, ok := bindAdminList(c)
+	if !ok {
+		return
+	}
+
+	projects, total, err := h.projectRepo.ListBatchFailures(c.Request.Context(), req.UserID, req.Page, req.PageSize)
+	if err != nil {
+		log.Printf("[API] 查询批量失败条目失败: %v", err)
+		response.ServerError(c, "查询失败")
+		return
+	}
+
+	// 失败条目只需要来源和原因，不返回爬取内容和分析结果
+	list := make([]gin.H, 0, len(projects))
+	for _, p := range projects {
+		list = append(list, gin.H{
+			"id":            p.ID,
+			"user_id":       p.UserID,
+			"workspace_id":  p.WorkspaceID,
+			"batch_task_id": p.BatchTaskID,
+			"source_url":    p.SourceURL,
+			"status":        p.Status,
+			"error":         p.BatchError,
+			"updated_at":    p.UpdatedAt,
+		})
+	}
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetSystemSettings 获取系统默认设置
+// @Summary 获取系统设置
+// @Tags Admin
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.SystemSettings}
+// @Router /admin/system-settings [get]
+func (h *AdminHandler) GetSystemSettings(c *gin.Context) {
+	settings, err := h.systemRepo.Get(c.Request.Context())
+	if err != nil {
+		log.Printf("[API] 获取系统设置失败: %v", err)
+		response.ServerError(c, "查询失败")
+		return
+	}
+	response.Success(c, settings)
+}
+
+// SaveSystemSettings 修改系统默认设置（默认模型、仿写条数和批量并发数）
+// @Summary 修改系统设置
+// @Tags Admin
+// @Security BearerAuth
+// @Param request body SystemSettingsRequest true "系统设置"
+// @Success 200 {object} response.Response{data=model.SystemSettings}
+// @Router /admin/system-settings [put]
+func (h *AdminHandler) SaveSystemSettings(c *gin.Context) {
+	var req SystemSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if !validLLMProvider(req.DefaultLLMProvider) {
+		response.BadRequest(c, "不支持的服务商: "+req.DefaultLLMProvider)
+		return
+	}
+	if req.MaxGenerateCount > maxGenerateCountLimit {
+		response.BadRequest(c, "仿写条数上限不能超过 "+strconv.Itoa(maxGenerateCountLimit))
+		return
+	}
+	if req.DefaultGenerateCount > req.MaxGenerateCount {
+		response.BadRequest(c, "默认仿写条数不能超过上限")
+		return
+	}
+	if req.BatchConcurrency > maxBatchConcurrency {
+		response.BadRequest(c, "批量并发数不能超过 "+strconv.Itoa(maxBatchConcurrency))
+		return
+	}
+
+	before, err := h.systemRepo.Get(c.Request.Context())
+	if err != nil {
+		log.Printf("[API] 获取系统设置失败: %v", err)
+		response.ServerError(c, "保存失败")
+		return
+	}
+
+	settings := &model.SystemSettings{
+		DefaultLLMProvider:   req.DefaultLLMProvider,
+		DefaultLLMModel:      req.DefaultLLMModel,
+		DefaultImageLLMModel: req.DefaultImageLLMModel,
+		DefaultVideoLLMModel: req.DefaultVideoLLMModel,
+		DefaultGenerateCount: req.DefaultGenerateCount,
+		MaxGenerateCount:     req.MaxGenerateCount,
+		BatchConcurrency:     req.BatchConcurrency,
+		UpdatedBy:            c.GetInt64("userID"),
+	}
+	if err := h.systemRepo.Save(c.Request.Context(), settings); err != nil {
+		log.Printf("[API] 保存系统设置失败: %v", err)
+		response.ServerError(c, "保存失败")
+		return
+	}
+	entry := auditEntry(c)
+	entry.SetTarget("system_settings", model.SystemSettingsID)
+	entry.SetChanges(before, settings)
+	log.Printf("[API] 管理员 %d 修改系统设置: %+v", settings.UpdatedBy, req)
+	response.SuccessWithMessage(c, "保存成功", settings)
+}
+
+// UnlockLoginRequest 解锁请求（邮箱和 IP 至少填一个）
+type UnlockLoginRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
+
+// ListLoginLocks 获取当前被锁定的账号和 IP
+// @Summary 获取登录锁定列表
+// @Tags Admin
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.LoginThrottle}
+// @Router /admin/login-locks [get]
+func (h *AdminHandler) ListLoginLocks(c *gin.Context) {
+	locks, err := h.loginRepo.ListLocked(c.Request.Context())
+	if err != nil {
+		log.Printf("[API] 查询登录锁定失败: %v", err)
+		response.ServerError(c, "查询失败")
+		return
+	}
+	response.Success(c, locks)
+}
+
+// UnlockLogin 解锁账号或 IP，清除连续失败计数
+// @Summary 解锁登录
+// @Tags Admin
+// @Security BearerAuth
+// @Param request body UnlockLoginRequest true "解锁对象"
+// @Success 200 {object} response.Response
+// @Router /admin/login-locks/unlock [post]
+func (h *AdminHandler) UnlockLogin(c *gin.Context) {
+	var req UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if req.Email == "" && req.IP == "" {
+		response.BadRequest(c, "请指定邮箱或 IP")
+		return
+	}
+
+	var keys []string
+	if req.Email != "" {
+		keys = append(keys, auth.EmailThrottleKey(req.Email))
+	}
+	if req.IP != "" {
+		keys = append(keys, auth.IPThrottleKey(req.IP))
+	}
+
+	unlocked := false
+	for _, key := range keys {
+		ok, err := h.guard.Unlock(c.Request.Context(), key)
+		if err != nil {
+			log.Printf("[API] 解锁登录失败: key=%s err=%v", key, err)
+			response.ServerError(c, "解锁失败")
+			return
+		}
+		unlocked = unlocked || ok
+	}
+	if !unlocked {
+		response.NotFound(c, "没有需要解锁的记录")
+		return
+	}
+	entry := auditEntry(c)
+	entry.SetTarget("login_throttle", "")
+	entry.AddDetail("keys", keys)
+	log.Printf("[API] 管理员 %d 解锁登录: email=%s ip=%s", c.GetInt64("userID"), req.Email, req.IP)
+	response.SuccessWithMessage(c, "解锁成功", nil)
+}
+
+// ListLoginAttempts 查询最近的登录记录
+// @Summary 查询登录记录
+// @Tags Admin
+// @Security BearerAuth
+// @Param email query string false "邮箱"
+// @Param ip query string false "IP"
+// @Param limit query int false "条数(默认100,最大500)"
+// @Success 200 {object} response.Response{data=[]model.LoginAttempt}
+// @Router /admin/login-attempts [get]
+func (h *AdminHandler) ListLoginAttempts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	email := c.Query("email")
+	if email != "" {
+		email = auth.NormalizeEmail(email)
+	}
+
+	attempts, err := h.loginRepo.List(c.Request.Context(), email, c.Query("ip"), limit)
+	if err != nil {
+		log.Printf("[API] 查询登录记录失败: %v", err)
+		response.ServerError(c, "查询失败")
+		return
+	}
+	response.Success(c, attempts)
+}
+
+// loadUser 按路径参数加载用户，失败时已写入响应
+func (h *AdminHandler) loadUser(c *gin.Context) (*model.User, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(c, "无效的用户ID")
+		return nil, false
+	}
+	user, err := h.userRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.NotFound(c, "用户不存在")
+		} else {
+			response.ServerError(c, "查询用户失败")
+		}
+		return nil, false
+	}
+	return user, true
+}
+
+// bindAdminList 解析分页参数（默认第 1 页，每页 20 条），失败时已写入响应
+func bindAdminList(c *gin.Context) (*AdminListRequest, bool) {
+	var req AdminListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return nil, false
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+	return &req, true
+}
+
+// validLLMProvider 是否为支持的 LLM 服务商
+func validLLMProvider(provider string) bool {
+	switch provider {
+	case model.LLMProviderOpenAI, model.LLMProviderDeepSeek, model.LLMProviderAnthropic, model.LLMProviderMoonshot,
+		model.LLMProviderQwen, model.LLMProviderHunyuan, model.LLMProviderDoubao, model.LLMProviderZhipu:
+		return true
+	}
+	return false
+}
@@ -5,8 +5,14 @@ import (
 	"encoding/json"
 	"log"
 	"strings"
+	"sync"
 
+	"copycat/internal/core/compliance"
+	"copycat/internal/core/crawler"
 	"copycat/internal/core/llm"
+	"copycat/internal/core/media"
+	"copycat/internal/core/policy"
+	"copycat/internal/core/similarity"
 	"copycat/internal/model"
 	"copycat/internal/repository"
 	"copycat/pkg/logger"
@@ -19,17 +25,27 @@ import (
 
 // AnalysisHandler 分析理器
 type AnalysisHandler struct {
-	db           *gorm.DB
-	settingsRepo *repository.UserSettingsRepository
-	projectRepo  repository.ProjectRepository
+	db             *gorm.DB
+	settingsRepo   *repository.UserSettingsRepository
+	projectRepo    repository.ProjectRepository
+	generationRepo repository.GenerationRepository
+	workspaceRepo  repository.WorkspaceRepository
+	systemRepo     repository.SystemSettingsRepository
+	mediaCache     *media.Cache
+	authorizer     *policy.Authorizer
 }
 
 // NewAnalysisHandler 创建分析理器
-func NewAnalysisHandler(db *gorm.DB) *AnalysisHandler {
+func NewAnalysisHandler(db *gorm.DB, mediaCache *media.Cache, authorizer *policy.Authorizer) *AnalysisHandler {
 	return &AnalysisHandler{
-		db:           db,
-		settingsRepo: repository.NewUserSettingsRepository(db),
-		projectRepo:  repository.NewProjectRepository(db),
+		db:             db,
+		settingsRepo:   repository.NewUserSettingsRepository(db),
+		projectRepo:    repository.NewProjectRepository(db),
+		generationRepo: repository.NewGenerationRepository(db),
+		workspaceRepo:  repository.NewWorkspaceRepository(db),
+		systemRepo:     repository.NewSystemSettingsRepository(db),
+		mediaCache:     mediaCache,
+		authorizer:     authorizer,
 	}
 }
 
@@ -39,18 +55,37 @@ type AnalyzeRequest struct {
 	Content     string `json:"content" binding:"required"` // 正容
 	ProjectID   string `json:"project_id"`                 // 选项目
 	ContentType string `json:"content_type"`               // 内容类型: text/images/video
+	VideoURL    string `json:"video_url"`                  // 视频地址（视频类型可选，用于抽取关键帧）
+	Duration    int    `json:"duration"`                   // 视频时长（秒，可选）
 }
 
 // AnalyzeImagesRequest 图片分析求
 type AnalyzeImagesRequest struct {
 	Images    []string `json:"images" binding:"required"` // 图片 URL 列表
+	CoverURL  string   `json:"cover_url"`                 // 封面图 URL (可选，默认第 1 张图片)
 	ProjectID string   `json:"project_id"`                // 选项目
 }
 
+// AnalyzeImagesResponse 图片分析响应（逐图分析字段保持原有结构，封面/轮播分析失败时省略）
+type AnalyzeImagesResponse struct {
+	*llm.ImageAnalysisResult
+	CoverAnalysis    *llm.CoverAnalysisResult    `json:"cover_analysis,omitempty"`    // 封面分析
+	CarouselAnalysis *llm.CarouselAnalysisResult `json:"carousel_analysis,omitempty"` // 轮播顺序分析
+}
+
 // GenerateRequest 成求
 type GenerateRequest struct {
 	ProjectID string `json:"project_id" binding:"required"`
 	NewTopic  string `json:"new_topic" binding:"required"`
+	Platform  string `json:"platform"` // 目标发布平台（可选，默认根据来源链接判断）
+}
+
+// RefineRequest 精修单条生成内容请求
+type RefineRequest struct {
+	ProjectID    string `json:"project_id" binding:"required"`
+	GenerationID string `json:"generation_id" binding:"required"`
+	Instruction  string `json:"instruction" binding:"required"` // 修改意见，如"第三条改短一点，更俏皮"
+	Platform     string `json:"platform"`                       // 目标发布平台（可选，默认沿用生成时的平台）
 }
 
 // Analyze 分析容
@@ -80,8 +115,12 @@ func (h *AnalysisHandler) Analyze(c *gin.Context) {
 	log.Printf("   - 容长: %d 字", len(req.Content))
 	log.Printf("   - 项目: %s", req.ProjectID)
 
-	//  LLM 置
-	settings, err := h.settingsRepo.GetByUserID(userID)
+	if !requireWorkspaceAction(c, policy.ActionEdit) {
+		return
+	}
+
+	//  LLM 置（工作区共享 API Key 优先）
+	settings, err := resolveSettings(c.Request.Context(), h.settingsRepo, h.workspaceRepo, h.systemRepo, userID, c.GetInt64("workspaceID"))
 	if err == gorm.ErrRecordNotFound {
 		log.Printf("[API] 置 LLM")
 		response.BadRequest(c, "置心设置 LLM API Key")
@@ -120,7 +159,7 @@ func (h *AnalysisHandler) Analyze(c *gin.Context) {
 	// 根据内容类型调用不同的分析方法
 	if req.ContentType == "video" {
 		log.Printf("[API] 使用视频专属分析方法")
-		result, err = client.AnalyzeVideoContent(req.Title, req.Content)
+		result, err = analyzeVideo(c.Request.Context(), settings, client, h.mediaCache, req.Title, req.Content, req.VideoURL, req.Duration)
 	} else {
 		result, err = client.AnalyzeContent(req.Title, req.Content)
 	}
@@ -138,7 +177,7 @@ func (h *AnalysisHandler) Analyze(c *gin.Context) {
 		analysisJSON, _ := json.Marshal(result)
 		projectUUID, _ := uuid.Parse(req.ProjectID)
 		project, _ := h.projectRepo.GetByID(context.Background(), projectUUID)
-		if project != nil {
+		if project != nil && h.authorizer.AuthorizeProject(c.Request.Context(), userID, project, policy.ActionEdit) == nil {
 			project.AnalysisResult = analysisJSON
 			project.Status = model.ProjectStatusAnalyzed
 			// 如果请求中包含 content_type，更新项目类型
@@ -195,15 +234,15 @@ func (h *AnalysisHandler) Generate(c *gin.Context) {
 		return
 	}
 
-	// 证项目所
-	if project.UserID != userID {
-		log.Printf("[API] 项目")
-		response.Forbidden(c, "项目")
+	// 证工作区权限
+	if err := h.authorizer.AuthorizeProject(c.Request.Context(), userID, project, policy.ActionEdit); err != nil {
+		log.Printf("[API] 项目权限: %v", err)
+		respondAuthzError(c, err)
 		return
 	}
 
-	//  LLM 置
-	settings, err := h.settingsRepo.GetByUserID(userID)
+	//  LLM 置（项目所属工作区的共享 API Key 优先）
+	settings, err := resolveSettings(c.Request.Context(), h.settingsRepo, h.workspaceRepo, h.systemRepo, userID, project.WorkspaceID)
 	if err == gorm.ErrRecordNotFound {
 		log.Printf("[API] 置 LLM")
 		response.BadRequest(c, "置心设置 LLM API Key")
@@ -256,41 +295,70 @@ func (h *AnalysisHandler) Generate(c *gin.Context) {
 		originalTitle = analysisResult.TitleAnalysis.Original
 	}
 
-	// 获取生成条数配置（默认1条）
+	// 获取生成条数配置（默认值和上限由管理员在系统设置中配置）
+	system, err := h.systemRepo.Get(c.Request.Context())
+	if err != nil {
+		log.Printf("[API] 获取系统设置失败: %v", err)
+		system = model.DefaultSystemSettings()
+	}
 	generateCount := settings.GenerateCount
 	if generateCount <= 0 {
-		generateCount = 1
+		generateCount = system.DefaultGenerateCount
 	}
-	if generateCount > 10 {
-		generateCount = 10 // 最多10条
+	if generateCount > system.MaxGenerateCount {
+		generateCount = system.MaxGenerateCount
 	}
 	log.Printf("   - 生成条数: %d", generateCount)
 	log.Printf("   - 内容类型: %s", project.ContentType)
 
-	var generatedContents []string
+	// 原创度检查：与原文及用户其他项目过于接近的变体会带着重合片段重新生成
+	references := h.originalityReferences(context.Background(), project)
+	threshold := settings.OriginalityThreshold
+	variantOpts := llm.DefaultVariantOptions(generateCount)
+	if threshold > 0 {
+		variantOpts.Validate = func(content string) string {
+			report := similarity.Check(content, references, threshold)
+			if !report.Exceeded {
+				return ""
+			}
+			return buildOriginalityFeedback(report)
+		}
+	}
+
+	var variants []llm.VariantResult
 
-	// 根据内容类型选择不同的生成方法
+	// 根据内容类型选择不同的生成方法（均为并发逐条生成，单条失败不影响其他条）
 	if project.ContentType == "video" {
 		// 视频类型：生成视频脚本（包含时间线、分镜头、拍摄建议）
 		log.Printf("[API] 使用视频脚本生成方法")
-		generatedContents, err = client.GenerateMultipleVideoScripts(originalTitle, project.SourceContent, &analysisResult, req.NewTopic, generateCount)
-		if err != nil {
-			log.Printf("[API] 视频脚本生成失败: %v", err)
-			response.ServerError(c, "生成失败: "+err.Error())
-			return
-		}
+		variants, err = client.GenerateMultipleVideoScripts(originalTitle, project.SourceContent, &analysisResult, req.NewTopic, variantOpts)
 	} else {
 		// 图文类型：使用原有的仿写生成方法
 		log.Printf("[API] 使用图文仿写生成方法")
-		generatedContents, err = client.GenerateMultipleContent(originalTitle, project.SourceContent, &analysisResult, req.NewTopic, generateCount)
-		if err != nil {
-			log.Printf("[API] 生成失败: %v", err)
-			response.ServerError(c, "生成失败: "+err.Error())
-			return
+		variants, err = client.GenerateMultipleContent(originalTitle, project.SourceContent, &analysisResult, req.NewTopic, variantOpts)
+	}
+	if err != nil {
+		log.Printf("[API] 生成失败: %v", err)
+		response.ServerError(c, "生成失败: "+err.Error())
+		return
+	}
+
+	// 只取成功的变体用于保存和兼容旧字段，失败原因通过 variants 返回
+	successVariants := make([]llm.VariantResult, 0, len(variants))
+	generatedContents := make([]string, 0, len(variants))
+	for _, v := range variants {
+		if v.Error != "" {
+			log.Printf("[API] 第 %d 条生成失败: %s", v.Index, v.Error)
+			continue
+		}
+		if v.Duplicate {
+			log.Printf("[API] 第 %d 条与其他版本雷同: %s", v.Index, v.Warning)
 		}
+		successVariants = append(successVariants, v)
+		generatedContents = append(generatedContents, v.Content)
 	}
 
-	log.Printf("[API] 生成成功，内容条数: %d", len(generatedContents))
+	log.Printf("[API] 生成成功，内容条数: %d/%d", len(generatedContents), len(variants))
 
 	// 更新项目（保存第一条或全部内容）
 	project.NewTopic = req.NewTopic
@@ -301,12 +369,309 @@ func (h *AnalysisHandler) Generate(c *gin.Context) {
 	h.projectRepo.Update(context.Background(), project)
 	log.Printf("[API] 更新项目成功")
 
+	// 每条变体单独保存（含目标平台），供后续精修引用
+	platform := resolvePlatform(req.Platform, project.SourceURL)
+	generations := make([]*model.Generation, len(successVariants))
+	for i, v := range successVariants {
+		generations[i] = &model.Generation{
+			ProjectID:    project.ID,
+			UserID:       userID,
+			VariantIndex: v.Index,
+			NewTopic:     req.NewTopic,
+			Content:      v.Content,
+			Platform:     platform,
+		}
+	}
+	if err := h.generationRepo.CreateBatch(context.Background(), generations); err != nil {
+		log.Printf("[API] 保存生成记录失败: %v", err)
+	}
+
+	// 结构化解析（标题/正文/话题）并校验平台字数限制
+	parsedContents := make([]*llm.ParsedContent, len(generatedContents))
+	originality := make([]similarity.OriginalityReport, len(generatedContents))
+	complianceResults := make([]*compliance.Result, len(generatedContents))
+	for i, content := range generatedContents {
+		parsedContents[i] = llm.ParseGeneratedContent(content, platform)
+		if len(parsedContents[i].Violations) > 0 {
+			log.Printf("[API] 第 %d 条文案超出 %s 平台限制: %d 项", i+1, platform, len(parsedContents[i].Violations))
+		}
+		originality[i] = similarity.Check(content, references, threshold)
+		if originality[i].Exceeded {
+			log.Printf("[API] 第 %d 条文案重新生成后原创度仍不足: %.2f", i+1, originality[i].Score)
+		}
+		complianceResults[i] = compliance.Check(content, platform)
+		if !complianceResults[i].Passed {
+			log.Printf("[API] 第 %d 条文案命中 %d 个高风险违禁词", i+1, complianceResults[i].Counts[compliance.SeverityHigh])
+		}
+	}
+
 	response.Success(c, gin.H{
 		"generated_contents": generatedContents,
 		"generated_content":  generatedContents[0], // 兼容旧版本
+		"parsed_contents":    parsedContents,
+		"platform":           platform,
+		"generations":        generations,
+		"variants":           variants,
+		"originality":        originality,
+		"compliance":         complianceResults,
 	})
 }
 
+// maxOriginalityReferences 原创度比对时最多参考的其他项目数
+const maxOriginalityReferences = 20
+
+// originalityReferences 构建原创度比对的参考文本：本项目原文 + 工作区最近其他项目的原文
+func (h *AnalysisHandler) originalityReferences(ctx context.Context, project *model.Project) []similarity.Reference {
+	references := []similarity.Reference{
+		{Label: "source", Text: projectSourceText(project.SourceContent)},
+	}
+
+	others, err := h.projectRepo.ListRecentByWorkspaceID(ctx, project.WorkspaceID, project.ID, maxOriginalityReferences)
+	if err != nil {
+		log.Printf("[API] 查询其他项目失败，仅与原文比对: %v", err)
+		return references
+	}
+	for _, p := range others {
+		if text := projectSourceText(p.SourceContent); text != "" {
+			references = append(references, similarity.Reference{Label: p.ID.String(), Text: text})
+		}
+	}
+	return references
+}
+
+// projectSourceText 提取项目原文的纯文本；批量任务保存的是 {"title","content",...} JSON
+func projectSourceText(sourceContent string) string {
+	var data struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+	if strings.HasPrefix(strings.TrimSpace(sourceContent), "{") {
+		if err := json.Unmarshal([]byte(sourceContent), &data); err == nil && (data.Title != "" || data.Content != "") {
+			return strings.TrimSpace(data.Title + "\n" + data.Content)
+		}
+	}
+	return sourceContent
+}
+
+// maxOriginalityFeedbackSpans 重新生成时最多列出的重合片段数
+const maxOriginalityFeedbackSpans = 8
+
+// buildOriginalityFeedback 把重合片段整理成重新生成时的修改意见
+func buildOriginalityFeedback(report similarity.OriginalityReport) string {
+	var b strings.Builder
+	b.WriteString("【原创度不足】上一版与原文过于接近，以下句子必须彻底换一种说法，不要保留原句结构：\n")
+	for i, text := range report.SpanTexts() {
+		if i >= maxOriginalityFeedbackSpans {
+			break
+		}
+		b.WriteString("- ")
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Refine 按指令精修单条生成内容
+// @Summary 精修单条仿写文案
+// @Tags Analysis
+// @Security BearerAuth
+// @Param request body RefineRequest true "精修请求"
+// @Success 200 {object} response.Response{data=model.Generation}
+// @Router /generate/refine [post]
+func (h *AnalysisHandler) Refine(c *gin.Context) {
+	userID := c.GetInt64("userID")
+	if userID == 0 {
+		response.Unauthorized(c, "请先登录")
+		return
+	}
+
+	var req RefineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	projectUUID, err := uuid.Parse(req.ProjectID)
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID")
+		return
+	}
+	generationUUID, err := uuid.Parse(req.GenerationID)
+	if err != nil {
+		response.BadRequest(c, "无效的生成记录ID")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	project, err := h.projectRepo.GetByID(ctx, projectUUID)
+	if err != nil {
+		response.NotFound(c, "项目不存在")
+		return
+	}
+	if err := h.authorizer.AuthorizeProject(ctx, userID, project, policy.ActionEdit); err != nil {
+		respondAuthzError(c, err)
+		return
+	}
+
+	generation, err := h.generationRepo.GetByID(ctx, generationUUID)
+	if err != nil || generation.ProjectID != project.ID {
+		response.NotFound(c, "生成记录不存在")
+		return
+	}
+
+	if project.AnalysisResult == nil {
+		response.BadRequest(c, "项目尚未分析，请先进行分析")
+		return
+	}
+	var analysisResult llm.AnalysisResult
+	if err := json.Unmarshal(project.AnalysisResult, &analysisResult); err != nil {
+		response.BadRequest(c, "项目分析结果无效，请重新分析")
+		return
+	}
+
+	settings, err := resolveSettings(ctx, h.settingsRepo, h.workspaceRepo, h.systemRepo, userID, project.WorkspaceID)
+	if err != nil || settings.LLMApiKey == "" {
+		response.BadRequest(c, "请先在配置中心设置 LLM API Key")
+		return
+	}
+
+	// 从当前版本回溯到原始版本，还原完整的修改历史
+	history, err := h.loadRefineHistory(ctx, generation)
+	if err != nil {
+		log.Printf("[API] 加载精修历史失败: %v", err)
+		response.ServerError(c, "加载生成记录失败")
+		return
+	}
+
+	originalTitle := ""
+	if analysisResult.TitleAnalysis != nil {
+		originalTitle = analysisResult.TitleAnalysis.Original
+	}
+
+	client := llm.NewClient(llm.Config{
+		Provider: settings.LLMProvider,
+		ApiKey:   settings.LLMApiKey,
+		Model:    settings.LLMModel,
+		BaseURL:  settings.LLMBaseURL,
+	})
+
+	refined, err := client.RefineContent(originalTitle, project.SourceContent, &analysisResult, generation.NewTopic, history, req.Instruction)
+	if err != nil {
+		log.Printf("[API] 精修失败: %v", err)
+		response.ServerError(c, "精修失败: "+err.Error())
+		return
+	}
+
+	// 平台优先使用请求指定的，其次沿用被精修版本的平台，最后根据来源链接判断
+	platform := req.Platform
+	if platform == "" {
+		platform = generation.Platform
+	}
+	platform = resolvePlatform(platform, project.SourceURL)
+
+	child := &model.Generation{
+		ProjectID:    project.ID,
+		UserID:       userID,
+		ParentID:     &generation.ID,
+		VariantIndex: generation.VariantIndex,
+		NewTopic:     generation.NewTopic,
+		Instruction:  req.Instruction,
+		Content:      refined,
+		Platform:     platform,
+	}
+	if err := h.generationRepo.Create(ctx, child); err != nil {
+		log.Printf("[API] 保存精修结果失败: %v", err)
+		response.ServerError(c, "保存精修结果失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"generation":     child,
+		"parsed_content": llm.ParseGeneratedContent(refined, platform),
+		"originality":    similarity.Check(refined, h.originalityReferences(context.Background(), project), settings.OriginalityThreshold),
+		"compliance":     compliance.Check(refined, platform),
+	})
+}
+
+// ListGenerations 获取项目的全部生成记录（含精修版本）
+// @Summary 获取项目生成记录
+// @Tags Analysis
+// @Security BearerAuth
+// @Param id path string true "项目ID"
+// @Success 200 {object} response.Response{data=[]model.Generation}
+// @Router /projects/{id}/generations [get]
+func (h *AnalysisHandler) ListGenerations(c *gin.Context) {
+	userID := c.GetInt64("userID")
+	if userID == 0 {
+		response.Unauthorized(c, "请先登录")
+		return
+	}
+
+	projectUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID")
+		return
+	}
+
+	project, err := h.projectRepo.GetByID(c.Request.Context(), projectUUID)
+	if err != nil {
+		response.NotFound(c, "项目不存在")
+		return
+	}
+	if err := h.authorizer.AuthorizeProject(c.Request.Context(), userID, project, policy.ActionView); err != nil {
+		respondAuthzError(c, err)
+		return
+	}
+
+	generations, err := h.generationRepo.ListByProjectID(c.Request.Context(), project.ID)
+	if err != nil {
+		response.ServerError(c, "查询生成记录失败")
+		return
+	}
+
+	response.Success(c, generations)
+}
+
+// maxRefineDepth 精修历史最大回溯深度，防止异常数据导致死循环
+const maxRefineDepth = 20
+
+// loadRefineHistory 从指定版本回溯到原始版本，返回按时间正序排列的对话轮次
+func (h *AnalysisHandler) loadRefineHistory(ctx context.Context, generation *model.Generation) ([]llm.RefineTurn, error) {
+	chain := []*model.Generation{generation}
+	current := generation
+	for current.ParentID != nil && len(chain) < maxRefineDepth {
+		parent, err := h.generationRepo.GetByID(ctx, *current.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, parent)
+		current = parent
+	}
+
+	history := make([]llm.RefineTurn, 0, len(chain))
+	for i := len(chain) - 1; i >= 0; i-- {
+		history = append(history, llm.RefineTurn{
+			Instruction: chain[i].Instruction,
+			Content:     chain[i].Content,
+		})
+	}
+	return history, nil
+}
+
+// resolvePlatform 确定校验所用平台：优先使用请求指定的平台，否则根据来源链接判断
+func resolvePlatform(requested, sourceURL string) string {
+	if _, ok := llm.PlatformLimits[requested]; ok {
+		return requested
+	}
+	if detected := string(crawler.DetectPlatform(sourceURL)); detected != "" {
+		if _, ok := llm.PlatformLimits[detected]; ok {
+			return detected
+		}
+	}
+	return llm.PlatformXiaohongshu
+}
+
 // AnalyzeImages 分析图片容
 // @Summary 分析图片容模态
 // @Tags Analysis
@@ -341,8 +706,12 @@ func (h *AnalysisHandler) AnalyzeImages(c *gin.Context) {
 	log.Printf("   - 图片数: %d", len(req.Images))
 	log.Printf("   - 项目 ID: %s", req.ProjectID)
 
-	// LLM 置使图片分析置
-	settings, err := h.settingsRepo.GetByUserID(userID)
+	if !requireWorkspaceAction(c, policy.ActionEdit) {
+		return
+	}
+
+	// LLM 置使图片分析置（工作区共享 API Key 优先）
+	settings, err := resolveSettings(c.Request.Context(), h.settingsRepo, h.workspaceRepo, h.systemRepo, userID, c.GetInt64("workspaceID"))
 	if err != nil || settings.ImageLLMApiKey == "" {
 		log.Printf("[API] 置图片分析 LLM进图片分析")
 		response.BadRequest(c, "置心设置图片分析模 API Key")
@@ -377,6 +746,8 @@ func (h *AnalysisHandler) AnalyzeImages(c *gin.Context) {
 		ApiKey:   settings.ImageLLMApiKey,
 		Model:    settings.ImageLLMModel,
 		BaseURL:  settings.ImageLLMBaseURL,
+		// 图片经媒体缓存下载（带防盗链 Referer）并压缩后以 Base64 发送
+		ImageLoader: h.mediaCache.Loader(c.Request.Context()),
 	})
 
 	// 调图片分析
@@ -390,16 +761,25 @@ func (h *AnalysisHandler) AnalyzeImages(c *gin.Context) {
 
 	log.Printf("[API] 图片分析成分析了 %d 图片", len(result.Images))
 
+	// 封面与轮播顺序分析
+	cover, carousel := analyzeCoverAndCarousel(client, req.CoverURL, req.Images)
+
 	// 项目将图片分析合并到项目分析
 	if req.ProjectID != "" {
 		projectUUID, _ := uuid.Parse(req.ProjectID)
 		project, _ := h.projectRepo.GetByID(context.Background(), projectUUID)
-		if project != nil && project.AnalysisResult != nil {
+		if project != nil && project.AnalysisResult != nil && h.authorizer.AuthorizeProject(c.Request.Context(), userID, project, policy.ActionEdit) == nil {
 			// 解析现分析
 			var existingResult map[string]interface{}
 			if err := json.Unmarshal(project.AnalysisResult, &existingResult); err == nil {
 				// 图片分析
 				existingResult["image_analysis"] = result
+				if cover != nil {
+					existingResult["cover_analysis"] = cover
+				}
+				if carousel != nil {
+					existingResult["carousel_analysis"] = carousel
+				}
 				updatedJSON, _ := json.Marshal(existingResult)
 				project.AnalysisResult = updatedJSON
 				h.projectRepo.Update(context.Background(), project)
@@ -408,5 +788,49 @@ func (h *AnalysisHandler) AnalyzeImages(c *gin.Context) {
 		}
 	}
 
-	response.Success(c, result)
+	response.Success(c, AnalyzeImagesResponse{
+		ImageAnalysisResult: result,
+		CoverAnalysis:       cover,
+		CarouselAnalysis:    carousel,
+	})
+}
+
+// analyzeCoverAndCarousel 并行进行封面分析和轮播顺序分析（单张图片时跳过轮播分析），
+// 失败只记录日志，不影响逐图分析结果
+func analyzeCoverAndCarousel(client *llm.Client, coverURL string, images []string) (*llm.CoverAnalysisResult, *llm.CarouselAnalysisResult) {
+	if coverURL == "" && len(images) > 0 {
+		coverURL = images[0]
+	}
+
+	var (
+		wg       sync.WaitGroup
+		cover    *llm.CoverAnalysisResult
+		carousel *llm.CarouselAnalysisResult
+	)
+	if coverURL != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := client.AnalyzeCover(coverURL)
+			if err != nil {
+				logger.LLMError("封面分析失败: %v", err)
+				return
+			}
+			cover = result
+		}()
+	}
+	if len(images) >= 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := client.AnalyzeCarousel(images)
+			if err != nil {
+				logger.LLMError("轮播顺序分析失败: %v", err)
+				return
+			}
+			carousel = result
+		}()
+	}
+	wg.Wait()
+	return cover, carousel
 }
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+	"copycat/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// saveAssetFile 写入对象存储后创建资源记录；记录创建失败时清理已上传的文件
+func saveAssetFile(ctx context.Context, store storage.Storage, assetRepo repository.AssetRepository, asset *model.Asset, data []byte) error {
+	asset.StorageKey = fmt.Sprintf("%s/%d/%s.%s", asset.Kind, asset.UserID, asset.ID, asset.Format)
+	if err := store.Put(ctx, asset.StorageKey, bytes.NewReader(data), int64(len(data)), asset.ContentType); err != nil {
+		return err
+	}
+	if err := assetRepo.Create(ctx, asset); err != nil {
+		if delErr := store.Delete(ctx, asset.StorageKey); delErr != nil {
+			log.Printf("[API] 清理资源文件失败: %v", delErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// serveAssetFile 输出资源文件（支持 Range 请求），失败时已写入响应
+func serveAssetFile(c *gin.Context, store storage.Storage, asset *model.Asset) {
+	obj, err := store.Open(c.Request.Context(), asset.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			response.NotFound(c, "文件不存在")
+			return
+		}
+		log.Printf("[API] 打开资源文件失败: %v", err)
+		response.ServerError(c, "读取文件失败")
+		return
+	}
+	defer obj.Close()
+
+	filename := asset.ID.String() + "." + asset.Format
+	c.Header("Content-Type", asset.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	http.ServeContent(c.Writer, c.Request, filename, obj.ModTime(), obj)
+}
+
+// deleteAssetFile 删除资源文件和记录
+func deleteAssetFile(ctx context.Context, store storage.Storage, assetRepo repository.AssetRepository, asset *model.Asset) error {
+	if err := store.Delete(ctx, asset.StorageKey); err != nil {
+		return fmt.Errorf("删除文件失败: %w", err)
+	}
+	if err := assetRepo.Delete(ctx, asset.ID); err != nil {
+		return fmt.Errorf("删除记录失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"copycat/internal/core/audit"
+	"copycat/internal/core/policy"
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler 审计日志处理器
+type AuditHandler struct {
+	audit      *audit.Service
+	userRepo   repository.UserRepository
+	authorizer *policy.Authorizer
+}
+
+// NewAuditHandler 创建审计日志处理器
+func NewAuditHandler(auditService *audit.Service, userRepo repository.UserRepository, authorizer *policy.Authorizer) *AuditHandler {
+	return &AuditHandler{audit: auditService, userRepo: userRepo, authorizer: authorizer}
+}
+
+// ListAuditRequest 审计日志查询参数
+type ListAuditRequest struct {
+	ActorID     int64  `form:"actor_id"`
+	WorkspaceID int64  `form:"workspace_id"`
+	Action      string `form:"action"`
+	TargetType  string `form:"target_type"`
+	TargetID    string `form:"target_id"`
+	From        string `form:"from"` // RFC3339 或 2006-01-02
+	To          string `form:"to"`
+	Page        int    `form:"page" binding:"omitempty,min=1"`
+	PageSize    int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// List 查询审计日志：管理员可查询全部记录，工作区所有者只能查询自己工作区内的记录
+// @Summary 查询审计日志
+// @Tags Audit
+// @Security BearerAuth
+// @Param actor_id query int false "操作人用户ID"
+// @Param workspace_id query int false "工作区ID(非管理员默认为当前工作区)"
+// @Param action query string false "操作(如project.delete)"
+// @Param target_type query string false "操作对象类型"
+// @Param target_id query string false "操作对象ID"
+// @Param from query string false "开始时间(RFC3339或日期)"
+// @Param to query string false "结束时间(RFC3339或日期,不含)"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Success 200 {object} response.Response{data=response.PageData}
+// @Router /audit [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	var req ListAuditRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	filter := repository.AuditLogFilter{
+		ActorID:     req.ActorID,
+		WorkspaceID: req.WorkspaceID,
+		Action:      req.Action,
+		TargetType:  req.TargetType,
+		TargetID:    req.TargetID,
+	}
+	var err error
+	if filter.From, err = parseAuditTime(req.From); err != nil {
+		response.BadRequest(c, "无效的开始时间")
+		return
+	}
+	if filter.To, err = parseAuditTime(req.To); err != nil {
+		response.BadRequest(c, "无效的结束时间")
+		return
+	}
+
+	userID := c.GetInt64("userID")
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		response.Unauthorized(c, "user not found")
+		return
+	}
+	// 非管理员只能查看自己拥有的工作区
+	if !user.IsAdmin() {
+		if filter.WorkspaceID == 0 {
+			filter.WorkspaceID = c.GetInt64("workspaceID")
+		}
+		if err := h.authorizer.Authorize(c.Request.Context(), userID, filter.WorkspaceID, policy.ActionViewAudit); err != nil {
+			respondAuthzError(c, err)
+			return
+		}
+	}
+
+	logs, total, err := h.audit.List(c.Request.Context(), filter, req.Page, req.PageSize)
+	if err != nil {
+		log.Printf("[API] 查询审计日志失败: %v", err)
+		response.ServerError(c, "查询失败")
+		return
+	}
+	response.SuccessWithPage(c, logs, total, req.Page, req.PageSize)
+}
+
+// auditEntry 当前请求的审计记录，路由未挂审计中间件时返回 nil（Entry 的方法对 nil 安全）
+func auditEntry(c *gin.Context) *audit.Entry {
+	if v, ok := c.Get(audit.ContextKey); ok {
+		if entry, ok := v.(*audit.Entry); ok {
+			return entry
+		}
+	}
+	return nil
+}
+
+// auditAsset 在审计记录中写入被删除素材的信息，关联项目的素材归属项目所在的工作区
+func auditAsset(c *gin.Context, projectRepo repository.ProjectRepository, asset *model.Asset) {
+	entry := auditEntry(c)
+	entry.AddDetail("kind", asset.Kind)
+	if asset.ProjectID == nil {
+		return
+	}
+	entry.AddDetail("project_id", asset.ProjectID)
+	if project, err := projectRepo.GetByID(c.Request.Context(), *asset.ProjectID); err == nil {
+		entry.SetWorkspace(project.WorkspaceID)
+	}
+}
+
+// parseAuditTime 解析 RFC3339 时间或日期，为空时返回 nil
+func parseAuditTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, errors.New("invalid time")
+}
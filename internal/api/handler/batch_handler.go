@@ -9,6 +9,8 @@ import (
 
 	"copycat/internal/core/agent"
 	"copycat/internal/core/llm"
+	"copycat/internal/core/media"
+	"copycat/internal/core/policy"
 	"copycat/internal/model"
 	"copycat/internal/repository"
 	"copycat/pkg/logger"
@@ -25,17 +27,25 @@ type BatchHandler struct {
 	batchTaskRepo  *repository.BatchTaskRepository
 	projectRepo    repository.ProjectRepository
 	settingsRepo   *repository.UserSettingsRepository
+	workspaceRepo  repository.WorkspaceRepository
+	systemRepo     repository.SystemSettingsRepository
 	contentService *agent.ContentService
+	mediaCache     *media.Cache
+	authorizer     *policy.Authorizer
 }
 
 // NewBatchHandler 创建批量任务处理器
-func NewBatchHandler(db *gorm.DB, contentService *agent.ContentService) *BatchHandler {
+func NewBatchHandler(db *gorm.DB, contentService *agent.ContentService, mediaCache *media.Cache, authorizer *policy.Authorizer) *BatchHandler {
 	return &BatchHandler{
 		db:             db,
 		batchTaskRepo:  repository.NewBatchTaskRepository(db),
 		projectRepo:    repository.NewProjectRepository(db),
 		settingsRepo:   repository.NewUserSettingsRepository(db),
+		workspaceRepo:  repository.NewWorkspaceRepository(db),
+		systemRepo:     repository.NewSystemSettingsRepository(db),
 		contentService: contentService,
+		mediaCache:     mediaCache,
+		authorizer:     authorizer,
 	}
 }
 
@@ -83,9 +93,13 @@ func (h *BatchHandler) CreateBatchAnalyze(c *gin.Context) {
 		response.BadRequest(c, "参数错误: "+err.Error())
 		return
 	}
+	if !requireWorkspaceAction(c, policy.ActionEdit) {
+		return
+	}
+	workspaceID := c.GetInt64("workspaceID")
 
-	// 检查用户 LLM 配置
-	settings, err := h.settingsRepo.GetByUserID(userID)
+	// 检查用户 LLM 配置（工作区共享 API Key 优先）
+	settings, err := resolveSettings(c.Request.Context(), h.settingsRepo, h.workspaceRepo, h.systemRepo, userID, workspaceID)
 	if err != nil || settings.LLMApiKey == "" {
 		response.BadRequest(c, "请先在配置中心设置 LLM API Key")
 		return
@@ -110,9 +124,10 @@ func (h *BatchHandler) CreateBatchAnalyze(c *gin.Context) {
 
 	// 创建批量任务
 	batchTask := &model.BatchTask{
-		UserID:     userID,
-		TotalCount: len(uniqueURLs),
-		Status:     model.BatchTaskStatusProcessing,
+		UserID:      userID,
+		WorkspaceID: workspaceID,
+		TotalCount:  len(uniqueURLs),
+		Status:      model.BatchTaskStatusProcessing,
 	}
 
 	if err := h.batchTaskRepo.Create(batchTask); err != nil {
@@ -124,7 +139,7 @@ func (h *BatchHandler) CreateBatchAnalyze(c *gin.Context) {
 	log.Printf("[Batch] 批量任务已创建 - BatchID: %s", batchTask.ID.String())
 
 	// 异步处理每个链接（传入 LLM 配置）
-	go h.processBatchTask(batchTask.ID, userID, uniqueURLs, settings)
+	go h.processBatchTask(batchTask.ID, userID, workspaceID, uniqueURLs, settings)
 
 	response.Success(c, BatchAnalyzeResponse{
 		BatchID:    batchTask.ID.String(),
@@ -134,11 +149,18 @@ func (h *BatchHandler) CreateBatchAnalyze(c *gin.Context) {
 }
 
 // processBatchTask 异步处理批量任务
-func (h *BatchHandler) processBatchTask(batchID uuid.UUID, userID int64, urls []string, settings *model.UserSettings) {
+func (h *BatchHandler) processBatchTask(batchID uuid.UUID, userID, workspaceID int64, urls []string, settings *model.UserSettings) {
 	log.Printf("[Batch] 开始处理批量任务 - BatchID: %s, 链接数: %d", batchID.String(), len(urls))
 
+	// 并发数由管理员在系统设置中配置（LLM 调用比较耗时）
+	system, err := h.systemRepo.Get(context.Background())
+	if err != nil {
+		log.Printf("[Batch] 获取系统设置失败，使用默认并发数: %v", err)
+		system = model.DefaultSystemSettings()
+	}
+
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, 2) // 限制并发数为2（LLM 调用比较耗时）
+	semaphore := make(chan struct{}, system.BatchConcurrency)
 
 	for _, url := range urls {
 		wg.Add(1)
@@ -147,7 +169,7 @@ func (h *BatchHandler) processBatchTask(batchID uuid.UUID, userID int64, urls []
 			semaphore <- struct{}{}        // 获取信号量
 			defer func() { <-semaphore }() // 释放信号量
 
-			h.processSingleURL(batchID, userID, targetURL, settings)
+			h.processSingleURL(batchID, userID, workspaceID, targetURL, settings)
 		}(url)
 	}
 
@@ -175,7 +197,7 @@ func (h *BatchHandler) processBatchTask(batchID uuid.UUID, userID int64, urls []
 }
 
 // processSingleURL 处理单个URL（包含爬取、文本分析和图片分析）
-func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url string, settings *model.UserSettings) {
+func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID, workspaceID int64, url string, settings *model.UserSettings) {
 	log.Printf("[Batch] 处理链接: %s", url)
 
 	ctx := context.Background()
@@ -183,6 +205,7 @@ func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url str
 	// 1. 创建项目记录
 	project := &model.Project{
 		UserID:        userID,
+		WorkspaceID:   workspaceID,
 		BatchTaskID:   &batchID,
 		SourceURL:     url,
 		SourceContent: "正在爬取内容...",
@@ -199,8 +222,15 @@ func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url str
 	result, err := h.contentService.CrawlOnly(ctx, url)
 	if err != nil || !result.Success {
 		log.Printf("[Batch] 爬取失败: %s - %v", url, err)
-		project.Status = "failed"
+		project.Status = model.ProjectStatusFailed
 		project.SourceContent = "爬取失败"
+		detail := ""
+		if err != nil {
+			detail = err.Error()
+		} else if result != nil {
+			detail = result.Error
+		}
+		project.BatchError = batchError("爬取失败", detail)
 		h.projectRepo.Update(ctx, project)
 		h.batchTaskRepo.IncrementFailedCount(batchID)
 		return
@@ -209,23 +239,35 @@ func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url str
 	// 3. 保存爬取结果（包括图片URL，以JSON格式保存供前端读取）
 	var title, content string
 	var images []string
+	var coverURL string
+	var videoURL string
+	var videoDuration int
 	var contentType string = "images" // 默认为图文类型
 	if result.Content != nil {
 		title = result.Content.Title
 		content = result.Content.Content
 		images = result.Content.Images
+		coverURL = result.Content.CoverURL
 
 		// 判断内容类型
 		if result.Content.Type == "video" {
 			contentType = "video"
 		}
+		if result.Content.Video != nil {
+			videoURL = result.Content.Video.URL
+			videoDuration = result.Content.Video.Duration
+		}
 
 		// 将内容和图片以 JSON 格式保存，供前端读取
 		sourceData := map[string]interface{}{
-			"title":   title,
-			"content": content,
-			"images":  images,
-			"type":    result.Content.Type, // 保存原始类型
+			"title":         title,
+			"content":       content,
+			"images":        images,
+			"cover_url":     coverURL,
+			"cached_images": result.Content.CachedImages,
+			"cached_cover":  result.Content.CachedCover,
+			"video":         result.Content.Video,
+			"type":          result.Content.Type, // 保存原始类型
 		}
 		sourceJSON, _ := json.Marshal(sourceData)
 		project.SourceContent = string(sourceJSON)
@@ -243,8 +285,8 @@ func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url str
 
 	var analysisResult *llm.AnalysisResult
 	if contentType == "video" {
-		// 视频类型使用视频分析
-		analysisResult, err = textClient.AnalyzeVideoContent(title, content)
+		// 视频类型使用视频分析（配置了视频 LLM 时结合关键帧）
+		analysisResult, err = analyzeVideo(ctx, settings, textClient, h.mediaCache, title, content, videoURL, videoDuration)
 	} else {
 		// 图文类型使用普通分析
 		analysisResult, err = textClient.AnalyzeContent(title, content)
@@ -252,6 +294,7 @@ func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url str
 	if err != nil {
 		logger.LLMError("[Batch] 分析失败: %s - %v", url, err)
 		project.Status = model.ProjectStatusDraft
+		project.BatchError = batchError("分析失败", err.Error())
 		h.projectRepo.Update(ctx, project)
 		h.batchTaskRepo.IncrementFailedCount(batchID)
 		return
@@ -260,6 +303,8 @@ func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url str
 
 	// 5. 图片分析（如果有图片且配置了图片 LLM）
 	var imageAnalysisResult *llm.ImageAnalysisResult
+	var coverAnalysis *llm.CoverAnalysisResult
+	var carouselAnalysis *llm.CarouselAnalysisResult
 	if len(images) > 0 && settings.ImageLLMApiKey != "" {
 		logger.LLMInfo("[Batch] 开始图片分析: %s (图片数: %d)", url, len(images))
 
@@ -268,6 +313,8 @@ func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url str
 			ApiKey:   settings.ImageLLMApiKey,
 			Model:    settings.ImageLLMModel,
 			BaseURL:  settings.ImageLLMBaseURL,
+			// 爬取时已缓存的图片直接从缓存读取，不再重新下载
+			ImageLoader: h.mediaCache.Loader(ctx),
 		})
 
 		imageAnalysisResult, err = imageClient.AnalyzeImages(images)
@@ -277,6 +324,11 @@ func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url str
 		} else {
 			logger.LLMInfo("[Batch] 图片分析成功: %s (分析图片数: %d)", url, len(imageAnalysisResult.Images))
 		}
+
+		// 图文笔记额外分析封面和轮播顺序（视频的封面为首帧，不做封面分析）
+		if contentType != "video" {
+			coverAnalysis, carouselAnalysis = analyzeCoverAndCarousel(imageClient, coverURL, images)
+		}
 	} else if len(images) > 0 {
 		logger.LLMInfo("[Batch] 跳过图片分析（未配置图片 LLM）: %s", url)
 	}
@@ -300,11 +352,20 @@ func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url str
 		finalResult["ppp_model"] = analysisResult.PPPModel
 		finalResult["viral_mechanics"] = analysisResult.ViralMechanics
 		finalResult["tags_&_seo"] = analysisResult.TagsAndSEO
+		if len(analysisResult.VideoFrames) > 0 {
+			finalResult["video_frames"] = analysisResult.VideoFrames
+		}
 	}
 
 	if imageAnalysisResult != nil {
 		finalResult["image_analysis"] = imageAnalysisResult
 	}
+	if coverAnalysis != nil {
+		finalResult["cover_analysis"] = coverAnalysis
+	}
+	if carouselAnalysis != nil {
+		finalResult["carousel_analysis"] = carouselAnalysis
+	}
 
 	analysisJSON, _ := json.Marshal(finalResult)
 	project.AnalysisResult = analysisJSON
@@ -320,6 +381,18 @@ func (h *BatchHandler) processSingleURL(batchID uuid.UUID, userID int64, url str
 	log.Printf("[Batch] 链接处理完成: %s", url)
 }
 
+// batchError 记录到项目上的失败原因（管理后台排查失败条目用），超长时截断
+func batchError(stage, detail string) string {
+	msg := stage
+	if detail != "" {
+		msg += ": " + detail
+	}
+	if runes := []rune(msg); len(runes) > 500 {
+		msg = string(runes[:500])
+	}
+	return msg
+}
+
 // GetBatchStatus 获取批量任务状态
 func (h *BatchHandler) GetBatchStatus(c *gin.Context) {
 	userID := c.GetInt64("userID")
@@ -341,9 +414,9 @@ func (h *BatchHandler) GetBatchStatus(c *gin.Context) {
 		return
 	}
 
-	// 检查权限
-	if task.UserID != userID {
-		response.Forbidden(c, "无权查看此任务")
+	// 检查工作区权限
+	if err := h.authorizer.AuthorizeBatchTask(c.Request.Context(), userID, task, policy.ActionView); err != nil {
+		respondAuthzError(c, err)
 		return
 	}
 
@@ -351,9 +424,10 @@ func (h *BatchHandler) GetBatchStatus(c *gin.Context) {
 	projects := make([]BatchProjectResponse, 0)
 	for _, p := range task.Projects {
 		projects = append(projects, BatchProjectResponse{
-			ID:        p.ID.String(),
-			SourceURL: p.SourceURL,
-			Status:    p.Status,
+			ID:           p.ID.String(),
+			SourceURL:    p.SourceURL,
+			Status:       p.Status,
+			ErrorMessage: p.BatchError,
 		})
 	}
 
@@ -391,7 +465,7 @@ func (h *BatchHandler) ListBatchTasks(c *gin.Context) {
 
 	offset := (page - 1) * pageSize
 
-	tasks, total, err := h.batchTaskRepo.FindByUserID(userID, pageSize, offset)
+	tasks, total, err := h.batchTaskRepo.FindByWorkspaceID(c.GetInt64("workspaceID"), pageSize, offset)
 	if err != nil {
 		response.ServerError(c, "查询失败")
 		return
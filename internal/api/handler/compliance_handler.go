@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"unicode/utf8"
+
+	"copycat/internal/core/compliance"
+	"copycat/internal/core/llm"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxComplianceTextChars 单次检查的最大字数
+const maxComplianceTextChars = 20000
+
+// ComplianceHandler 合规检查处理器
+type ComplianceHandler struct{}
+
+// NewComplianceHandler 创建合规检查处理器
+func NewComplianceHandler() *ComplianceHandler {
+	return &ComplianceHandler{}
+}
+
+// ComplianceCheckRequest 合规检查请求
+type ComplianceCheckRequest struct {
+	Text     string `json:"text" binding:"required"` // 待检查文本
+	Platform string `json:"platform"`                // 目标平台 (xiaohongshu/douyin/wechat)，为空时应用全部规则
+}
+
+// Check 检查任意文本中的违禁词和站外联系方式
+// @Summary 合规检查
+// @Tags Compliance
+// @Security BearerAuth
+// @Param request body ComplianceCheckRequest true "合规检查请求"
+// @Success 200 {object} response.Response{data=compliance.Result}
+// @Router /compliance/check [post]
+func (h *ComplianceHandler) Check(c *gin.Context) {
+	var req ComplianceCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	if utf8.RuneCountInString(req.Text) > maxComplianceTextChars {
+		response.BadRequest(c, "文本过长，单次最多检查 20000 字")
+		return
+	}
+	if req.Platform != "" {
+		if _, ok := llm.PlatformLimits[req.Platform]; !ok {
+			response.BadRequest(c, "不支持的平台: "+req.Platform)
+			return
+		}
+	}
+
+	response.Success(c, compliance.Check(req.Text, req.Platform))
+}
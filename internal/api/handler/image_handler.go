@@ -0,0 +1,499 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"copycat/internal/core/imagegen"
+	"copycat/internal/core/llm"
+	"copycat/internal/core/policy"
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+	"copycat/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// 图片用途
+const (
+	ImageRoleCover  = "cover"  // 封面（对应原图第 1 张）
+	ImageRoleInside = "inside" // 内页
+)
+
+// imageGenConcurrency 同时进行的生成请求数
+const imageGenConcurrency = 2
+
+// ImageHandler 图像生成处理器
+type ImageHandler struct {
+	settingsRepo  *repository.UserSettingsRepository
+	projectRepo   repository.ProjectRepository
+	assetRepo     repository.AssetRepository
+	workspaceRepo repository.WorkspaceRepository
+	systemRepo    repository.SystemSettingsRepository
+	store         storage.Storage
+	authorizer    *policy.Authorizer
+}
+
+// NewImageHandler 创建图像生成处理器
+func NewImageHandler(db *gorm.DB, store storage.Storage, authorizer *policy.Authorizer) *ImageHandler {
+	return &ImageHandler{
+		settingsRepo:  repository.NewUserSettingsRepository(db),
+		projectRepo:   repository.NewProjectRepository(db),
+		assetRepo:     repository.NewAssetRepository(db),
+		workspaceRepo: repository.NewWorkspaceRepository(db),
+		systemRepo:    repository.NewSystemSettingsRepository(db),
+		store:         store,
+		authorizer:    authorizer,
+	}
+}
+
+// GenerateImagesRequest 图像生成请求
+type GenerateImagesRequest struct {
+	NewTopic string `json:"new_topic"` // 新主题 (可选，默认使用项目的新主题)
+	Indexes  []int  `json:"indexes"`   // 要复刻的原图序号 (可选，默认全部；序号 1 为封面)
+	Model    string `json:"model"`     // 模型 (可选，默认使用服务商的默认模型)
+	Aspect   string `json:"aspect"`    // 画面比例 (可选，默认 3:4)
+	Count    int    `json:"count"`     // 每张原图生成的张数 (可选，默认 1)
+}
+
+// ImageItem 生成的图片
+type ImageItem struct {
+	Asset       *model.Asset `json:"asset"`        // 图片资源记录
+	URL         string       `json:"url"`          // 图片地址
+	Role        string       `json:"role"`         // cover / inside
+	SourceIndex int          `json:"source_index"` // 对应的原图序号
+}
+
+// ImageFailure 生成失败的原图
+type ImageFailure struct {
+	SourceIndex int    `json:"source_index"` // 原图序号
+	Error       string `json:"error"`        // 失败原因
+}
+
+// GenerateImagesResponse 图像生成响应
+type GenerateImagesResponse struct {
+	Images []ImageItem    `json:"images"`           // 生成成功的图片
+	Failed []ImageFailure `json:"failed,omitempty"` // 生成失败的原图
+}
+
+// ImageMeta 图片资源的附加信息（保存在 Asset.Meta 中）
+type ImageMeta struct {
+	Provider       string `json:"provider"`
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	NegativePrompt string `json:"negative_prompt,omitempty"`
+	RevisedPrompt  string `json:"revised_prompt,omitempty"`
+	Topic          string `json:"topic"`
+	Role           string `json:"role"`
+	SourceIndex    int    `json:"source_index"`
+	Aspect         string `json:"aspect"`
+	Width          int    `json:"width"`
+	Height         int    `json:"height"`
+}
+
+// ImageProviderItem 图像生成服务商信息
+type ImageProviderItem struct {
+	ID           string                `json:"id"`           // 服务商标识
+	Name         string                `json:"name"`         // 展示名称
+	Capabilities imagegen.Capabilities `json:"capabilities"` // 能力说明
+	Models       []imagegen.Model      `json:"models"`       // 可用模型
+	Current      bool                  `json:"current"`      // 是否为当前用户选择的服务商
+}
+
+// GenerateImages 按项目的图片分析结果和新主题生成封面/内页图片
+// @Summary 生成封面/内页图片
+// @Tags Image
+// @Security BearerAuth
+// @Param id path string true "项目ID"
+// @Param request body GenerateImagesRequest true "图像生成请求"
+// @Success 200 {object} response.Response{data=GenerateImagesResponse}
+// @Router /projects/{id}/images [post]
+func (h *ImageHandler) GenerateImages(c *gin.Context) {
+	project, ok := h.getOwnedProject(c, policy.ActionEdit)
+	if !ok {
+		return
+	}
+
+	var req GenerateImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+
+	// 读取图片分析结果
+	var analysis struct {
+		ImageAnalysis *llm.ImageAnalysisResult `json:"image_analysis"`
+	}
+	if len(project.AnalysisResult) > 0 {
+		if err := json.Unmarshal(project.AnalysisResult, &analysis); err != nil {
+			log.Printf("[API] 解析项目分析结果失败: %v", err)
+		}
+	}
+	if analysis.ImageAnalysis == nil || len(analysis.ImageAnalysis.Images) == 0 {
+		response.BadRequest(c, "该项目还没有图片分析结果，请先分析图片")
+		return
+	}
+
+	// 选择要复刻的原图
+	items, err := selectImageItems(analysis.ImageAnalysis.Images, req.Indexes)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	topic := req.NewTopic
+	if topic == "" {
+		topic = project.NewTopic
+	}
+	aspect := req.Aspect
+	if aspect == "" {
+		aspect = imagegen.DefaultAspect
+	}
+	if !imagegen.IsValidAspect(aspect) {
+		response.BadRequest(c, "不支持的画面比例: "+aspect)
+		return
+	}
+
+	provider, ok := h.userProvider(c, c.GetInt64("userID"), project.WorkspaceID)
+	if !ok {
+		return
+	}
+	caps := provider.Capabilities()
+	count := req.Count
+	if count == 0 {
+		count = 1
+	}
+	if count < 1 || count > caps.MaxCount {
+		response.BadRequest(c, fmt.Sprintf("每张原图的生成张数需在 1-%d 之间", caps.MaxCount))
+		return
+	}
+	genModel, err := imagegen.ResolveModel(provider, req.Model)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	// 逐张原图生成（限制并发），单张失败不影响其他图片
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result GenerateImagesResponse
+		sem    = make(chan struct{}, imageGenConcurrency)
+	)
+	result.Images = []ImageItem{}
+	for _, item := range items {
+		wg.Add(1)
+		go func(item llm.ImageAnalysisItem) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			generated, err := h.generateForItem(c, provider, project, item, topic, genModel, aspect, count, analysis.ImageAnalysis.OverallStyle)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("[API] 生成图片失败: project=%s, index=%d, err=%v", project.ID, item.Index, err)
+				result.Failed = append(result.Failed, ImageFailure{SourceIndex: item.Index, Error: err.Error()})
+				return
+			}
+			result.Images = append(result.Images, generated...)
+		}(item)
+	}
+	wg.Wait()
+
+	if len(result.Images) == 0 {
+		response.ServerError(c, "图片生成失败: "+result.Failed[0].Error)
+		return
+	}
+
+	sort.SliceStable(result.Images, func(i, j int) bool { return result.Images[i].SourceIndex < result.Images[j].SourceIndex })
+	sort.Slice(result.Failed, func(i, j int) bool { return result.Failed[i].SourceIndex < result.Failed[j].SourceIndex })
+	response.Success(c, result)
+}
+
+// generateForItem 按单张原图的提示词生成图片并保存为项目资源
+func (h *ImageHandler) generateForItem(c *gin.Context, provider imagegen.Provider, project *model.Project, item llm.ImageAnalysisItem, topic, genModel, aspect string, count int, overallStyle string) ([]ImageItem, error) {
+	role := ImageRoleInside
+	if item.Index == 1 {
+		role = ImageRoleCover
+	}
+	prompt := imagegen.BuildPrompt(topic, item.ImagePrompt, overallStyle)
+	if prompt == "" {
+		return nil, fmt.Errorf("第 %d 张原图没有可用的提示词", item.Index)
+	}
+
+	images, err := provider.Generate(imagegen.Request{
+		Prompt:         prompt,
+		NegativePrompt: item.NegativePrompt,
+		Model:          genModel,
+		Aspect:         aspect,
+		Count:          count,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ImageItem, 0, len(images))
+	for _, img := range images {
+		meta, _ := json.Marshal(ImageMeta{
+			Provider:       provider.Name(),
+			Model:          genModel,
+			Prompt:         prompt,
+			NegativePrompt: item.NegativePrompt,
+			RevisedPrompt:  img.RevisedPrompt,
+			Topic:          topic,
+			Role:           role,
+			SourceIndex:    item.Index,
+			Aspect:         aspect,
+			Width:          img.Width,
+			Height:         img.Height,
+		})
+		asset := &model.Asset{
+			ID:          uuid.New(),
+			UserID:      c.GetInt64("userID"),
+			ProjectID:   &project.ID,
+			Kind:        model.AssetKindImage,
+			ContentType: imagegen.ContentType(img.Format),
+			Format:      img.Format,
+			Size:        int64(len(img.Data)),
+			Meta:        datatypes.JSON(meta),
+		}
+		if err := saveAssetFile(c.Request.Context(), h.store, h.assetRepo, asset, img.Data); err != nil {
+			return nil, fmt.Errorf("保存图片失败: %w", err)
+		}
+		items = append(items, ImageItem{Asset: asset, URL: imageURL(asset.ID), Role: role, SourceIndex: item.Index})
+	}
+	return items, nil
+}
+
+// selectImageItems 按序号选择原图分析结果，序号为空时返回全部
+func selectImageItems(all []llm.ImageAnalysisItem, indexes []int) ([]llm.ImageAnalysisItem, error) {
+	if len(indexes) == 0 {
+		return all, nil
+	}
+	byIndex := make(map[int]llm.ImageAnalysisItem, len(all))
+	for _, item := range all {
+		byIndex[item.Index] = item
+	}
+	seen := make(map[int]bool, len(indexes))
+	items := make([]llm.ImageAnalysisItem, 0, len(indexes))
+	for _, idx := range indexes {
+		item, ok := byIndex[idx]
+		if !ok {
+			return nil, fmt.Errorf("图片分析结果中没有第 %d 张图片", idx)
+		}
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// imageURL 图片访问地址
+func imageURL(id uuid.UUID) string {
+	return "/api/v1/images/" + id.String()
+}
+
+// ListProjectImages 获取项目下的全部生成图片
+// @Summary 获取项目图片列表
+// @Tags Image
+// @Security BearerAuth
+// @Param id path string true "项目ID"
+// @Success 200 {object} response.Response{data=[]ImageItem}
+// @Router /projects/{id}/images [get]
+func (h *ImageHandler) ListProjectImages(c *gin.Context) {
+	project, ok := h.getOwnedProject(c, policy.ActionView)
+	if !ok {
+		return
+	}
+
+	assets, err := h.assetRepo.ListByProjectID(c.Request.Context(), project.ID, model.AssetKindImage)
+	if err != nil {
+		log.Printf("[API] 查询项目图片失败: %v", err)
+		response.ServerError(c, "查询图片失败")
+		return
+	}
+
+	items := make([]ImageItem, len(assets))
+	for i, a := range assets {
+		var meta ImageMeta
+		_ = json.Unmarshal(a.Meta, &meta)
+		items[i] = ImageItem{Asset: a, URL: imageURL(a.ID), Role: meta.Role, SourceIndex: meta.SourceIndex}
+	}
+	response.Success(c, items)
+}
+
+// GetImage 获取图片文件
+// @Summary 获取图片文件
+// @Tags Image
+// @Security BearerAuth
+// @Param id path string true "图片资源ID"
+// @Success 200 {file} binary
+// @Router /images/{id} [get]
+func (h *ImageHandler) GetImage(c *gin.Context) {
+	asset, ok := h.getOwnedImage(c, policy.ActionView)
+	if !ok {
+		return
+	}
+	serveAssetFile(c, h.store, asset)
+}
+
+// DeleteImage 删除图片
+// @Summary 删除图片
+// @Tags Image
+// @Security BearerAuth
+// @Param id path string true "图片资源ID"
+// @Success 200 {object} response.Response
+// @Router /images/{id} [delete]
+func (h *ImageHandler) DeleteImage(c *gin.Context) {
+	asset, ok := h.getOwnedImage(c, policy.ActionEdit)
+	if !ok {
+		return
+	}
+
+	if err := deleteAssetFile(c.Request.Context(), h.store, h.assetRepo, asset); err != nil {
+		log.Printf("[API] 删除图片失败: %v", err)
+		response.ServerError(c, "删除图片失败")
+		return
+	}
+
+	auditAsset(c, h.projectRepo, asset)
+	response.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// GetProviders 获取图像生成服务商列表
+// @Summary 获取图像生成服务商列表
+// @Tags Image
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]ImageProviderItem}
+// @Router /images/providers [get]
+func (h *ImageHandler) GetProviders(c *gin.Context) {
+	current := imagegen.ProviderDashScope
+	if settings, err := h.settingsRepo.GetByUserID(c.GetInt64("userID")); err == nil && settings.ImageGenProvider != "" {
+		current = settings.ImageGenProvider
+	}
+
+	items := make([]ImageProviderItem, 0, len(imagegen.ProviderTypes))
+	for _, name := range imagegen.ProviderTypes {
+		provider, err := imagegen.NewProvider(imagegen.ProviderConfig{Type: name})
+		if err != nil {
+			continue
+		}
+		items = append(items, ImageProviderItem{
+			ID:           name,
+			Name:         imagegen.ProviderLabel(name),
+			Capabilities: provider.Capabilities(),
+			Models:       provider.Models(),
+			Current:      name == current,
+		})
+	}
+
+	response.Success(c, items)
+}
+
+// getOwnedProject 根据路径参数加载项目并检查工作区权限，失败时已写入响应
+func (h *ImageHandler) getOwnedProject(c *gin.Context, action policy.Action) (*model.Project, bool) {
+	userID := c.GetInt64("userID")
+	if userID == 0 {
+		response.Unauthorized(c, "未授权")
+		return nil, false
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID")
+		return nil, false
+	}
+	project, err := h.projectRepo.GetByID(c.Request.Context(), projectID)
+	if err != nil {
+		response.NotFound(c, "项目不存在")
+		return nil, false
+	}
+	if err := h.authorizer.AuthorizeProject(c.Request.Context(), userID, project, action); err != nil {
+		respondAuthzError(c, err)
+		return nil, false
+	}
+	return project, true
+}
+
+// getOwnedImage 根据路径参数加载图片资源并检查工作区权限，失败时已写入响应
+func (h *ImageHandler) getOwnedImage(c *gin.Context, action policy.Action) (*model.Asset, bool) {
+	userID := c.GetInt64("userID")
+	if userID == 0 {
+		response.Unauthorized(c, "未授权")
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的图片ID")
+		return nil, false
+	}
+	asset, err := h.assetRepo.GetByID(c.Request.Context(), id)
+	if err != nil || asset.Kind != model.AssetKindImage {
+		response.NotFound(c, "图片不存在")
+		return nil, false
+	}
+	if err := h.authorizer.AuthorizeAsset(c.Request.Context(), userID, asset, action); err != nil {
+		respondAuthzError(c, err)
+		return nil, false
+	}
+	return asset, true
+}
+
+// userProvider 按用户设置（工作区共享 API Key 优先）创建图像生成服务商（没有设置记录时使用默认服务商）并检查 API Key，
+// 失败时已写入响应
+func (h *ImageHandler) userProvider(c *gin.Context, userID, workspaceID int64) (imagegen.Provider, bool) {
+	settings, err := resolveSettings(c.Request.Context(), h.settingsRepo, h.workspaceRepo, h.systemRepo, userID, workspaceID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		response.ServerError(c, "获取用户设置失败")
+		return nil, false
+	}
+	if settings == nil {
+		settings = &model.UserSettings{UserID: userID}
+	}
+
+	cfg := imageGenProviderConfig(settings)
+	provider, err := imagegen.NewProvider(cfg)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return nil, false
+	}
+	if provider.Capabilities().RequiresAPIKey && cfg.APIKey == "" {
+		response.BadRequest(c, "请先在设置中配置"+imagegen.ProviderLabel(provider.Name())+"的图像生成 API Key")
+		return nil, false
+	}
+	return provider, true
+}
+
+// imageGenProviderConfig 用户设置对应的服务商配置：未单独填写图像生成 Key 时，
+// 百炼沿用通义千问 Key，OpenAI 官方接口沿用 OpenAI Key
+func imageGenProviderConfig(settings *model.UserSettings) imagegen.ProviderConfig {
+	cfg := imagegen.ProviderConfig{
+		Type:    settings.ImageGenProvider,
+		APIKey:  settings.ImageGenApiKey,
+		BaseURL: settings.ImageGenBaseURL,
+	}
+	if cfg.APIKey == "" {
+		switch cfg.Type {
+		case "", imagegen.ProviderDashScope:
+			cfg.APIKey = settings.QwenApiKey
+		case imagegen.ProviderOpenAI:
+			if cfg.BaseURL == "" {
+				cfg.APIKey = settings.OpenAIApiKey
+			}
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"copycat/internal/core/media"
+	"copycat/pkg/response"
+	"copycat/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MediaHandler 媒体缓存代理处理器
+type MediaHandler struct {
+	cache *media.Cache
+}
+
+// NewMediaHandler 创建媒体缓存代理处理器
+func NewMediaHandler(cache *media.Cache) *MediaHandler {
+	return &MediaHandler{cache: cache}
+}
+
+// GetMedia 获取缓存的笔记图片（内容按哈希寻址，可长期缓存）
+// @Summary 获取缓存图片
+// @Tags Media
+// @Security BearerAuth
+// @Param hash path string true "图片内容哈希"
+// @Param variant query string false "original（默认）/ vision（视觉模型使用的压缩图）"
+// @Success 200 {file} binary
+// @Router /media/{hash} [get]
+func (h *MediaHandler) GetMedia(c *gin.Context) {
+	hash := c.Param("hash")
+	if !media.IsValidHash(hash) {
+		response.BadRequest(c, "无效的图片哈希")
+		return
+	}
+
+	variant := c.DefaultQuery("variant", "original")
+	if variant != "original" && variant != "vision" {
+		response.BadRequest(c, "无效的图片版本: "+variant)
+		return
+	}
+
+	obj, err := h.cache.Get(c.Request.Context(), hash)
+	if err != nil {
+		response.NotFound(c, "图片不存在")
+		return
+	}
+
+	f, contentType, err := h.cache.Open(c.Request.Context(), obj, variant == "vision")
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			response.NotFound(c, "图片文件不存在")
+			return
+		}
+		log.Printf("[API] 读取缓存图片失败: %v", err)
+		response.ServerError(c, "读取图片失败")
+		return
+	}
+	defer f.Close()
+
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "private, max-age=31536000, immutable")
+	http.ServeContent(c.Writer, c.Request, hash, f.ModTime(), f)
+}
@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"copycat/config"
+	"copycat/internal/core/auth"
+	"copycat/internal/core/oidc"
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// OIDC 登录状态 Cookie
+const (
+	oidcStateCookie = "copycat_oidc"
+	oidcStatePath   = "/api/v1/oidc"
+	oidcStateTTL    = 10 * time.Minute
+)
+
+var (
+	// errSSOForbidden SSO 身份无法关联到本地账号
+	errSSOForbidden = errors.New("sso login not allowed")
+	// errSSOUnverified 同邮箱的本地账号尚未验证邮箱，不能自动关联（否则注册者可预先占用他人邮箱）
+	errSSOUnverified = errors.New("an account with this email exists but is not verified; verify the email or sign in with password first")
+)
+
+// OIDCHandler OIDC 单点登录处理器（授权码 + PKCE）
+type OIDCHandler struct {
+	provider      *oidc.Provider // 未启用时为 nil
+	userRepo      repository.UserRepository
+	workspaceRepo repository.WorkspaceRepository
+	identityRepo  repository.UserIdentityRepository
+	tokens        *auth.TokenService
+}
+
+// NewOIDCHandler 创建 OIDC 单点登录处理器，provider 为 nil 表示未启用
+func NewOIDCHandler(provider *oidc.Provider, userRepo repository.UserRepository, workspaceRepo repository.WorkspaceRepository, identityRepo repository.UserIdentityRepository, tokens *auth.TokenService) *OIDCHandler {
+	return &OIDCHandler{
+		provider:      provider,
+		userRepo:      userRepo,
+		workspaceRepo: workspaceRepo,
+		identityRepo:  identityRepo,
+		tokens:        tokens,
+	}
+}
+
+// OIDCConfigResponse 前端展示 SSO 登录按钮所需的信息
+type OIDCConfigResponse struct {
+	Enabled  bool   `json:"enabled"`
+	Name     string `json:"name,omitempty"`
+	LoginURL string `json:"login_url,omitempty"`
+}
+
+// oidcState 登录状态（签名后存入 Cookie），回调时校验 state 并取回 nonce 和 PKCE code_verifier
+type oidcState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+// GetConfig 获取 SSO 配置
+// @Summary 获取单点登录配置
+// @Tags User
+// @Produce json
+// @Success 200 {object} response.Response{data=OIDCConfigResponse}
+// @Router /api/v1/oidc/config [get]
+func (h *OIDCHandler) GetConfig(c *gin.Context) {
+	if h.provider == nil {
+		response.Success(c, OIDCConfigResponse{Enabled: false})
+		return
+	}
+	response.Success(c, OIDCConfigResponse{
+		Enabled:  true,
+		Name:     h.provider.Config().Name,
+		LoginURL: oidcStatePath + "/login",
+	})
+}
+
+// Login 跳转到身份提供方登录
+// @Summary 单点登录
+// @Tags User
+// @Success 302
+// @Router /api/v1/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	if h.provider == nil {
+		response.NotFound(c, "sso login is not enabled")
+		return
+	}
+
+	state := oidcState{
+		State:    oidc.RandomString(),
+		Nonce:    oidc.RandomString(),
+		Verifier: oidc.RandomString(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(oidcStateTTL)),
+		},
+	}
+	authURL, err := h.provider.AuthCodeURL(c.Request.Context(), state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		log.Printf("[API] OIDC discovery 失败: %v", err)
+		response.ServerError(c, "identity provider unavailable")
+		return
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, state).SignedString(oidcStateKey())
+	if err != nil {
+		response.ServerError(c, "failed to start sso login")
+		return
+	}
+
+	h.setStateCookie(c, signed, int(oidcStateTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback 身份提供方回调：校验 state、用授权码换取 ID Token、关联或创建本地账号并签发令牌
+// @Summary 单点登录回调
+// @Tags User
+// @Param code query string true "授权码"
+// @Param state query string true "state"
+// @Success 200 {object} response.Response{data=LoginResponse}
+// @Router /api/v1/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if h.provider == nil {
+		response.NotFound(c, "sso login is not enabled")
+		return
+	}
+
+	cookie, _ := c.Cookie(oidcStateCookie)
+	h.setStateCookie(c, "", -1) // 登录状态只能使用一次
+
+	if idpErr := c.Query("error"); idpErr != "" {
+		h.fail(c, http.StatusUnauthorized, "identity provider error: "+idpErr)
+		return
+	}
+
+	state := &oidcState{}
+	token, err := jwt.ParseWithClaims(cookie, state, func(t *jwt.Token) (interface{}, error) {
+		return oidcStateKey(), nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	if cookie == "" || err != nil || !token.Valid || state.State != c.Query("state") {
+		h.fail(c, http.StatusBadRequest, "invalid or expired sso login state")
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		h.fail(c, http.StatusBadRequest, "missing authorization code")
+		return
+	}
+
+	claims, err := h.provider.Exchange(c.Request.Context(), code, state.Verifier, state.Nonce)
+	if err != nil {
+		log.Printf("[API] OIDC 授权码换取失败: %v", err)
+		h.fail(c, http.StatusUnauthorized, "sso login failed")
+		return
+	}
+
+	user, err := h.resolveUser(c.Request.Context(), claims)
+	if err != nil {
+		if errors.Is(err, errSSOForbidden) || errors.Is(err, errSSOUnverified) {
+			h.fail(c, http.StatusForbidden, err.Error())
+			return
+		}
+		log.Printf("[API] OIDC 关联账号失败: %v", err)
+		h.fail(c, http.StatusInternalServerError, "failed to sign in")
+		return
+	}
+
+	pair, err := h.tokens.Issue(c.Request.Context(), user, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, auth.ErrAccountDisabled) {
+			h.fail(c, http.StatusForbidden, err.Error())
+			return
+		}
+		h.fail(c, http.StatusInternalServerError, "failed to generate token")
+		return
+	}
+
+	if frontend := h.provider.Config().FrontendURL; frontend != "" {
+		// 令牌放在 fragment 中，不会出现在服务器日志和 Referer 里
+		fragment := url.Values{
+			"token":         {pair.AccessToken},
+			"refresh_token": {pair.RefreshToken},
+			"expires_in":    {strconv.FormatInt(pair.ExpiresIn, 10)},
+		}
+		c.Redirect(http.StatusFound, frontend+"#"+fragment.Encode())
+		return
+	}
+	response.Success(c, LoginResponse{TokenPair: *pair, User: user})
+}
+
+// resolveUser 按外部身份查找本地用户：已关联的直接登录；否则按已验证的邮箱关联已有账号
+// （本地账号自身的邮箱也必须已验证）；都没有且允许注册时创建新账号
+func (h *OIDCHandler) resolveUser(ctx context.Context, claims *oidc.Claims) (*model.User, error) {
+	identity, err := h.identityRepo.GetByIssuerSubject(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		user, err := h.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if claims.Email != "" && identity.Email != claims.Email {
+			identity.Email = claims.Email
+			if err := h.identityRepo.Update(ctx, identity); err != nil {
+				return nil, err
+			}
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// 只信任身份提供方验证过的邮箱，否则任何人都能用同名邮箱接管账号
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errSSOForbidden
+	}
+	if !h.provider.EmailAllowed(claims.Email) {
+		return nil, errSSOForbidden
+	}
+
+	user, err := h.userRepo.GetByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		if !user.EmailVerified {
+			return nil, errSSOUnverified
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if !h.provider.Config().AllowSignup {
+			return nil, errSSOForbidden
+		}
+		if user, err = h.provisionUser(ctx, claims); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if err := h.identityRepo.Create(ctx, &model.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   claims.Email,
+	}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// provisionUser 为 SSO 用户创建本地账号（随机密码，可通过找回密码设置）
+func (h *OIDCHandler) provisionUser(ctx context.Context, claims *oidc.Claims) (*model.User, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(oidc.RandomString()), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	nickname := claims.Name
+	if nickname == "" {
+		nickname = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+
+	user := &model.User{
+		Email:         auth.NormalizeEmail(claims.Email),
+		Password:      string(hashed),
+		Nickname:      nickname,
+		Avatar:        claims.Picture,
+		EmailVerified: true,
+	}
+	if err := h.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	if _, err := h.workspaceRepo.EnsurePersonal(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// setStateCookie 写入或清除登录状态 Cookie
+func (h *OIDCHandler) setStateCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, oidcStatePath, "", c.Request.TLS != nil, true)
+}
+
+// oidcStateKey 登录状态 Cookie 的签名密钥，由 JWT 密钥派生，与访问令牌的签名密钥分开
+func oidcStateKey() []byte {
+	mac := hmac.New(sha256.New, []byte(config.AppCfg.JWT.Secret))
+	mac.Write([]byte("copycat-oidc-state"))
+	return mac.Sum(nil)
+}
+
+// fail 登录失败：配置了前端地址时带错误信息跳回前端，否则返回 JSON
+func (h *OIDCHandler) fail(c *gin.Context, status int, message string) {
+	if frontend := h.provider.Config().FrontendURL; frontend != "" {
+		c.Redirect(http.StatusFound, frontend+"#"+url.Values{"error": {message}}.Encode())
+		return
+	}
+	switch status {
+	case http.StatusBadRequest:
+		response.BadRequest(c, message)
+	case http.StatusUnauthorized:
+		response.Unauthorized(c, message)
+	case http.StatusForbidden:
+		response.Forbidden(c, message)
+	default:
+		response.ServerError(c, message)
+	}
+}
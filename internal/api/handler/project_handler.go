@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"log"
 
+	"copycat/internal/core/policy"
 	"copycat/internal/model"
 	"copycat/internal/repository"
 	"copycat/pkg/response"
+	"copycat/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -15,11 +19,14 @@ import (
 // ProjectHandler 项目处理器
 type ProjectHandler struct {
 	projectRepo repository.ProjectRepository
+	assetRepo   repository.AssetRepository
+	store       storage.Storage
+	authorizer  *policy.Authorizer
 }
 
 // NewProjectHandler 创建项目处理器
-func NewProjectHandler(projectRepo repository.ProjectRepository) *ProjectHandler {
-	return &ProjectHandler{projectRepo: projectRepo}
+func NewProjectHandler(projectRepo repository.ProjectRepository, assetRepo repository.AssetRepository, store storage.Storage, authorizer *policy.Authorizer) *ProjectHandler {
+	return &ProjectHandler{projectRepo: projectRepo, assetRepo: assetRepo, store: store, authorizer: authorizer}
 }
 
 // CreateProjectRequest 创建项目请求
@@ -60,6 +67,9 @@ func (h *ProjectHandler) Create(c *gin.Context) {
 		response.BadRequest(c, "invalid request: "+err.Error())
 		return
 	}
+	if !requireWorkspaceAction(c, policy.ActionEdit) {
+		return
+	}
 
 	userID, _ := c.Get("userID")
 
@@ -71,6 +81,7 @@ func (h *ProjectHandler) Create(c *gin.Context) {
 
 	project := &model.Project{
 		UserID:        userID.(int64),
+		WorkspaceID:   c.GetInt64("workspaceID"),
 		SourceURL:     req.SourceURL,
 		SourceContent: req.SourceContent,
 		ContentType:   contentType,
@@ -85,7 +96,7 @@ func (h *ProjectHandler) Create(c *gin.Context) {
 	response.Success(c, project)
 }
 
-// List 获取项目列表
+// List 获取当前工作区的项目列表
 // @Summary 获取项目列表
 // @Tags Project
 // @Security BearerAuth
@@ -109,9 +120,7 @@ func (h *ProjectHandler) List(c *gin.Context) {
 		req.PageSize = 10
 	}
 
-	userID, _ := c.Get("userID")
-
-	projects, total, err := h.projectRepo.GetByUserID(c.Request.Context(), userID.(int64), req.Page, req.PageSize)
+	projects, total, err := h.projectRepo.GetByWorkspaceID(c.Request.Context(), c.GetInt64("workspaceID"), req.Page, req.PageSize)
 	if err != nil {
 		response.ServerError(c, "failed to get projects")
 		return
@@ -146,10 +155,9 @@ func (h *ProjectHandler) Get(c *gin.Context) {
 		return
 	}
 
-	// 验证项目所有权
-	userID, _ := c.Get("userID")
-	if project.UserID != userID.(int64) {
-		response.Forbidden(c, "access denied")
+	// 验证工作区权限
+	if err := h.authorizer.AuthorizeProject(c.Request.Context(), c.GetInt64("userID"), project, policy.ActionView); err != nil {
+		respondAuthzError(c, err)
 		return
 	}
 
@@ -184,10 +192,9 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 		return
 	}
 
-	// 验证项目所有权
-	userID, _ := c.Get("userID")
-	if project.UserID != userID.(int64) {
-		response.Forbidden(c, "access denied")
+	// 验证工作区权限
+	if err := h.authorizer.AuthorizeProject(c.Request.Context(), c.GetInt64("userID"), project, policy.ActionEdit); err != nil {
+		respondAuthzError(c, err)
 		return
 	}
 
@@ -198,6 +205,7 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 	}
 
 	// 更新字段
+	before := *project
 	if req.SourceURL != "" {
 		project.SourceURL = req.SourceURL
 	}
@@ -219,6 +227,9 @@ func (h *ProjectHandler) Update(c *gin.Context) {
 		return
 	}
 
+	entry := auditEntry(c)
+	entry.SetWorkspace(project.WorkspaceID)
+	entry.SetChanges(&before, project)
 	response.Success(c, project)
 }
 
@@ -248,18 +259,21 @@ func (h *ProjectHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	// 验证项目所有权
-	userID, _ := c.Get("userID")
-	if project.UserID != userID.(int64) {
-		response.Forbidden(c, "access denied")
+	// 验证工作区权限
+	if err := h.authorizer.AuthorizeProject(c.Request.Context(), c.GetInt64("userID"), project, policy.ActionEdit); err != nil {
+		respondAuthzError(c, err)
 		return
 	}
 
-	if err := h.projectRepo.Delete(c.Request.Context(), id); err != nil {
+	if err := h.deleteProject(c.Request.Context(), id); err != nil {
+		log.Printf("[API] 删除项目失败: project=%s err=%v", id, err)
 		response.ServerError(c, "failed to delete project")
 		return
 	}
 
+	entry := auditEntry(c)
+	entry.SetWorkspace(project.WorkspaceID)
+	entry.AddDetail("source_url", project.SourceURL)
 	response.SuccessWithMessage(c, "project deleted", nil)
 }
 
@@ -278,9 +292,7 @@ func (h *ProjectHandler) GetByURL(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("userID")
-
-	project, err := h.projectRepo.GetBySourceURL(c.Request.Context(), userID.(int64), sourceURL)
+	project, err := h.projectRepo.GetBySourceURL(c.Request.Context(), c.GetInt64("workspaceID"), sourceURL)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// 没有找到已分析的项目，返回 null
@@ -328,29 +340,57 @@ func (h *ProjectHandler) BatchDelete(c *gin.Context) {
 
 	// 批量删除
 	deletedCount := 0
+	deleted := make([]gin.H, 0, len(req.IDs))
 	for _, idStr := range req.IDs {
 		projectID, err := uuid.Parse(idStr)
 		if err != nil {
 			continue // 跳过无效的 ID
 		}
 
-		// 验证项目属于当前用户
+		// 验证当前用户可以删除该项目
 		project, err := h.projectRepo.GetByID(c.Request.Context(), projectID)
 		if err != nil {
 			continue
 		}
-		if project.UserID != userID.(int64) {
+		if h.authorizer.AuthorizeProject(c.Request.Context(), userID.(int64), project, policy.ActionEdit) != nil {
 			continue
 		}
 
 		// 删除
-		if err := h.projectRepo.Delete(c.Request.Context(), projectID); err == nil {
+		if err := h.deleteProject(c.Request.Context(), projectID); err == nil {
 			deletedCount++
+			deleted = append(deleted, gin.H{"id": projectID, "workspace_id": project.WorkspaceID, "source_url": project.SourceURL})
 		}
 	}
 
+	// 审计记录归属当前工作区，每个被删除项目的所属工作区记录在明细中
+	entry := auditEntry(c)
+	if deletedCount == 0 {
+		entry.Discard()
+	}
+	entry.SetWorkspace(c.GetInt64("workspaceID"))
+	entry.AddDetail("projects", deleted)
+
 	response.Success(c, gin.H{
 		"deleted_count": deletedCount,
 		"message":       "批量删除成功",
 	})
 }
+
+// deleteProject 删除项目及其生成记录和资源；数据库删除成功后再清理对象存储中的资源文件，
+// 文件清理失败不回滚，只记录日志
+func (h *ProjectHandler) deleteProject(ctx context.Context, id uuid.UUID) error {
+	assets, err := h.assetRepo.ListByProjectID(ctx, id, "")
+	if err != nil {
+		return err
+	}
+	if err := h.projectRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	for _, asset := range assets {
+		if err := h.store.Delete(ctx, asset.StorageKey); err != nil {
+			log.Printf("[API] 删除项目资源文件失败: project=%s key=%s err=%v", id, asset.StorageKey, err)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"log"
+
+	"copycat/internal/core/imagegen"
+	"copycat/internal/core/tts"
 	"copycat/internal/model"
 	"copycat/internal/repository"
 	"copycat/pkg/response"
@@ -12,12 +16,14 @@ import (
 // SettingsHandler 设置处理器
 type SettingsHandler struct {
 	settingsRepo *repository.UserSettingsRepository
+	systemRepo   repository.SystemSettingsRepository
 }
 
 // NewSettingsHandler 创建设置处理器
 func NewSettingsHandler(db *gorm.DB) *SettingsHandler {
 	return &SettingsHandler{
 		settingsRepo: repository.NewUserSettingsRepository(db),
+		systemRepo:   repository.NewSystemSettingsRepository(db),
 	}
 }
 
@@ -44,12 +50,22 @@ type ProviderApiKeys struct {
 
 // MultiModalConfigResponse 多模态配置响应
 type MultiModalConfigResponse struct {
-	ContentAnalysis LLMConfigItem   `json:"content_analysis"`
-	ImageAnalysis   LLMConfigItem   `json:"image_analysis"`
-	VideoAnalysis   LLMConfigItem   `json:"video_analysis"`
-	ProviderKeys    ProviderApiKeys `json:"provider_keys"`
-	GenerateCount   int             `json:"generate_count"`
-	DefaultTaskType string          `json:"default_task_type"`
+	ContentAnalysis      LLMConfigItem   `json:"content_analysis"`
+	ImageAnalysis        LLMConfigItem   `json:"image_analysis"`
+	VideoAnalysis        LLMConfigItem   `json:"video_analysis"`
+	ProviderKeys         ProviderApiKeys `json:"provider_keys"`
+	GenerateCount        int             `json:"generate_count"`
+	DefaultTaskType      string          `json:"default_task_type"`
+	OriginalityThreshold float64         `json:"originality_threshold"`
+	TTS                  TTSConfigItem   `json:"tts"`
+	ImageGen             TTSConfigItem   `json:"image_gen"`
+}
+
+// TTSConfigItem 语音合成/图像生成服务商配置
+type TTSConfigItem struct {
+	Provider string `json:"provider"`
+	ApiKey   string `json:"api_key"`
+	BaseURL  string `json:"base_url"`
 }
 
 // === 请求结构体 ===
@@ -83,7 +99,22 @@ type SaveModelConfigRequest struct {
 
 // SaveGenerateConfigRequest 保存生成设置请求（模块3：仿写条数等）
 type SaveGenerateConfigRequest struct {
-	GenerateCount int `json:"generate_count"`
+	GenerateCount        int      `json:"generate_count"`
+	OriginalityThreshold *float64 `json:"originality_threshold"` // 原创度阈值(0-1)，0 表示关闭自动重新生成
+}
+
+// SaveTTSConfigRequest 保存语音合成配置请求
+type SaveTTSConfigRequest struct {
+	Provider string `json:"provider" binding:"required"` // dashscope / openai / local
+	ApiKey   string `json:"api_key"`                     // 脱敏值表示保留原有 Key
+	BaseURL  string `json:"base_url"`                    // OpenAI 兼容接口地址
+}
+
+// SaveImageGenConfigRequest 保存图像生成配置请求
+type SaveImageGenConfigRequest struct {
+	Provider string `json:"provider" binding:"required"` // dashscope / openai / local
+	ApiKey   string `json:"api_key"`                     // 脱敏值表示保留原有 Key
+	BaseURL  string `json:"base_url"`                    // OpenAI 兼容接口地址
 }
 
 // SaveTaskTypeRequest 保存任务类型请求
@@ -106,18 +137,17 @@ func (h *SettingsHandler) GetLLMConfig(c *gin.Context) {
 
 	settings, err := h.settingsRepo.GetByUserID(userID)
 	if err == gorm.ErrRecordNotFound {
-		defaultConfig := LLMConfigItem{
-			Provider: model.LLMProviderOpenAI,
-			ApiKey:   "",
-			Model:    "gpt-3.5-turbo",
-			BaseURL:  "https://api.openai.com/v1",
-		}
+		// 没有设置时返回管理员配置的系统默认值
+		defaults := h.newSettings(c, userID)
 		response.Success(c, MultiModalConfigResponse{
-			ContentAnalysis: defaultConfig,
-			ImageAnalysis:   LLMConfigItem{Provider: model.LLMProviderOpenAI, Model: "gpt-4o", BaseURL: "https://api.openai.com/v1"},
-			VideoAnalysis:   LLMConfigItem{Provider: model.LLMProviderOpenAI, Model: "gpt-4o", BaseURL: "https://api.openai.com/v1"},
-			ProviderKeys:    ProviderApiKeys{},
-			GenerateCount:   1,
+			ContentAnalysis:      LLMConfigItem{Provider: defaults.LLMProvider, Model: defaults.LLMModel, BaseURL: getProviderBaseURL(defaults.LLMProvider)},
+			ImageAnalysis:        LLMConfigItem{Provider: defaults.ImageLLMProvider, Model: defaults.ImageLLMModel, BaseURL: getProviderBaseURL(defaults.ImageLLMProvider)},
+			VideoAnalysis:        LLMConfigItem{Provider: defaults.VideoLLMProvider, Model: defaults.VideoLLMModel, BaseURL: getProviderBaseURL(defaults.VideoLLMProvider)},
+			ProviderKeys:         ProviderApiKeys{},
+			GenerateCount:        defaults.GenerateCount,
+			OriginalityThreshold: model.DefaultOriginalityThreshold,
+			TTS:                  TTSConfigItem{Provider: tts.ProviderDashScope},
+			ImageGen:             TTSConfigItem{Provider: imagegen.ProviderDashScope},
 		})
 		return
 	}
@@ -155,8 +185,19 @@ func (h *SettingsHandler) GetLLMConfig(c *gin.Context) {
 			Zhipu:     maskApiKey(settings.ZhipuApiKey),
 			Anthropic: maskApiKey(settings.AnthropicApiKey),
 		},
-		GenerateCount:   settings.GenerateCount,
-		DefaultTaskType: settings.DefaultTaskType,
+		GenerateCount:        settings.GenerateCount,
+		DefaultTaskType:      settings.DefaultTaskType,
+		OriginalityThreshold: settings.OriginalityThreshold,
+		TTS: TTSConfigItem{
+			Provider: settings.TTSProvider,
+			ApiKey:   maskApiKey(settings.TTSApiKey),
+			BaseURL:  settings.TTSBaseURL,
+		},
+		ImageGen: TTSConfigItem{
+			Provider: settings.ImageGenProvider,
+			ApiKey:   maskApiKey(settings.ImageGenApiKey),
+			BaseURL:  settings.ImageGenBaseURL,
+		},
 	})
 }
 
@@ -183,8 +224,9 @@ func (h *SettingsHandler) SaveApiConfig(c *gin.Context) {
 	// 获取现有配置
 	existing, _ := h.settingsRepo.GetByUserID(userID)
 	if existing == nil {
-		existing = &model.UserSettings{UserID: userID}
+		existing = h.newSettings(c, userID)
 	}
+	before := *existing
 
 	// 更新服务商和 Base URL
 	if req.ContentAnalysis.Provider != "" {
@@ -225,6 +267,7 @@ func (h *SettingsHandler) SaveApiConfig(c *gin.Context) {
 		response.ServerError(c, "保存配置失败")
 		return
 	}
+	h.auditChanges(c, userID, &before, existing)
 
 	response.Success(c, gin.H{"message": "API 配置保存成功"})
 }
@@ -252,8 +295,9 @@ func (h *SettingsHandler) SaveModelConfig(c *gin.Context) {
 	// 获取现有配置
 	existing, _ := h.settingsRepo.GetByUserID(userID)
 	if existing == nil {
-		existing = &model.UserSettings{UserID: userID}
+		existing = h.newSettings(c, userID)
 	}
+	before := *existing
 
 	// 更新模型和服务商
 	if req.ContentModel != "" {
@@ -286,6 +330,7 @@ func (h *SettingsHandler) SaveModelConfig(c *gin.Context) {
 		response.ServerError(c, "保存配置失败")
 		return
 	}
+	h.auditChanges(c, userID, &before, existing)
 
 	response.Success(c, gin.H{"message": "模型配置保存成功"})
 }
@@ -313,25 +358,134 @@ func (h *SettingsHandler) SaveGenerateConfig(c *gin.Context) {
 	// 获取现有配置
 	existing, _ := h.settingsRepo.GetByUserID(userID)
 	if existing == nil {
-		existing = &model.UserSettings{UserID: userID}
+		existing = h.newSettings(c, userID)
 	}
+	before := *existing
 
 	// 更新生成配置
 	if req.GenerateCount > 0 {
-		if req.GenerateCount > 10 {
-			req.GenerateCount = 10
+		if limit := h.system(c).MaxGenerateCount; req.GenerateCount > limit {
+			req.GenerateCount = limit
 		}
 		existing.GenerateCount = req.GenerateCount
 	}
+	if req.OriginalityThreshold != nil {
+		if *req.OriginalityThreshold < 0 || *req.OriginalityThreshold > 1 {
+			response.BadRequest(c, "原创度阈值需在 0-1 之间")
+			return
+		}
+		existing.OriginalityThreshold = *req.OriginalityThreshold
+	}
 
 	if err := h.settingsRepo.Upsert(existing); err != nil {
 		response.ServerError(c, "保存配置失败")
 		return
 	}
+	h.auditChanges(c, userID, &before, existing)
 
 	response.Success(c, gin.H{"message": "生成设置保存成功"})
 }
 
+// SaveTTSConfig 保存语音合成配置
+// @Summary 保存语音合成配置
+// @Tags Settings
+// @Security BearerAuth
+// @Param request body SaveTTSConfigRequest true "语音合成配置"
+// @Success 200 {object} response.Response
+// @Router /settings/tts-config [post]
+func (h *SettingsHandler) SaveTTSConfig(c *gin.Context) {
+	userID := c.GetInt64("userID")
+	if userID == 0 {
+		response.Unauthorized(c, "请先登录")
+		return
+	}
+
+	var req SaveTTSConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if !tts.IsValidProvider(req.Provider) {
+		response.BadRequest(c, "不支持的语音合成服务商: "+req.Provider)
+		return
+	}
+
+	// 获取现有配置
+	existing, _ := h.settingsRepo.GetByUserID(userID)
+	if existing == nil {
+		existing = h.newSettings(c, userID)
+	}
+	before := *existing
+
+	// 前端回传脱敏值时保留原有 Key
+	apiKey := req.ApiKey
+	if isMaskedApiKey(apiKey) {
+		apiKey = existing.TTSApiKey
+	}
+
+	existing.TTSProvider = req.Provider
+	existing.TTSApiKey = apiKey
+	existing.TTSBaseURL = req.BaseURL
+
+	if err := h.settingsRepo.Upsert(existing); err != nil {
+		response.ServerError(c, "保存配置失败")
+		return
+	}
+	h.auditChanges(c, userID, &before, existing)
+
+	response.Success(c, gin.H{"message": "语音合成配置保存成功"})
+}
+
+// SaveImageGenConfig 保存图像生成配置
+// @Summary 保存图像生成配置
+// @Tags Settings
+// @Security BearerAuth
+// @Param request body SaveImageGenConfigRequest true "图像生成配置"
+// @Success 200 {object} response.Response
+// @Router /settings/image-gen-config [post]
+func (h *SettingsHandler) SaveImageGenConfig(c *gin.Context) {
+	userID := c.GetInt64("userID")
+	if userID == 0 {
+		response.Unauthorized(c, "请先登录")
+		return
+	}
+
+	var req SaveImageGenConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if !imagegen.IsValidProvider(req.Provider) {
+		response.BadRequest(c, "不支持的图像生成服务商: "+req.Provider)
+		return
+	}
+
+	// 获取现有配置
+	existing, _ := h.settingsRepo.GetByUserID(userID)
+	if existing == nil {
+		existing = h.newSettings(c, userID)
+	}
+	before := *existing
+
+	// 前端回传脱敏值时保留原有 Key
+	apiKey := req.ApiKey
+	if isMaskedApiKey(apiKey) {
+		apiKey = existing.ImageGenApiKey
+	}
+
+	existing.ImageGenProvider = req.Provider
+	existing.ImageGenApiKey = apiKey
+	existing.ImageGenBaseURL = req.BaseURL
+
+	if err := h.settingsRepo.Upsert(existing); err != nil {
+		response.ServerError(c, "保存配置失败")
+		return
+	}
+	h.auditChanges(c, userID, &before, existing)
+
+	response.Success(c, gin.H{"message": "图像生成配置保存成功"})
+}
+
 // SaveTaskType 保存任务类型偏好
 func (h *SettingsHandler) SaveTaskType(c *gin.Context) {
 	userID := c.GetInt64("userID")
@@ -349,8 +503,9 @@ func (h *SettingsHandler) SaveTaskType(c *gin.Context) {
 	// 获取现有配置
 	existing, _ := h.settingsRepo.GetByUserID(userID)
 	if existing == nil {
-		existing = &model.UserSettings{UserID: userID}
+		existing = h.newSettings(c, userID)
 	}
+	before := *existing
 
 	// 更新
 	if req.TaskType != "" {
@@ -361,6 +516,7 @@ func (h *SettingsHandler) SaveTaskType(c *gin.Context) {
 		response.ServerError(c, "保存配置失败")
 		return
 	}
+	h.auditChanges(c, userID, &before, existing)
 
 	response.Success(c, gin.H{"message": "任务偏好保存成功"})
 }
@@ -376,9 +532,14 @@ func maskApiKey(apiKey string) string {
 	return apiKey[:4] + "****" + apiKey[len(apiKey)-4:]
 }
 
+// isMaskedApiKey 判断是否为 maskApiKey 生成的脱敏值
+func isMaskedApiKey(key string) bool {
+	return len(key) > 0 && (key == "****" || (len(key) > 8 && key[4:8] == "****"))
+}
+
 // getApiKeyOrKeepExisting 保留现有 key
 func getApiKeyOrKeepExisting(newKey string, existing *model.UserSettings, provider string) string {
-	if len(newKey) > 0 && (newKey == "****" || (len(newKey) > 8 && newKey[4:8] == "****")) {
+	if isMaskedApiKey(newKey) {
 		if existing != nil {
 			return getProviderApiKeyByName(existing, provider)
 		}
@@ -441,3 +602,25 @@ func getProviderBaseURL(provider string) string {
 		return "https://api.openai.com/v1"
 	}
 }
+
+// system 获取系统设置，查询失败时使用默认值
+func (h *SettingsHandler) system(c *gin.Context) *model.SystemSettings {
+	system, err := h.systemRepo.Get(c.Request.Context())
+	if err != nil {
+		log.Printf("[API] 获取系统设置失败: %v", err)
+		return model.DefaultSystemSettings()
+	}
+	return system
+}
+
+// newSettings 用户首次保存设置时的初始值（服务商、模型和生成条数取系统默认值）
+func (h *SettingsHandler) newSettings(c *gin.Context, userID int64) *model.UserSettings {
+	return defaultUserSettings(userID, h.system(c))
+}
+
+// auditChanges 在审计记录中写入设置变更（API Key 已脱敏）
+func (h *SettingsHandler) auditChanges(c *gin.Context, userID int64, before, after *model.UserSettings) {
+	entry := auditEntry(c)
+	entry.SetTarget("user_settings", userID)
+	entry.SetChanges(before, after)
+}
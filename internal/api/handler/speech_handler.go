@@ -1,38 +1,116 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"copycat/config"
+	"copycat/internal/core/policy"
 	"copycat/internal/core/tts"
+	"copycat/internal/model"
 	"copycat/internal/repository"
 	"copycat/pkg/response"
+	"copycat/pkg/storage"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
 // SpeechHandler 语音合成处理器
 type SpeechHandler struct {
-	settingsRepo *repository.UserSettingsRepository
+	settingsRepo   *repository.UserSettingsRepository
+	projectRepo    repository.ProjectRepository
+	generationRepo repository.GenerationRepository
+	assetRepo      repository.AssetRepository
+	workspaceRepo  repository.WorkspaceRepository
+	systemRepo     repository.SystemSettingsRepository
+	store          storage.Storage
+	authorizer     *policy.Authorizer
 }
 
 // NewSpeechHandler 创建语音合成处理器
-func NewSpeechHandler(db *gorm.DB) *SpeechHandler {
+func NewSpeechHandler(db *gorm.DB, store storage.Storage, authorizer *policy.Authorizer) *SpeechHandler {
 	return &SpeechHandler{
-		settingsRepo: repository.NewUserSettingsRepository(db),
+		settingsRepo:   repository.NewUserSettingsRepository(db),
+		projectRepo:    repository.NewProjectRepository(db),
+		generationRepo: repository.NewGenerationRepository(db),
+		assetRepo:      repository.NewAssetRepository(db),
+		workspaceRepo:  repository.NewWorkspaceRepository(db),
+		systemRepo:     repository.NewSystemSettingsRepository(db),
+		store:          store,
+		authorizer:     authorizer,
 	}
 }
 
 // GenerateSpeechRequest 语音合成请求
 type GenerateSpeechRequest struct {
-	Text  string `json:"text" binding:"required"`  // 要合成的文本
-	Voice string `json:"voice" binding:"required"` // 音色
-	Model string `json:"model"`                    // 模型 (可选，默认 qwen3-tts-flash)
+	Text         string `json:"text" binding:"required"`  // 要合成的文本，支持 <break time="500ms"/> 停顿和 <emphasis> 重读标记
+	Voice        string `json:"voice" binding:"required"` // 音色
+	Model        string `json:"model"`                    // 模型 (可选，默认使用服务商的默认模型)
+	ProjectID    string `json:"project_id"`               // 关联项目 (可选)
+	GenerationID string `json:"generation_id"`            // 关联生成记录 (可选，填写后自动关联其所属项目)
+
+	// 多角色配音 (mode=script)
+	Mode           string            `json:"mode"`             // single (默认) / script
+	Voices         map[string]string `json:"voices"`           // 角色 -> 音色，未指定的角色自动分配；旁白使用 voice
+	LinePauseMs    *int              `json:"line_pause_ms"`    // 台词之间的停顿，默认 300ms
+	SegmentPauseMs *int              `json:"segment_pause_ms"` // 片段之间的停顿，默认 800ms
+
+	// 合成参数（可选，服务商不支持的参数会被忽略并在 warnings 中说明）
+	Rate       float64 `json:"rate"`        // 语速倍率 0.5-2.0
+	Pitch      float64 `json:"pitch"`       // 音调倍率 0.5-2.0
+	Volume     int     `json:"volume"`      // 音量 1-100，默认 50
+	Emotion    string  `json:"emotion"`     // 情感/语气描述
+	Format     string  `json:"format"`      // 输出格式 mp3/wav
+	SampleRate int     `json:"sample_rate"` // 采样率
+}
+
+// 语音合成模式
+const (
+	SpeechModeSingle = "single" // 单音色朗读
+	SpeechModeScript = "script" // 按脚本角色多音色配音
+)
+
+// ParseScriptRequest 脚本解析预览请求
+type ParseScriptRequest struct {
+	Script string            `json:"script" binding:"required"` // 视频脚本
+	Voice  string            `json:"voice" binding:"required"`  // 旁白/默认音色
+	Model  string            `json:"model"`                     // 模型 (可选)
+	Voices map[string]string `json:"voices"`                    // 角色 -> 音色 (可选)
 }
 
 // GenerateSpeechResponse 语音合成响应
 type GenerateSpeechResponse struct {
-	AudioBase64 string `json:"audio_base64"` // Base64 编码的音频
-	Format      string `json:"format"`       // 音频格式 (mp3)
-	Characters  int    `json:"characters"`   // 合成的字符数
+	Asset      *model.Asset      `json:"asset"`              // 音频资源记录
+	URL        string            `json:"url"`                // 音频播放地址
+	Format     string            `json:"format"`             // 音频格式 (mp3/wav)
+	SampleRate int               `json:"sample_rate"`        // 采样率
+	DurationMs int64             `json:"duration_ms"`        // 总时长（毫秒）
+	Characters int               `json:"characters"`         // 合成的字符数
+	Chunks     []tts.ChunkTiming `json:"chunks"`             // 分段时间轴
+	Subtitles  map[string]string `json:"subtitles"`          // 字幕下载地址 (srt/vtt)
+	Speakers   map[string]string `json:"speakers,omitempty"` // 角色 -> 音色 (多角色配音时)
+	Warnings   []string          `json:"warnings,omitempty"` // 被忽略或近似处理的参数
+}
+
+// AudioMeta 音频资源的附加信息（保存在 Asset.Meta 中）
+type AudioMeta struct {
+	Mode       string               `json:"mode"`
+	Provider   string               `json:"provider"`
+	Voice      string               `json:"voice"`
+	Speakers   map[string]string    `json:"speakers,omitempty"`
+	Model      string               `json:"model"`
+	Options    tts.SynthesisOptions `json:"options"`
+	SampleRate int                  `json:"sample_rate"`
+	Characters int                  `json:"characters"`
+	Chunks     []tts.ChunkTiming    `json:"chunks"`
 }
 
 // VoiceItem 音色信息
@@ -43,6 +121,16 @@ type VoiceItem struct {
 	Gender      string `json:"gender"`      // 性别
 }
 
+// ProviderItem 语音合成服务商信息
+type ProviderItem struct {
+	ID           string           `json:"id"`           // 服务商标识
+	Name         string           `json:"name"`         // 展示名称
+	Capabilities tts.Capabilities `json:"capabilities"` // 能力说明
+	Models       []ModelItem      `json:"models"`       // 可用模型
+	Available    bool             `json:"available"`    // 是否可用（本地引擎需服务端配置）
+	Current      bool             `json:"current"`      // 是否为当前用户选择的服务商
+}
+
 // ModelItem 模型信息
 type ModelItem struct {
 	ID          string `json:"id"`          // 模型ID
@@ -50,6 +138,7 @@ type ModelItem struct {
 	Description string `json:"description"` // 描述
 	VoiceCount  int    `json:"voice_count"` // 音色数量
 	Languages   string `json:"languages"`   // 支持语言
+	MaxChars    int    `json:"max_chars"`   // 单次请求最大字数
 }
 
 // GenerateSpeech 生成语音
@@ -80,59 +169,394 @@ func (h *SpeechHandler) GenerateSpeech(c *gin.Context) {
 		return
 	}
 
-	if len(req.Text) > 10000 {
-		response.BadRequest(c, "文本长度不能超过 10000 字符")
+	if utf8.RuneCountInString(req.Text) > tts.MaxLongTextChars {
+		response.BadRequest(c, fmt.Sprintf("文本长度不能超过 %d 字", tts.MaxLongTextChars))
 		return
 	}
 
-	// 默认模型
-	model := req.Model
-	if model == "" {
-		model = tts.DefaultModel
+	// 校验关联的项目/生成记录
+	uid := userID.(int64)
+	projectID, generationID, workspaceID, ok := h.resolveSpeechTarget(c, uid, req.ProjectID, req.GenerationID)
+	if !ok {
+		return
+	}
+
+	// 按用户设置选择语音合成服务商
+	client, ok := h.userTTSClient(c, uid, workspaceID, true)
+	if !ok {
+		return
 	}
 
-	// 验证模型
-	if !tts.IsValidModel(model) {
-		response.BadRequest(c, "无效的模型: "+model)
+	// 验证模型（为空时使用服务商默认模型）
+	modelInfo, err := client.ResolveModel(req.Model)
+	if err != nil {
+		response.BadRequest(c, err.Error())
 		return
 	}
+	ttsModel := modelInfo.ID
 
 	// 验证音色（根据模型）
-	if !tts.IsValidVoiceForModel(req.Voice, model) {
-		response.BadRequest(c, "无效的音色: "+req.Voice+" (模型: "+model+")")
+	if err := client.ValidateVoice(req.Voice, ttsModel); err != nil {
+		response.BadRequest(c, err.Error())
 		return
 	}
 
-	// 获取用户设置
-	settings, err := h.settingsRepo.GetByUserID(userID.(int64))
-	if err != nil {
-		response.ServerError(c, "获取用户设置失败")
+	// 验证合成参数
+	synthOpts := tts.SynthesisOptions{
+		Rate:       req.Rate,
+		Pitch:      req.Pitch,
+		Volume:     req.Volume,
+		Emotion:    req.Emotion,
+		Format:     req.Format,
+		SampleRate: req.SampleRate,
+	}
+	if err := synthOpts.Validate(client.Provider().Capabilities()); err != nil {
+		response.BadRequest(c, err.Error())
 		return
 	}
 
-	// 获取 API Key（使用 Qwen API Key，因为阿里云百炼使用同一个）
-	apiKey := settings.QwenApiKey
-	if apiKey == "" {
-		response.BadRequest(c, "请先在设置中配置通义千问 (Qwen) API Key")
+	// 解析多角色脚本
+	mode := req.Mode
+	if mode == "" {
+		mode = SpeechModeSingle
+	}
+	var lines []tts.ScriptLine
+	var speakers map[string]string
+	var scriptOpts tts.ScriptOptions
+	switch mode {
+	case SpeechModeSingle:
+	case SpeechModeScript:
+		lines = tts.ParseScript(req.Text)
+		speakers, err = client.AssignVoices(tts.ScriptSpeakers(lines), req.Voices, req.Voice, ttsModel)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		scriptOpts = tts.ScriptOptions{
+			LinePauseMs:    pauseOrDefault(req.LinePauseMs, tts.DefaultLinePauseMs),
+			SegmentPauseMs: pauseOrDefault(req.SegmentPauseMs, tts.DefaultSegmentPauseMs),
+			Concurrency:    tts.DefaultChunkConcurrency,
+			Synthesis:      synthOpts,
+		}
+		if scriptOpts.LinePauseMs < 0 || scriptOpts.LinePauseMs > tts.MaxPauseMs ||
+			scriptOpts.SegmentPauseMs < 0 || scriptOpts.SegmentPauseMs > tts.MaxPauseMs {
+			response.BadRequest(c, fmt.Sprintf("停顿时长需在 0-%d 毫秒之间", tts.MaxPauseMs))
+			return
+		}
+	default:
+		response.BadRequest(c, "无效的合成模式: "+mode)
 		return
 	}
 
-	// 创建 TTS 客户端并合成语音
-	client := tts.NewClient(apiKey)
-	result, err := client.Synthesize(req.Text, req.Voice, model)
+	// 合成语音（超过模型单次限制时自动分段合成并拼接）
+	var result *tts.LongSynthesizeResult
+	if mode == SpeechModeScript {
+		result, err = client.SynthesizeScript(lines, speakers, ttsModel, scriptOpts)
+	} else {
+		result, err = client.SynthesizeLong(req.Text, req.Voice, ttsModel, synthOpts, tts.DefaultChunkConcurrency)
+	}
 	if err != nil {
 		response.ServerError(c, "语音合成失败: "+err.Error())
 		return
 	}
 
+	// 保存音频文件和资源记录
+	meta, _ := json.Marshal(AudioMeta{
+		Mode:       mode,
+		Provider:   client.Provider().Name(),
+		Voice:      req.Voice,
+		Speakers:   speakers,
+		Model:      ttsModel,
+		Options:    synthOpts,
+		SampleRate: result.SampleRate,
+		Characters: result.Characters,
+		Chunks:     result.Chunks,
+	})
+	asset := &model.Asset{
+		ID:           uuid.New(),
+		UserID:       uid,
+		ProjectID:    projectID,
+		GenerationID: generationID,
+		Kind:         model.AssetKindAudio,
+		ContentType:  tts.ContentType(result.Format),
+		Format:       result.Format,
+		Size:         int64(len(result.Audio)),
+		DurationMs:   result.DurationMs,
+		Meta:         datatypes.JSON(meta),
+	}
+	if err := saveAssetFile(c.Request.Context(), h.store, h.assetRepo, asset, result.Audio); err != nil {
+		log.Printf("[API] 保存音频失败: %v", err)
+		response.ServerError(c, "保存音频失败")
+		return
+	}
+
 	// 返回结果
 	response.Success(c, GenerateSpeechResponse{
-		AudioBase64: result.AudioBase64,
-		Format:      result.Format,
-		Characters:  result.Characters,
+		Asset:      asset,
+		URL:        speechURL(asset.ID),
+		Format:     result.Format,
+		SampleRate: result.SampleRate,
+		DurationMs: result.DurationMs,
+		Characters: result.Characters,
+		Chunks:     result.Chunks,
+		Subtitles:  subtitleURLs(asset.ID),
+		Speakers:   speakers,
+		Warnings:   result.Warnings,
+	})
+}
+
+// ParseScript 预览脚本解析结果和角色音色分配（不调用合成接口）
+// @Summary 解析多角色脚本
+// @Tags Speech
+// @Security BearerAuth
+// @Param request body ParseScriptRequest true "脚本解析请求"
+// @Success 200 {object} response.Response
+// @Router /speech/script/parse [post]
+func (h *SpeechHandler) ParseScript(c *gin.Context) {
+	var req ParseScriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "请求参数错误: "+err.Error())
+		return
+	}
+	if utf8.RuneCountInString(req.Script) > tts.MaxLongTextChars {
+		response.BadRequest(c, fmt.Sprintf("脚本长度不能超过 %d 字", tts.MaxLongTextChars))
+		return
+	}
+
+	client, ok := h.userTTSClient(c, c.GetInt64("userID"), c.GetInt64("workspaceID"), false)
+	if !ok {
+		return
+	}
+
+	lines := tts.ParseScript(req.Script)
+	speakers, err := client.AssignVoices(tts.ScriptSpeakers(lines), req.Voices, req.Voice, req.Model)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"lines":    lines,
+		"speakers": speakers,
 	})
 }
 
+// pauseOrDefault 未填写停顿时使用默认值
+func pauseOrDefault(v *int, def int) int {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+// resolveSpeechTarget 校验并解析语音要关联的项目和生成记录（只填生成记录时自动使用其所属项目），
+// 返回计费使用的工作区（关联项目时为项目所属工作区，否则为当前工作区），失败时已写入响应
+func (h *SpeechHandler) resolveSpeechTarget(c *gin.Context, userID int64, projectIDStr, generationIDStr string) (*uuid.UUID, *uuid.UUID, int64, bool) {
+	ctx := c.Request.Context()
+	var projectID, generationID *uuid.UUID
+
+	if generationIDStr != "" {
+		id, err := uuid.Parse(generationIDStr)
+		if err != nil {
+			response.BadRequest(c, "无效的生成记录ID")
+			return nil, nil, 0, false
+		}
+		generation, err := h.generationRepo.GetByID(ctx, id)
+		if err != nil {
+			response.NotFound(c, "生成记录不存在")
+			return nil, nil, 0, false
+		}
+		generationID = &generation.ID
+		projectID = &generation.ProjectID
+	}
+
+	if projectIDStr != "" {
+		id, err := uuid.Parse(projectIDStr)
+		if err != nil {
+			response.BadRequest(c, "无效的项目ID")
+			return nil, nil, 0, false
+		}
+		if projectID != nil && *projectID != id {
+			response.BadRequest(c, "生成记录不属于该项目")
+			return nil, nil, 0, false
+		}
+		projectID = &id
+	}
+
+	if projectID == nil {
+		if !requireWorkspaceAction(c, policy.ActionEdit) {
+			return nil, nil, 0, false
+		}
+		return nil, generationID, c.GetInt64("workspaceID"), true
+	}
+
+	project, err := h.authorizer.AuthorizeProjectID(ctx, userID, *projectID, policy.ActionEdit)
+	if err != nil {
+		respondAuthzError(c, err)
+		return nil, nil, 0, false
+	}
+	return &project.ID, generationID, project.WorkspaceID, true
+}
+
+// speechURL 音频播放地址
+func speechURL(id uuid.UUID) string {
+	return "/api/v1/speech/" + id.String()
+}
+
+// subtitleURLs 与音频对齐的字幕下载地址
+func subtitleURLs(id uuid.UUID) map[string]string {
+	return map[string]string{
+		tts.SubtitleSRT: speechURL(id) + "/subtitles?format=" + tts.SubtitleSRT,
+		tts.SubtitleVTT: speechURL(id) + "/subtitles?format=" + tts.SubtitleVTT,
+	}
+}
+
+// StreamSpeech 播放/下载音频（支持 Range 请求，可直接用于 <audio> 拖动进度）
+// @Summary 获取音频文件
+// @Tags Speech
+// @Security BearerAuth
+// @Param id path string true "音频资源ID"
+// @Success 200 {file} binary
+// @Router /speech/{id} [get]
+func (h *SpeechHandler) StreamSpeech(c *gin.Context) {
+	asset, ok := h.getOwnedAudio(c, policy.ActionView)
+	if !ok {
+		return
+	}
+
+	serveAssetFile(c, h.store, asset)
+}
+
+// ExportSubtitles 导出与音频对齐的字幕文件
+// @Summary 导出字幕 (SRT/WebVTT)
+// @Tags Speech
+// @Security BearerAuth
+// @Param id path string true "音频资源ID"
+// @Param format query string false "字幕格式 srt/vtt，默认 srt"
+// @Param max_chars query int false "单条字幕最大字数，默认 16"
+// @Success 200 {file} binary
+// @Router /speech/{id}/subtitles [get]
+func (h *SpeechHandler) ExportSubtitles(c *gin.Context) {
+	asset, ok := h.getOwnedAudio(c, policy.ActionView)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", tts.SubtitleSRT)
+	maxChars, err := strconv.Atoi(c.DefaultQuery("max_chars", strconv.Itoa(tts.DefaultCueMaxChars)))
+	if err != nil || maxChars < 4 || maxChars > 100 {
+		response.BadRequest(c, "max_chars 需在 4-100 之间")
+		return
+	}
+
+	var meta AudioMeta
+	if err := json.Unmarshal(asset.Meta, &meta); err != nil || len(meta.Chunks) == 0 {
+		response.BadRequest(c, "该音频没有时间轴信息，无法生成字幕")
+		return
+	}
+
+	content, err := tts.FormatSubtitles(tts.BuildCues(meta.Chunks, maxChars), format)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	filename := asset.ID.String() + "." + format
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, tts.SubtitleContentType(format), []byte(content))
+}
+
+// DeleteSpeech 删除音频
+// @Summary 删除音频
+// @Tags Speech
+// @Security BearerAuth
+// @Param id path string true "音频资源ID"
+// @Success 200 {object} response.Response
+// @Router /speech/{id} [delete]
+func (h *SpeechHandler) DeleteSpeech(c *gin.Context) {
+	asset, ok := h.getOwnedAudio(c, policy.ActionEdit)
+	if !ok {
+		return
+	}
+
+	if err := deleteAssetFile(c.Request.Context(), h.store, h.assetRepo, asset); err != nil {
+		log.Printf("[API] 删除音频失败: %v", err)
+		response.ServerError(c, "删除音频失败")
+		return
+	}
+
+	auditAsset(c, h.projectRepo, asset)
+	response.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// ListProjectAudio 获取项目下的全部音频
+// @Summary 获取项目音频列表
+// @Tags Speech
+// @Security BearerAuth
+// @Param id path string true "项目ID"
+// @Success 200 {object} response.Response{data=[]model.Asset}
+// @Router /projects/{id}/audio [get]
+func (h *SpeechHandler) ListProjectAudio(c *gin.Context) {
+	userID := c.GetInt64("userID")
+	if userID == 0 {
+		response.Unauthorized(c, "未授权")
+		return
+	}
+
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的项目ID")
+		return
+	}
+	project, err := h.projectRepo.GetByID(c.Request.Context(), projectID)
+	if err != nil {
+		response.NotFound(c, "项目不存在")
+		return
+	}
+	if err := h.authorizer.AuthorizeProject(c.Request.Context(), userID, project, policy.ActionView); err != nil {
+		respondAuthzError(c, err)
+		return
+	}
+
+	assets, err := h.assetRepo.ListByProjectID(c.Request.Context(), projectID, model.AssetKindAudio)
+	if err != nil {
+		log.Printf("[API] 查询项目音频失败: %v", err)
+		response.ServerError(c, "查询音频失败")
+		return
+	}
+
+	items := make([]gin.H, len(assets))
+	for i, a := range assets {
+		items[i] = gin.H{"asset": a, "url": speechURL(a.ID), "subtitles": subtitleURLs(a.ID)}
+	}
+	response.Success(c, items)
+}
+
+// getOwnedAudio 根据路径参数加载音频资源并检查工作区权限，失败时已写入响应
+func (h *SpeechHandler) getOwnedAudio(c *gin.Context, action policy.Action) (*model.Asset, bool) {
+	userID := c.GetInt64("userID")
+	if userID == 0 {
+		response.Unauthorized(c, "未授权")
+		return nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "无效的音频ID")
+		return nil, false
+	}
+	asset, err := h.assetRepo.GetByID(c.Request.Context(), id)
+	if err != nil || asset.Kind != model.AssetKindAudio {
+		response.NotFound(c, "音频不存在")
+		return nil, false
+	}
+	if err := h.authorizer.AuthorizeAsset(c.Request.Context(), userID, asset, action); err != nil {
+		respondAuthzError(c, err)
+		return nil, false
+	}
+	return asset, true
+}
+
 // GetVoices 获取可用音色列表
 // @Summary 获取可用音色列表
 // @Tags Speech
@@ -141,12 +565,12 @@ func (h *SpeechHandler) GenerateSpeech(c *gin.Context) {
 // @Success 200 {object} response.Response{data=[]VoiceItem}
 // @Router /speech/voices [get]
 func (h *SpeechHandler) GetVoices(c *gin.Context) {
-	model := c.Query("model")
-	if model == "" {
-		model = tts.DefaultModel
+	client, ok := h.userTTSClient(c, c.GetInt64("userID"), c.GetInt64("workspaceID"), false)
+	if !ok {
+		return
 	}
 
-	voices := tts.GetVoicesForModel(model)
+	voices := client.Voices(c.Query("model"))
 
 	// 转换为响应格式
 	items := make([]VoiceItem, len(voices))
@@ -169,19 +593,127 @@ func (h *SpeechHandler) GetVoices(c *gin.Context) {
 // @Success 200 {object} response.Response{data=[]ModelItem}
 // @Router /speech/models [get]
 func (h *SpeechHandler) GetModels(c *gin.Context) {
-	models := tts.GetModels()
+	client, ok := h.userTTSClient(c, c.GetInt64("userID"), c.GetInt64("workspaceID"), false)
+	if !ok {
+		return
+	}
+
+	models := client.Models()
 
 	// 转换为响应格式
 	items := make([]ModelItem, len(models))
 	for i, m := range models {
-		items[i] = ModelItem{
-			ID:          m.ID,
-			Name:        m.Name,
-			Description: m.Description,
-			VoiceCount:  m.VoiceCount,
-			Languages:   m.Languages,
+		items[i] = toModelItem(m)
+	}
+
+	response.Success(c, items)
+}
+
+// GetProviders 获取语音合成服务商列表
+// @Summary 获取语音合成服务商列表
+// @Tags Speech
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]ProviderItem}
+// @Router /speech/providers [get]
+func (h *SpeechHandler) GetProviders(c *gin.Context) {
+	current := tts.ProviderDashScope
+	if settings, err := h.settingsRepo.GetByUserID(c.GetInt64("userID")); err == nil && settings.TTSProvider != "" {
+		current = settings.TTSProvider
+	}
+
+	items := make([]ProviderItem, 0, len(tts.ProviderTypes))
+	for _, name := range tts.ProviderTypes {
+		provider, err := tts.NewProvider(tts.ProviderConfig{Type: name, Local: localTTSConfig()})
+		if err != nil {
+			continue
+		}
+		models := provider.Models()
+		item := ProviderItem{
+			ID:           name,
+			Name:         tts.ProviderLabel(name),
+			Capabilities: provider.Capabilities(),
+			Models:       make([]ModelItem, len(models)),
+			Available:    len(models) > 0,
+			Current:      name == current,
 		}
+		for i, m := range models {
+			item.Models[i] = toModelItem(m)
+		}
+		items = append(items, item)
 	}
 
 	response.Success(c, items)
 }
+
+// userTTSClient 按用户设置（工作区共享 API Key 优先）创建语音合成客户端（没有设置记录时使用默认服务商），失败时已写入响应。
+// requireReady 为 true 时检查 API Key 和本地引擎是否已配置
+func (h *SpeechHandler) userTTSClient(c *gin.Context, userID, workspaceID int64, requireReady bool) (*tts.Client, bool) {
+	settings, err := resolveSettings(c.Request.Context(), h.settingsRepo, h.workspaceRepo, h.systemRepo, userID, workspaceID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		response.ServerError(c, "获取用户设置失败")
+		return nil, false
+	}
+	if settings == nil {
+		settings = &model.UserSettings{UserID: userID}
+	}
+
+	cfg := ttsProviderConfig(settings)
+	provider, err := tts.NewProvider(cfg)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return nil, false
+	}
+
+	if requireReady {
+		if provider.Capabilities().RequiresAPIKey && cfg.APIKey == "" {
+			response.BadRequest(c, "请先在设置中配置"+tts.ProviderLabel(provider.Name())+"的语音合成 API Key")
+			return nil, false
+		}
+		if len(provider.Models()) == 0 {
+			response.BadRequest(c, "服务端未启用"+tts.ProviderLabel(provider.Name())+"，请在设置中更换语音合成服务商")
+			return nil, false
+		}
+	}
+	return tts.NewClient(provider), true
+}
+
+// ttsProviderConfig 用户设置对应的服务商配置：未单独填写语音合成 Key 时，
+// 百炼沿用通义千问 Key，OpenAI 官方接口沿用 OpenAI Key
+func ttsProviderConfig(settings *model.UserSettings) tts.ProviderConfig {
+	cfg := tts.ProviderConfig{
+		Type:    settings.TTSProvider,
+		APIKey:  settings.TTSApiKey,
+		BaseURL: settings.TTSBaseURL,
+		Local:   localTTSConfig(),
+	}
+	if cfg.APIKey == "" {
+		switch cfg.Type {
+		case "", tts.ProviderDashScope:
+			cfg.APIKey = settings.QwenApiKey
+		case tts.ProviderOpenAI:
+			if cfg.BaseURL == "" {
+				cfg.APIKey = settings.OpenAIApiKey
+			}
+		}
+	}
+	return cfg
+}
+
+// localTTSConfig 服务端配置的本地引擎
+func localTTSConfig() tts.LocalConfig {
+	if config.AppCfg == nil {
+		return tts.LocalConfig{}
+	}
+	return config.AppCfg.TTS.Local
+}
+
+func toModelItem(m tts.Model) ModelItem {
+	return ModelItem{
+		ID:          m.ID,
+		Name:        m.Name,
+		Description: m.Description,
+		VoiceCount:  m.VoiceCount,
+		Languages:   m.Languages,
+		MaxChars:    m.MaxChars,
+	}
+}
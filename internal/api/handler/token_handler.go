@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"copycat/internal/core/auth"
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTokenExpireDays 个人访问令牌有效期上限（天）
+const maxTokenExpireDays = 365
+
+// TokenHandler 个人访问令牌处理器
+type TokenHandler struct {
+	tokenRepo      repository.PersonalAccessTokenRepository
+	personalTokens *auth.PersonalTokenService
+}
+
+// NewTokenHandler 创建个人访问令牌处理器
+func NewTokenHandler(tokenRepo repository.PersonalAccessTokenRepository, personalTokens *auth.PersonalTokenService) *TokenHandler {
+	return &TokenHandler{tokenRepo: tokenRepo, personalTokens: personalTokens}
+}
+
+// CreateTokenRequest 创建个人访问令牌请求
+type CreateTokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days"` // 有效期（天），0 表示永不过期
+}
+
+// CreateTokenResponse 创建个人访问令牌响应（明文令牌只返回这一次）
+type CreateTokenResponse struct {
+	*model.PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// List 获取当前用户的个人访问令牌
+// @Summary 获取个人访问令牌列表
+// @Tags Token
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.PersonalAccessToken}
+// @Router /tokens [get]
+func (h *TokenHandler) List(c *gin.Context) {
+	tokens, err := h.tokenRepo.ListByUserID(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		log.Printf("[API] 查询个人访问令牌失败: %v", err)
+		response.ServerError(c, "查询令牌失败")
+		return
+	}
+	response.Success(c, tokens)
+}
+
+// Create 创建个人访问令牌
+// @Summary 创建个人访问令牌
+// @Tags Token
+// @Security BearerAuth
+// @Param request body CreateTokenRequest true "令牌信息"
+// @Success 200 {object} response.Response{data=CreateTokenResponse}
+// @Router /tokens [post]
+func (h *TokenHandler) Create(c *gin.Context) {
+	var req CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		response.BadRequest(c, "令牌名称不能为空")
+		return
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !model.ValidScope(scope) {
+			response.BadRequest(c, "无效的权限范围: "+scope+"，可选: "+strings.Join(model.AllScopes, ", "))
+			return
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxTokenExpireDays {
+		response.BadRequest(c, "有效期需在 0-"+strconv.Itoa(maxTokenExpireDays)+" 天之间")
+		return
+	}
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	token, plain, err := h.personalTokens.Create(c.Request.Context(), c.GetInt64("userID"), name, scopes, expiresAt)
+	if err != nil {
+		log.Printf("[API] 创建个人访问令牌失败: %v", err)
+		response.ServerError(c, "创建令牌失败")
+		return
+	}
+	entry := auditEntry(c)
+	entry.SetTarget("token", token.ID)
+	entry.AddDetail("name", token.Name)
+	entry.AddDetail("scopes", token.Scopes)
+	response.SuccessWithMessage(c, "令牌只显示这一次，请妥善保存", CreateTokenResponse{PersonalAccessToken: token, Token: plain})
+}
+
+// Revoke 吊销个人访问令牌
+// @Summary 吊销个人访问令牌
+// @Tags Token
+// @Security BearerAuth
+// @Param id path int true "令牌ID"
+// @Success 200 {object} response.Response
+// @Router /tokens/{id} [delete]
+func (h *TokenHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的令牌ID")
+		return
+	}
+
+	revoked, err := h.tokenRepo.Revoke(c.Request.Context(), c.GetInt64("userID"), id)
+	if err != nil {
+		log.Printf("[API] 吊销个人访问令牌失败: %v", err)
+		response.ServerError(c, "吊销令牌失败")
+		return
+	}
+	if !revoked {
+		response.NotFound(c, "令牌不存在或已吊销")
+		return
+	}
+	response.SuccessWithMessage(c, "吊销成功", nil)
+}
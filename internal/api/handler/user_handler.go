@@ -2,28 +2,31 @@ package handler
 
 import (
 	"errors"
+	"log"
 
 	"copycat/config"
+	"copycat/internal/core/auth"
 	"copycat/internal/model"
 	"copycat/internal/repository"
 	"copycat/pkg/response"
 
-	"time"
-
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // UserHandler 用户处理器
 type UserHandler struct {
-	userRepo repository.UserRepository
+	userRepo      repository.UserRepository
+	workspaceRepo repository.WorkspaceRepository
+	tokens        *auth.TokenService
+	account       *auth.AccountService
+	guard         *auth.LoginGuard
 }
 
 // NewUserHandler 创建用户处理器
-func NewUserHandler(userRepo repository.UserRepository) *UserHandler {
-	return &UserHandler{userRepo: userRepo}
+func NewUserHandler(userRepo repository.UserRepository, workspaceRepo repository.WorkspaceRepository, tokens *auth.TokenService, account *auth.AccountService, guard *auth.LoginGuard) *UserHandler {
+	return &UserHandler{userRepo: userRepo, workspaceRepo: workspaceRepo, tokens: tokens, account: account, guard: guard}
 }
 
 // RegisterRequest 注册请求
@@ -39,10 +42,21 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// LoginResponse 登录响应
+// LoginResponse 登录响应（token 为短期访问令牌，过期后用 refresh_token 换取新令牌）
 type LoginResponse struct {
-	Token string      `json:"token"`
-	User  *model.User `json:"user"`
+	auth.TokenPair
+	User *model.User `json:"user"`
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest 退出登录请求
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"` // 当前会话的刷新令牌
+	All          bool   `json:"all"`           // 退出全部设备（已签发的访问令牌立即失效）
 }
 
 // Register 用户注册
@@ -60,7 +74,8 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// 检查邮箱是否已存在
+	// 邮箱统一小写保存，检查是否已存在（不区分大小写）
+	req.Email = auth.NormalizeEmail(req.Email)
 	_, err := h.userRepo.GetByEmail(c.Request.Context(), req.Email)
 	if err == nil {
 		response.BadRequest(c, "email already exists")
@@ -89,9 +104,23 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	response.SuccessWithMessage(c, "user registered successfully", nil)
+	// 每个用户都有一个个人工作区
+	if _, err := h.workspaceRepo.EnsurePersonal(c.Request.Context(), user); err != nil {
+		response.ServerError(c, "failed to create personal workspace")
+		return
+	}
+
+	// 发送验证邮件失败不影响注册，用户可稍后重新发送
+	if err := h.account.SendVerification(c.Request.Context(), user); err != nil {
+		log.Printf("[API] 发送验证邮件失败: user=%d err=%v", user.ID, err)
+	}
+
+	response.SuccessWithMessage(c, "user registered successfully, please check your email to verify your account", nil)
 }
 
+// dummyPasswordHash 邮箱未注册时用于比对的密码哈希，使登录耗时与已注册账号一致
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("copycat-dummy-password"), bcrypt.DefaultCost)
+
 // Login 用户登录
 // @Summary 用户登录
 // @Tags User
@@ -107,36 +136,149 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	ctx := c.Request.Context()
+	req.Email = auth.NormalizeEmail(req.Email)
+	attempt := auth.LoginAttemptInfo{Email: req.Email, IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+
+	// 连续失败过多时要求等待或临时锁定；允许尝试时本次先计为失败，登录成功后退还
+	wait, err := h.guard.Reserve(ctx, attempt)
+	if err != nil {
+		response.ServerError(c, "failed to check login throttle")
+		return
+	}
+	if wait > 0 {
+		h.recordLogin(h.guard.Record(ctx, attempt, model.LoginResultThrottled))
+		response.TooManyRequests(c, "too many failed login attempts, please try again later", wait)
+		return
+	}
+
 	// 查找用户
-	user, err := h.userRepo.GetByEmail(c.Request.Context(), req.Email)
+	user, err := h.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 与已注册账号一样做一次密码比对，避免通过响应时间判断邮箱是否注册
+			bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+			h.recordLogin(h.guard.Fail(ctx, attempt, model.LoginResultUnknownUser))
 			response.Unauthorized(c, "invalid email or password")
 			return
 		}
 		response.ServerError(c, "failed to get user")
 		return
 	}
+	attempt.UserID = user.ID
 
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		h.recordLogin(h.guard.Fail(ctx, attempt, model.LoginResultBadPassword))
 		response.Unauthorized(c, "invalid email or password")
 		return
 	}
 
-	// 生成 JWT token
-	token, err := generateToken(user.ID)
+	// 被管理员禁用的账号不能登录
+	if user.Disabled {
+		h.recordLogin(h.guard.Release(ctx, attempt, model.LoginResultDisabled))
+		response.Forbidden(c, "account has been disabled")
+		return
+	}
+
+	// 未验证邮箱时补发验证邮件（有频率限制），否则用户无法登录也就无法重新发送
+	if config.AppCfg.Mail.RequireVerification && !user.EmailVerified {
+		h.recordLogin(h.guard.Release(ctx, attempt, model.LoginResultNotVerified))
+		if err := h.account.SendVerification(ctx, user); err != nil && !errors.Is(err, auth.ErrTooFrequent) {
+			log.Printf("[API] 发送验证邮件失败: user=%d err=%v", user.ID, err)
+		}
+		response.Forbidden(c, "email not verified, please check your email for the verification link")
+		return
+	}
+	h.recordLogin(h.guard.Succeed(ctx, attempt))
+
+	// 签发访问令牌和刷新令牌
+	pair, err := h.tokens.Issue(ctx, user, clientInfo(c))
 	if err != nil {
 		response.ServerError(c, "failed to generate token")
 		return
 	}
 
 	response.Success(c, LoginResponse{
-		Token: token,
-		User:  user,
+		TokenPair: *pair,
+		User:      user,
 	})
 }
 
+// RefreshToken 用刷新令牌换取新令牌，旧刷新令牌随即作废
+// @Summary 刷新令牌
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body RefreshTokenRequest true "刷新令牌"
+// @Success 200 {object} response.Response{data=auth.TokenPair}
+// @Router /api/v1/token/refresh [post]
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request: "+err.Error())
+		return
+	}
+
+	pair, err := h.tokens.Refresh(c.Request.Context(), req.RefreshToken, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrTokenReused):
+			response.Unauthorized(c, "refresh token has been revoked, please login again")
+		case errors.Is(err, auth.ErrInvalidToken):
+			response.Unauthorized(c, "invalid or expired refresh token")
+		case errors.Is(err, auth.ErrAccountDisabled):
+			response.Forbidden(c, "account has been disabled")
+		default:
+			response.ServerError(c, "failed to refresh token")
+		}
+		return
+	}
+
+	response.Success(c, pair)
+}
+
+// Logout 退出登录：作废当前会话的刷新令牌，all 为 true 时退出全部设备
+// @Summary 退出登录
+// @Tags User
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body LogoutRequest false "退出选项"
+// @Success 200 {object} response.Response
+// @Router /api/v1/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "invalid request: "+err.Error())
+			return
+		}
+	}
+
+	userID := c.GetInt64("userID")
+	entry := auditEntry(c)
+	if req.All {
+		if err := h.tokens.RevokeAll(c.Request.Context(), userID); err != nil {
+			response.ServerError(c, "failed to logout")
+			return
+		}
+		entry.SetTarget("user", userID)
+		response.SuccessWithMessage(c, "logged out from all devices", nil)
+		return
+	}
+
+	// 只退出当前会话不记录审计日志
+	entry.Discard()
+	if req.RefreshToken != "" {
+		if err := h.tokens.Revoke(c.Request.Context(), userID, req.RefreshToken); err != nil {
+			response.ServerError(c, "failed to logout")
+			return
+		}
+	}
+	response.SuccessWithMessage(c, "logged out", nil)
+}
+
 // GetProfile 获取当前用户信息
 // @Summary 获取当前用户信息
 // @Tags User
@@ -160,16 +302,16 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 	response.Success(c, user)
 }
 
-// generateToken 生成 JWT Token
-func generateToken(userID int64) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * time.Duration(config.AppCfg.JWT.ExpireHours)).Unix(),
-		"iat":     time.Now().Unix(),
+// recordLogin 登录记录写入失败只打日志，不影响登录结果
+func (h *UserHandler) recordLogin(err error) {
+	if err != nil {
+		log.Printf("[API] 记录登录结果失败: %v", err)
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.AppCfg.JWT.Secret))
+// clientInfo 签发刷新令牌时记录的客户端信息
+func clientInfo(c *gin.Context) auth.ClientInfo {
+	return auth.ClientInfo{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
 }
 
 // UpdateProfileRequest 更新资料请求
@@ -208,6 +350,7 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	}
 
 	// 更新字段
+	before := *user
 	if req.Nickname != "" {
 		user.Nickname = req.Nickname
 	}
@@ -218,11 +361,14 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		user.Bio = req.Bio
 	}
 
-	if err := h.userRepo.Update(c.Request.Context(), user); err != nil {
+	if err := h.userRepo.UpdateProfile(c.Request.Context(), user); err != nil {
 		response.ServerError(c, "更新失败")
 		return
 	}
 
+	entry := auditEntry(c)
+	entry.SetTarget("user", user.ID)
+	entry.SetChanges(&before, user)
 	response.SuccessWithMessage(c, "资料更新成功", user)
 }
 
@@ -273,11 +419,148 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// 修改密码后其他会话全部失效，当前会话换发新令牌
 	user.Password = string(hashedPassword)
 	if err := h.userRepo.Update(c.Request.Context(), user); err != nil {
 		response.ServerError(c, "密码更新失败")
 		return
 	}
+	if err := h.tokens.RevokeAll(c.Request.Context(), user.ID); err != nil {
+		response.ServerError(c, "吊销旧令牌失败")
+		return
+	}
+	user.TokenVersion++ // 与 RevokeAll 递增后的版本保持一致
+	auditEntry(c).SetTarget("user", user.ID)
+
+	pair, err := h.tokens.Issue(c.Request.Context(), user, clientInfo(c))
+	if err != nil {
+		response.ServerError(c, "签发新令牌失败")
+		return
+	}
+
+	response.SuccessWithMessage(c, "密码修改成功", pair)
+}
+
+// VerifyEmailRequest 邮箱验证请求
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPasswordRequest 找回密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// VerifyEmail 使用邮件中的令牌验证邮箱
+// @Summary 验证邮箱
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "验证令牌"
+// @Success 200 {object} response.Response
+// @Router /api/v1/email/verify [post]
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if _, err := h.account.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			response.BadRequest(c, "验证链接无效或已过期")
+			return
+		}
+		response.ServerError(c, "邮箱验证失败")
+		return
+	}
+	response.SuccessWithMessage(c, "邮箱验证成功", nil)
+}
+
+// ResendVerification 重新发送验证邮件
+// @Summary 重新发送验证邮件
+// @Tags User
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /api/v1/email/verify/resend [post]
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	user, err := h.userRepo.GetByID(c.Request.Context(), c.GetInt64("userID"))
+	if err != nil {
+		response.NotFound(c, "用户不存在")
+		return
+	}
+	if user.EmailVerified {
+		response.BadRequest(c, "邮箱已验证")
+		return
+	}
+
+	if err := h.account.SendVerification(c.Request.Context(), user); err != nil {
+		if errors.Is(err, auth.ErrTooFrequent) {
+			response.BadRequest(c, "发送过于频繁，请稍后再试")
+			return
+		}
+		log.Printf("[API] 发送验证邮件失败: user=%d err=%v", user.ID, err)
+		response.ServerError(c, "发送验证邮件失败")
+		return
+	}
+	response.SuccessWithMessage(c, "验证邮件已发送", nil)
+}
+
+// ForgotPassword 发送重置密码邮件（无论邮箱是否注册都返回成功）
+// @Summary 找回密码
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "注册邮箱"
+// @Success 200 {object} response.Response
+// @Router /api/v1/password/forgot [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	// 邮件在后台发送，发送失败只记录日志，响应与邮箱是否注册无关
+	h.account.RequestPasswordReset(auth.NormalizeEmail(req.Email))
+	response.SuccessWithMessage(c, "如果该邮箱已注册，你将收到一封重置密码邮件", nil)
+}
+
+// ResetPassword 使用邮件中的令牌设置新密码，所有已登录的会话随即失效
+// @Summary 重置密码
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "重置信息"
+// @Success 200 {object} response.Response
+// @Router /api/v1/password/reset [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.account.ResetPassword(c.Request.Context(), req.Token, req.NewPassword)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			response.BadRequest(c, "重置链接无效或已过期")
+			return
+		}
+		response.ServerError(c, "重置密码失败")
+		return
+	}
 
-	response.SuccessWithMessage(c, "密码修改成功", nil)
+	// 公开接口，审计操作人为重置密码的用户
+	entry := auditEntry(c)
+	entry.SetActor(user.ID)
+	entry.SetTarget("user", user.ID)
+	response.SuccessWithMessage(c, "密码已重置，请重新登录", nil)
 }
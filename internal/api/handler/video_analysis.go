@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"copycat/config"
+	"copycat/internal/core/llm"
+	"copycat/internal/core/media"
+	"copycat/internal/core/video"
+	"copycat/internal/model"
+	"copycat/pkg/logger"
+)
+
+// videoSampler 按服务端配置创建视频抽帧器
+func videoSampler() *video.Sampler {
+	if config.AppCfg == nil {
+		return video.NewSampler(video.Config{})
+	}
+	return video.NewSampler(config.AppCfg.Video)
+}
+
+// sampleVideoFrames 抽取视频关键帧并存入媒体缓存，返回代理地址和时间点
+func sampleVideoFrames(ctx context.Context, cache *media.Cache, videoURL string, duration int) ([]llm.VideoFrame, error) {
+	frames, err := videoSampler().Sample(ctx, videoURL, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]llm.VideoFrame, 0, len(frames))
+	for _, f := range frames {
+		// 用媒体片段标记区分同一视频的不同帧
+		obj, err := cache.Save(ctx, fmt.Sprintf("%s#t=%.1f", videoURL, f.Timestamp), f.Data)
+		if err != nil {
+			logger.Warn("保存视频关键帧失败: %.1fs - %v", f.Timestamp, err)
+			continue
+		}
+		result = append(result, llm.VideoFrame{Timestamp: f.Timestamp, URL: media.ProxyURL(obj.Hash)})
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("保存视频关键帧失败")
+	}
+	return result, nil
+}
+
+// analyzeVideo 视频内容分析：配置了视频 LLM 且能抽取到关键帧时结合画面分析，否则退回纯文本分析
+func analyzeVideo(ctx context.Context, settings *model.UserSettings, textClient *llm.Client, cache *media.Cache, title, content, videoURL string, duration int) (*llm.AnalysisResult, error) {
+	switch {
+	case videoURL == "":
+		logger.LLMInfo("[Video] 没有视频地址，使用文本分析")
+	case settings.VideoLLMApiKey == "":
+		logger.LLMInfo("[Video] 未配置视频 LLM，使用文本分析")
+	case !videoSampler().Available():
+		logger.LLMInfo("[Video] 服务端未安装 ffmpeg，使用文本分析")
+	default:
+		frames, err := sampleVideoFrames(ctx, cache, videoURL, duration)
+		if err != nil {
+			logger.LLMError("[Video] 抽取关键帧失败，改用文本分析: %v", err)
+			break
+		}
+
+		videoClient := llm.NewClient(llm.Config{
+			Provider:    settings.VideoLLMProvider,
+			ApiKey:      settings.VideoLLMApiKey,
+			Model:       settings.VideoLLMModel,
+			BaseURL:     settings.VideoLLMBaseURL,
+			ImageLoader: cache.Loader(ctx),
+		})
+		result, err := videoClient.AnalyzeVideoWithFrames(title, content, frames)
+		if err == nil {
+			return result, nil
+		}
+		logger.LLMError("[Video] 关键帧分析失败，改用文本分析: %v", err)
+	}
+	return textClient.AnalyzeVideoContent(title, content)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"copycat/internal/core/imagegen"
+	"copycat/internal/core/policy"
+	"copycat/internal/core/tts"
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// respondAuthzError 将授权错误写入响应：非成员或角色不足返回 403，资源不存在返回 404
+func respondAuthzError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, policy.ErrNotMember):
+		response.Forbidden(c, "无权访问该工作区的资源")
+	case errors.Is(err, policy.ErrForbidden):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.NotFound(c, "资源不存在")
+	default:
+		response.ServerError(c, "权限校验失败")
+	}
+}
+
+// requireWorkspaceAction 检查当前工作区（WorkspaceMiddleware 解析）的角色能否执行操作，失败时已写入响应
+func requireWorkspaceAction(c *gin.Context, action policy.Action) bool {
+	if !policy.Allowed(c.GetString("workspaceRole"), action) {
+		response.Forbidden(c, policy.ErrForbidden.Error())
+		return false
+	}
+	return true
+}
+
+// resolveSettings 获取用户在工作区内实际使用的模型设置：服务商和模型按用户自己的设置（没有时使用系统默认值），
+// 工作区配置了共享 API Key 时覆盖对应密钥。用户和工作区都没有设置时返回 gorm.ErrRecordNotFound
+func resolveSettings(ctx context.Context, settingsRepo *repository.UserSettingsRepository, workspaceRepo repository.WorkspaceRepository, systemRepo repository.SystemSettingsRepository, userID, workspaceID int64) (*model.UserSettings, error) {
+	settings, err := settingsRepo.GetByUserID(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	shared, sharedErr := workspaceRepo.GetSettings(ctx, workspaceID)
+	if sharedErr != nil {
+		if !errors.Is(sharedErr, gorm.ErrRecordNotFound) {
+			return nil, sharedErr
+		}
+		return settings, err
+	}
+
+	if settings == nil {
+		system, err := systemRepo.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		settings = defaultUserSettings(userID, system)
+	}
+	applySharedKeys(settings, shared)
+	return settings, nil
+}
+
+// defaultUserSettings 没有设置记录时的默认值（服务商、模型和生成条数取管理员配置的系统默认值）
+func defaultUserSettings(userID int64, system *model.SystemSettings) *model.UserSettings {
+	return &model.UserSettings{
+		UserID:               userID,
+		LLMProvider:          system.DefaultLLMProvider,
+		LLMModel:             system.DefaultLLMModel,
+		ImageLLMProvider:     system.DefaultLLMProvider,
+		ImageLLMModel:        system.DefaultImageLLMModel,
+		VideoLLMProvider:     system.DefaultLLMProvider,
+		VideoLLMModel:        system.DefaultVideoLLMModel,
+		TTSProvider:          tts.ProviderDashScope,
+		ImageGenProvider:     imagegen.ProviderDashScope,
+		GenerateCount:        system.DefaultGenerateCount,
+		OriginalityThreshold: model.DefaultOriginalityThreshold,
+	}
+}
+
+// applySharedKeys 用工作区共享的 API Key 覆盖用户设置，并刷新各分析类型当前使用的密钥。
+// 使用共享密钥时接口地址固定为服务商官方地址，不沿用成员自定义的 Base URL，避免密钥被发往成员控制的服务器
+func applySharedKeys(settings *model.UserSettings, shared *model.WorkspaceSettings) {
+	overrides := []struct {
+		dst *string
+		src string
+	}{
+		{&settings.OpenAIApiKey, shared.OpenAIApiKey},
+		{&settings.DeepSeekApiKey, shared.DeepSeekApiKey},
+		{&settings.MoonshotApiKey, shared.MoonshotApiKey},
+		{&settings.QwenApiKey, shared.QwenApiKey},
+		{&settings.HunyuanApiKey, shared.HunyuanApiKey},
+		{&settings.DoubaoApiKey, shared.DoubaoApiKey},
+		{&settings.ZhipuApiKey, shared.ZhipuApiKey},
+		{&settings.AnthropicApiKey, shared.AnthropicApiKey},
+		{&settings.TTSApiKey, shared.TTSApiKey},
+		{&settings.ImageGenApiKey, shared.ImageGenApiKey},
+	}
+	for _, o := range overrides {
+		if o.src != "" {
+			*o.dst = o.src
+		}
+	}
+
+	llms := []struct {
+		provider string
+		key      *string
+		baseURL  *string
+	}{
+		{settings.LLMProvider, &settings.LLMApiKey, &settings.LLMBaseURL},
+		{settings.ImageLLMProvider, &settings.ImageLLMApiKey, &settings.ImageLLMBaseURL},
+		{settings.VideoLLMProvider, &settings.VideoLLMApiKey, &settings.VideoLLMBaseURL},
+	}
+	for _, l := range llms {
+		if key := sharedProviderApiKey(shared, l.provider); key != "" {
+			*l.key = key
+			*l.baseURL = getProviderBaseURL(l.provider)
+		} else if key := getProviderApiKey(settings, l.provider); key != "" {
+			*l.key = key
+		}
+	}
+
+	// 语音合成和图像生成的 Base URL 为空时使用服务商官方地址
+	if shared.TTSApiKey != "" {
+		settings.TTSBaseURL = ""
+	}
+	if shared.ImageGenApiKey != "" {
+		settings.ImageGenBaseURL = ""
+	}
+}
+
+// sharedProviderApiKey 工作区共享的指定服务商 API Key
+func sharedProviderApiKey(shared *model.WorkspaceSettings, provider string) string {
+	return getProviderApiKeyByName(&model.UserSettings{
+		OpenAIApiKey:    shared.OpenAIApiKey,
+		DeepSeekApiKey:  shared.DeepSeekApiKey,
+		MoonshotApiKey:  shared.MoonshotApiKey,
+		QwenApiKey:      shared.QwenApiKey,
+		HunyuanApiKey:   shared.HunyuanApiKey,
+		DoubaoApiKey:    shared.DoubaoApiKey,
+		ZhipuApiKey:     shared.ZhipuApiKey,
+		AnthropicApiKey: shared.AnthropicApiKey,
+	}, provider)
+}
@@ -0,0 +1,513 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"copycat/internal/core/policy"
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WorkspaceHandler 工作区处理器
+type WorkspaceHandler struct {
+	workspaceRepo repository.WorkspaceRepository
+	userRepo      repository.UserRepository
+	projectRepo   repository.ProjectRepository
+	authorizer    *policy.Authorizer
+}
+
+// NewWorkspaceHandler 创建工作区处理器
+func NewWorkspaceHandler(workspaceRepo repository.WorkspaceRepository, userRepo repository.UserRepository, projectRepo repository.ProjectRepository, authorizer *policy.Authorizer) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		projectRepo:   projectRepo,
+		authorizer:    authorizer,
+	}
+}
+
+// WorkspaceRequest 创建/重命名工作区请求
+type WorkspaceRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// WorkspaceItem 工作区及当前用户的角色
+type WorkspaceItem struct {
+	*model.Workspace
+	Role string `json:"role"`
+}
+
+// WorkspaceDetail 工作区详情
+type WorkspaceDetail struct {
+	WorkspaceItem
+	Members []*model.WorkspaceMember `json:"members"`
+}
+
+// AddMemberRequest 添加成员请求（按邮箱添加已注册用户）
+type AddMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+// UpdateMemberRequest 修改成员角色请求
+type UpdateMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// WorkspaceSettingsPayload 工作区共享 API Key（响应时脱敏，保存时脱敏值表示保持不变，空字符串表示清除）
+type WorkspaceSettingsPayload struct {
+	ProviderKeys   ProviderApiKeys `json:"provider_keys"`
+	TTSApiKey      string          `json:"tts_api_key"`
+	ImageGenApiKey string          `json:"image_gen_api_key"`
+}
+
+// List 获取当前用户加入的工作区
+// @Summary 获取工作区列表
+// @Tags Workspace
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]WorkspaceItem}
+// @Router /workspaces [get]
+func (h *WorkspaceHandler) List(c *gin.Context) {
+	userID := c.GetInt64("userID")
+	workspaces, err := h.workspaceRepo.ListByUserID(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[API] 查询工作区失败: %v", err)
+		response.ServerError(c, "查询工作区失败")
+		return
+	}
+
+	items := make([]WorkspaceItem, 0, len(workspaces))
+	for _, w := range workspaces {
+		role, err := h.authorizer.Role(c.Request.Context(), userID, w.ID)
+		if err != nil {
+			continue
+		}
+		items = append(items, WorkspaceItem{Workspace: w, Role: role})
+	}
+	response.Success(c, items)
+}
+
+// Create 创建团队工作区（创建者为所有者）
+// @Summary 创建工作区
+// @Tags Workspace
+// @Security BearerAuth
+// @Param request body WorkspaceRequest true "工作区信息"
+// @Success 200 {object} response.Response{data=WorkspaceItem}
+// @Router /workspaces [post]
+func (h *WorkspaceHandler) Create(c *gin.Context) {
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		response.BadRequest(c, "工作区名称不能为空")
+		return
+	}
+
+	workspace := &model.Workspace{Name: name, OwnerID: c.GetInt64("userID")}
+	if err := h.workspaceRepo.Create(c.Request.Context(), workspace); err != nil {
+		log.Printf("[API] 创建工作区失败: %v", err)
+		response.ServerError(c, "创建工作区失败")
+		return
+	}
+	entry := auditEntry(c)
+	entry.SetTarget("workspace", workspace.ID)
+	entry.SetWorkspace(workspace.ID)
+	entry.AddDetail("name", workspace.Name)
+	response.Success(c, WorkspaceItem{Workspace: workspace, Role: model.WorkspaceRoleOwner})
+}
+
+// Get 获取工作区详情和成员列表
+// @Summary 获取工作区详情
+// @Tags Workspace
+// @Security BearerAuth
+// @Param id path int true "工作区ID"
+// @Success 200 {object} response.Response{data=WorkspaceDetail}
+// @Router /workspaces/{id} [get]
+func (h *WorkspaceHandler) Get(c *gin.Context) {
+	workspace, role, ok := h.loadWorkspace(c, policy.ActionView)
+	if !ok {
+		return
+	}
+
+	members, err := h.workspaceRepo.ListMembers(c.Request.Context(), workspace.ID)
+	if err != nil {
+		log.Printf("[API] 查询工作区成员失败: %v", err)
+		response.ServerError(c, "查询成员失败")
+		return
+	}
+	response.Success(c, WorkspaceDetail{
+		WorkspaceItem: WorkspaceItem{Workspace: workspace, Role: role},
+		Members:       members,
+	})
+}
+
+// Update 重命名工作区
+// @Summary 重命名工作区
+// @Tags Workspace
+// @Security BearerAuth
+// @Param id path int true "工作区ID"
+// @Param request body WorkspaceRequest true "工作区信息"
+// @Success 200 {object} response.Response{data=model.Workspace}
+// @Router /workspaces/{id} [put]
+func (h *WorkspaceHandler) Update(c *gin.Context) {
+	workspace, _, ok := h.loadWorkspace(c, policy.ActionManageWorkspace)
+	if !ok {
+		return
+	}
+
+	var req WorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		response.BadRequest(c, "工作区名称不能为空")
+		return
+	}
+
+	before := *workspace
+	workspace.Name = name
+	if err := h.workspaceRepo.Update(c.Request.Context(), workspace); err != nil {
+		log.Printf("[API] 更新工作区失败: %v", err)
+		response.ServerError(c, "更新工作区失败")
+		return
+	}
+	auditEntry(c).SetChanges(&before, workspace)
+	response.Success(c, workspace)
+}
+
+// Delete 删除工作区（个人工作区不可删除，工作区内还有项目时需先删除项目）
+// @Summary 删除工作区
+// @Tags Workspace
+// @Security BearerAuth
+// @Param id path int true "工作区ID"
+// @Success 200 {object} response.Response
+// @Router /workspaces/{id} [delete]
+func (h *WorkspaceHandler) Delete(c *gin.Context) {
+	workspace, _, ok := h.loadWorkspace(c, policy.ActionManageWorkspace)
+	if !ok {
+		return
+	}
+	if workspace.Personal {
+		response.BadRequest(c, "个人工作区不能删除")
+		return
+	}
+
+	count, err := h.projectRepo.CountByWorkspaceID(c.Request.Context(), workspace.ID)
+	if err != nil {
+		response.ServerError(c, "查询工作区项目失败")
+		return
+	}
+	if count > 0 {
+		response.BadRequest(c, "工作区内还有项目，请先删除项目")
+		return
+	}
+
+	if err := h.workspaceRepo.Delete(c.Request.Context(), workspace.ID); err != nil {
+		log.Printf("[API] 删除工作区失败: %v", err)
+		response.ServerError(c, "删除工作区失败")
+		return
+	}
+	auditEntry(c).AddDetail("name", workspace.Name)
+	response.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// AddMember 按邮箱添加成员
+// @Summary 添加工作区成员
+// @Tags Workspace
+// @Security BearerAuth
+// @Param id path int true "工作区ID"
+// @Param request body AddMemberRequest true "成员信息"
+// @Success 200 {object} response.Response{data=model.WorkspaceMember}
+// @Router /workspaces/{id}/members [post]
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+	workspace, _, ok := h.loadWorkspace(c, policy.ActionManageMembers)
+	if !ok {
+		return
+	}
+	if workspace.Personal {
+		response.BadRequest(c, "个人工作区不能添加成员，请创建团队工作区")
+		return
+	}
+
+	var req AddMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if !policy.ValidRole(req.Role) {
+		response.BadRequest(c, "无效的角色: "+req.Role)
+		return
+	}
+
+	user, err := h.userRepo.GetByEmail(c.Request.Context(), req.Email)
+	if err != nil {
+		response.NotFound(c, "该邮箱尚未注册")
+		return
+	}
+	if _, err := h.workspaceRepo.GetMember(c.Request.Context(), workspace.ID, user.ID); err == nil {
+		response.BadRequest(c, "该用户已是工作区成员")
+		return
+	}
+
+	member := &model.WorkspaceMember{WorkspaceID: workspace.ID, UserID: user.ID, Role: req.Role}
+	if err := h.workspaceRepo.AddMember(c.Request.Context(), member); err != nil {
+		log.Printf("[API] 添加工作区成员失败: %v", err)
+		response.ServerError(c, "添加成员失败")
+		return
+	}
+	member.User = user
+	entry := auditEntry(c)
+	entry.AddDetail("user_id", user.ID)
+	entry.AddDetail("role", req.Role)
+	response.Success(c, member)
+}
+
+// UpdateMember 修改成员角色
+// @Summary 修改工作区成员角色
+// @Tags Workspace
+// @Security BearerAuth
+// @Param id path int true "工作区ID"
+// @Param user_id path int true "成员用户ID"
+// @Param request body UpdateMemberRequest true "角色"
+// @Success 200 {object} response.Response
+// @Router /workspaces/{id}/members/{user_id} [put]
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	workspace, _, ok := h.loadWorkspace(c, policy.ActionManageMembers)
+	if !ok {
+		return
+	}
+	member, ok := h.loadMember(c, workspace.ID)
+	if !ok {
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+	if !policy.ValidRole(req.Role) {
+		response.BadRequest(c, "无效的角色: "+req.Role)
+		return
+	}
+	if member.Role == model.WorkspaceRoleOwner && req.Role != model.WorkspaceRoleOwner && !h.hasOtherOwner(c, workspace.ID) {
+		return
+	}
+
+	if err := h.workspaceRepo.UpdateMemberRole(c.Request.Context(), workspace.ID, member.UserID, req.Role); err != nil {
+		log.Printf("[API] 修改成员角色失败: %v", err)
+		response.ServerError(c, "修改角色失败")
+		return
+	}
+	entry := auditEntry(c)
+	entry.AddDetail("user_id", member.UserID)
+	entry.SetChanges(map[string]string{"role": member.Role}, map[string]string{"role": req.Role})
+	response.SuccessWithMessage(c, "修改成功", nil)
+}
+
+// RemoveMember 移除成员（成员也可以移除自己以退出工作区）
+// @Summary 移除工作区成员
+// @Tags Workspace
+// @Security BearerAuth
+// @Param id path int true "工作区ID"
+// @Param user_id path int true "成员用户ID"
+// @Success 200 {object} response.Response
+// @Router /workspaces/{id}/members/{user_id} [delete]
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	userID := c.GetInt64("userID")
+	targetID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的用户ID")
+		return
+	}
+
+	action := policy.ActionManageMembers
+	if targetID == userID {
+		action = policy.ActionView
+	}
+	workspace, _, ok := h.loadWorkspace(c, action)
+	if !ok {
+		return
+	}
+	if workspace.Personal {
+		response.BadRequest(c, "不能退出个人工作区")
+		return
+	}
+	member, ok := h.loadMember(c, workspace.ID)
+	if !ok {
+		return
+	}
+	if member.Role == model.WorkspaceRoleOwner && !h.hasOtherOwner(c, workspace.ID) {
+		return
+	}
+
+	if err := h.workspaceRepo.RemoveMember(c.Request.Context(), workspace.ID, member.UserID); err != nil {
+		log.Printf("[API] 移除工作区成员失败: %v", err)
+		response.ServerError(c, "移除成员失败")
+		return
+	}
+	entry := auditEntry(c)
+	entry.AddDetail("user_id", member.UserID)
+	entry.AddDetail("role", member.Role)
+	response.SuccessWithMessage(c, "移除成功", nil)
+}
+
+// GetSettings 获取工作区共享 API Key（脱敏）
+// @Summary 获取工作区共享密钥
+// @Tags Workspace
+// @Security BearerAuth
+// @Param id path int true "工作区ID"
+// @Success 200 {object} response.Response{data=WorkspaceSettingsPayload}
+// @Router /workspaces/{id}/settings [get]
+func (h *WorkspaceHandler) GetSettings(c *gin.Context) {
+	workspace, _, ok := h.loadWorkspace(c, policy.ActionManageSettings)
+	if !ok {
+		return
+	}
+
+	settings, err := h.workspaceRepo.GetSettings(c.Request.Context(), workspace.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			response.ServerError(c, "获取工作区设置失败")
+			return
+		}
+		settings = &model.WorkspaceSettings{WorkspaceID: workspace.ID}
+	}
+
+	response.Success(c, WorkspaceSettingsPayload{
+		ProviderKeys: ProviderApiKeys{
+			OpenAI:    maskApiKey(settings.OpenAIApiKey),
+			DeepSeek:  maskApiKey(settings.DeepSeekApiKey),
+			Moonshot:  maskApiKey(settings.MoonshotApiKey),
+			Qwen:      maskApiKey(settings.QwenApiKey),
+			Hunyuan:   maskApiKey(settings.HunyuanApiKey),
+			Doubao:    maskApiKey(settings.DoubaoApiKey),
+			Zhipu:     maskApiKey(settings.ZhipuApiKey),
+			Anthropic: maskApiKey(settings.AnthropicApiKey),
+		},
+		TTSApiKey:      maskApiKey(settings.TTSApiKey),
+		ImageGenApiKey: maskApiKey(settings.ImageGenApiKey),
+	})
+}
+
+// SaveSettings 保存工作区共享 API Key，成员在该工作区内调用模型时优先使用
+// @Summary 保存工作区共享密钥
+// @Tags Workspace
+// @Security BearerAuth
+// @Param id path int true "工作区ID"
+// @Param request body WorkspaceSettingsPayload true "共享密钥"
+// @Success 200 {object} response.Response
+// @Router /workspaces/{id}/settings [put]
+func (h *WorkspaceHandler) SaveSettings(c *gin.Context) {
+	workspace, _, ok := h.loadWorkspace(c, policy.ActionManageSettings)
+	if !ok {
+		return
+	}
+
+	var req WorkspaceSettingsPayload
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	existing, err := h.workspaceRepo.GetSettings(c.Request.Context(), workspace.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			response.ServerError(c, "获取工作区设置失败")
+			return
+		}
+		existing = &model.WorkspaceSettings{WorkspaceID: workspace.ID}
+	}
+
+	// 脱敏值表示保持不变
+	before := *existing
+	keep := func(newKey, oldKey string) string {
+		if isMaskedApiKey(newKey) {
+			return oldKey
+		}
+		return newKey
+	}
+	existing.OpenAIApiKey = keep(req.ProviderKeys.OpenAI, existing.OpenAIApiKey)
+	existing.DeepSeekApiKey = keep(req.ProviderKeys.DeepSeek, existing.DeepSeekApiKey)
+	existing.MoonshotApiKey = keep(req.ProviderKeys.Moonshot, existing.MoonshotApiKey)
+	existing.QwenApiKey = keep(req.ProviderKeys.Qwen, existing.QwenApiKey)
+	existing.HunyuanApiKey = keep(req.ProviderKeys.Hunyuan, existing.HunyuanApiKey)
+	existing.DoubaoApiKey = keep(req.ProviderKeys.Doubao, existing.DoubaoApiKey)
+	existing.ZhipuApiKey = keep(req.ProviderKeys.Zhipu, existing.ZhipuApiKey)
+	existing.AnthropicApiKey = keep(req.ProviderKeys.Anthropic, existing.AnthropicApiKey)
+	existing.TTSApiKey = keep(req.TTSApiKey, existing.TTSApiKey)
+	existing.ImageGenApiKey = keep(req.ImageGenApiKey, existing.ImageGenApiKey)
+	existing.UpdatedBy = c.GetInt64("userID")
+
+	if err := h.workspaceRepo.UpsertSettings(c.Request.Context(), existing); err != nil {
+		log.Printf("[API] 保存工作区设置失败: %v", err)
+		response.ServerError(c, "保存工作区设置失败")
+		return
+	}
+	auditEntry(c).SetChanges(&before, existing)
+	response.SuccessWithMessage(c, "保存成功", nil)
+}
+
+// loadWorkspace 根据路径参数加载工作区并检查权限，返回当前用户的角色，失败时已写入响应
+func (h *WorkspaceHandler) loadWorkspace(c *gin.Context, action policy.Action) (*model.Workspace, string, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的工作区ID")
+		return nil, "", false
+	}
+
+	userID := c.GetInt64("userID")
+	if err := h.authorizer.Authorize(c.Request.Context(), userID, id, action); err != nil {
+		respondAuthzError(c, err)
+		return nil, "", false
+	}
+	role, _ := h.authorizer.Role(c.Request.Context(), userID, id)
+
+	workspace, err := h.workspaceRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		response.NotFound(c, "工作区不存在")
+		return nil, "", false
+	}
+	return workspace, role, true
+}
+
+// loadMember 根据路径参数加载工作区成员，失败时已写入响应
+func (h *WorkspaceHandler) loadMember(c *gin.Context, workspaceID int64) (*model.WorkspaceMember, bool) {
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "无效的用户ID")
+		return nil, false
+	}
+	member, err := h.workspaceRepo.GetMember(c.Request.Context(), workspaceID, userID)
+	if err != nil {
+		response.NotFound(c, "成员不存在")
+		return nil, false
+	}
+	return member, true
+}
+
+// hasOtherOwner 检查移除或降级一个所有者后工作区仍有所有者，失败时已写入响应
+func (h *WorkspaceHandler) hasOtherOwner(c *gin.Context, workspaceID int64) bool {
+	count, err := h.workspaceRepo.CountOwners(c.Request.Context(), workspaceID)
+	if err != nil {
+		response.ServerError(c, "查询工作区成员失败")
+		return false
+	}
+	if count <= 1 {
+		response.BadRequest(c, "工作区至少需要保留一名所有者")
+		return false
+	}
+	return true
+}
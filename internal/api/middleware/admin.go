@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin 只允许系统管理员（用户角色为 admin）访问，需在 AuthMiddleware 之后
+func RequireAdmin(users repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := users.GetByID(c.Request.Context(), c.GetInt64("userID"))
+		if err != nil {
+			response.Unauthorized(c, "user not found")
+			c.Abort()
+			return
+		}
+		if !user.IsAdmin() {
+			response.Forbidden(c, "admin only")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
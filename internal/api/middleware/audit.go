@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"copycat/internal/core/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Audit 审计中间件：请求成功（状态码 < 400）后写入一条审计记录。操作对象默认为路径参数 id，
+// 处理器可从上下文取出记录（audit.ContextKey）补充对象、所属工作区和变更内容
+func Audit(recorder *audit.Service, action string) gin.HandlerFunc {
+	targetType := strings.SplitN(action, ".", 2)[0]
+	return func(c *gin.Context) {
+		entry := &audit.Entry{
+			ActorID:    c.GetInt64("userID"),
+			Action:     action,
+			TargetType: targetType,
+			TargetID:   c.Param("id"),
+			IP:         c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+		}
+		// 工作区接口的路径参数就是工作区 ID
+		if targetType == "workspace" {
+			entry.WorkspaceID, _ = strconv.ParseInt(entry.TargetID, 10, 64)
+		}
+		c.Set(audit.ContextKey, entry)
+
+		c.Next()
+
+		if entry.Discarded() || c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		// 审计日志写入失败不影响已完成的操作
+		if err := recorder.Record(c.Request.Context(), entry); err != nil {
+			log.Printf("[Audit] 写入审计日志失败: action=%s actor=%d err=%v", action, entry.ActorID, err)
+		}
+	}
+}
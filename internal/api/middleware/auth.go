@@ -1,17 +1,17 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
 
-	"copycat/config"
+	"copycat/internal/core/auth"
 	"copycat/pkg/response"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-// AuthMiddleware JWT 认证中间件
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware 认证中间件：支持登录签发的 JWT 和个人访问令牌（ccp_ 前缀）
+func AuthMiddleware(tokens *auth.TokenService, personalTokens *auth.PersonalTokenService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -28,39 +28,44 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		tokenString := parts[1]
-
-		// 解析 JWT Token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		// 个人访问令牌：记录令牌以便 RequireScope 校验权限范围
+		if auth.IsPersonalToken(parts[1]) {
+			token, err := personalTokens.Authenticate(c.Request.Context(), parts[1])
+			if err != nil {
+				switch {
+				case errors.Is(err, auth.ErrInvalidToken):
+					response.Unauthorized(c, "invalid, expired or revoked access token")
+				case errors.Is(err, auth.ErrAccountDisabled):
+					response.Forbidden(c, "account has been disabled")
+				default:
+					response.ServerError(c, "failed to verify token")
+				}
+				c.Abort()
+				return
 			}
-			return []byte(config.AppCfg.JWT.Secret), nil
-		})
-
-		if err != nil || !token.Valid {
-			response.Unauthorized(c, "invalid or expired token")
-			c.Abort()
-			return
-		}
-
-		// 提取用户 ID
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			response.Unauthorized(c, "invalid token claims")
-			c.Abort()
+			c.Set("userID", token.UserID)
+			c.Set("personalToken", token)
+			c.Next()
 			return
 		}
 
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			response.Unauthorized(c, "invalid user id in token")
+		// 校验签名、有效期和令牌版本（修改密码或退出全部设备后旧令牌失效）
+		userID, err := tokens.Authenticate(c.Request.Context(), parts[1])
+		if err != nil {
+			switch {
+			case errors.Is(err, auth.ErrInvalidToken):
+				response.Unauthorized(c, "invalid or expired token")
+			case errors.Is(err, auth.ErrAccountDisabled):
+				response.Forbidden(c, "account has been disabled")
+			default:
+				response.ServerError(c, "failed to verify token")
+			}
 			c.Abort()
 			return
 		}
 
 		// 将用户 ID 存入上下文
-		c.Set("userID", int64(userID))
+		c.Set("userID", userID)
 		c.Next()
 	}
 }
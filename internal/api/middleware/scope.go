@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"strings"
+
+	"copycat/internal/model"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope 个人访问令牌需包含任一指定权限范围才能访问，登录会话（JWT）不受限制（需在 AuthMiddleware 之后）
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := personalToken(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		for _, scope := range scopes {
+			if token.HasScope(scope) {
+				c.Next()
+				return
+			}
+		}
+		response.Forbidden(c, "access token missing required scope: "+strings.Join(scopes, " or "))
+		c.Abort()
+	}
+}
+
+// RequireSession 只允许登录会话访问（账号、设置、工作区和令牌管理），拒绝个人访问令牌
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := personalToken(c); ok {
+			response.Forbidden(c, "this endpoint is not available to access tokens")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// personalToken 当前请求使用的个人访问令牌
+func personalToken(c *gin.Context) (*model.PersonalAccessToken, bool) {
+	v, exists := c.Get("personalToken")
+	if !exists {
+		return nil, false
+	}
+	token, ok := v.(*model.PersonalAccessToken)
+	return token, ok
+}
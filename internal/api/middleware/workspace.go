@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+
+	"copycat/internal/core/policy"
+	"copycat/internal/repository"
+	"copycat/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHeader 指定当前工作区的请求头，未指定时使用用户的个人工作区
+const WorkspaceHeader = "X-Workspace-ID"
+
+// WorkspaceMiddleware 解析当前工作区并校验成员身份，将工作区 ID 和角色存入上下文（需在 AuthMiddleware 之后）
+func WorkspaceMiddleware(workspaces repository.WorkspaceRepository, authorizer *policy.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetInt64("userID")
+
+		var workspaceID int64
+		if header := c.GetHeader(WorkspaceHeader); header != "" {
+			id, err := strconv.ParseInt(header, 10, 64)
+			if err != nil || id <= 0 {
+				response.BadRequest(c, "invalid workspace id")
+				c.Abort()
+				return
+			}
+			workspaceID = id
+		} else {
+			workspace, err := workspaces.GetPersonal(c.Request.Context(), userID)
+			if err != nil {
+				response.ServerError(c, "failed to get personal workspace")
+				c.Abort()
+				return
+			}
+			workspaceID = workspace.ID
+		}
+
+		role, err := authorizer.Role(c.Request.Context(), userID, workspaceID)
+		if err != nil {
+			if errors.Is(err, policy.ErrNotMember) {
+				response.Forbidden(c, "not a member of this workspace")
+			} else {
+				response.ServerError(c, "failed to check workspace membership")
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("workspaceID", workspaceID)
+		c.Set("workspaceRole", role)
+		c.Next()
+	}
+}
@@ -1,17 +1,27 @@
 package api
 
 import (
+	"copycat/config"
 	"copycat/internal/api/handler"
 	"copycat/internal/api/middleware"
 	"copycat/internal/core/agent"
+	"copycat/internal/core/audit"
+	"copycat/internal/core/auth"
+	"copycat/internal/core/media"
+	"copycat/internal/core/oidc"
+	"copycat/internal/core/policy"
+	"copycat/internal/core/userdata"
+	"copycat/internal/model"
 	"copycat/internal/repository"
+	"copycat/pkg/mailer"
+	"copycat/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 // SetupRouter 设置路由
-func SetupRouter(db *gorm.DB) *gin.Engine {
+func SetupRouter(db *gorm.DB, store storage.Storage, mail mailer.Mailer, sso *oidc.Provider) *gin.Engine {
 	r := gin.Default()
 
 	// 全局中间件
@@ -21,18 +31,48 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 	// 初始化仓库
 	userRepo := repository.NewUserRepository(db)
 	projectRepo := repository.NewProjectRepository(db)
+	workspaceRepo := repository.NewWorkspaceRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	personalTokenRepo := repository.NewPersonalAccessTokenRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	identityRepo := repository.NewUserIdentityRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	systemSettingsRepo := repository.NewSystemSettingsRepository(db)
 
 	// 初始化服务
-	contentService := agent.NewContentService(projectRepo)
+	mediaCache := media.NewCache(store, repository.NewMediaObjectRepository(db))
+	contentService := agent.NewContentService(projectRepo, mediaCache)
+	authorizer := policy.NewAuthorizer(workspaceRepo, projectRepo)
+	tokenService := auth.NewTokenService(config.AppCfg.JWT, userRepo, refreshTokenRepo)
+	personalTokenService := auth.NewPersonalTokenService(userRepo, personalTokenRepo)
+	loginGuard := auth.NewLoginGuard(config.AppCfg.Login, loginAttemptRepo)
+	auditService := audit.NewService(repository.NewAuditLogRepository(db))
+	userdataService := userdata.NewService(repository.NewAccountDataRepository(db), repository.NewExportJobRepository(db), userRepo,
+		repository.NewUserSettingsRepository(db), workspaceRepo, store)
+	accountService := auth.NewAccountService(userRepo, userTokenRepo, tokenService, mail, config.AppCfg.Mail.LinkBaseURL)
 
 	// 初始化处理器
-	userHandler := handler.NewUserHandler(userRepo)
-	projectHandler := handler.NewProjectHandler(projectRepo)
+	userHandler := handler.NewUserHandler(userRepo, workspaceRepo, tokenService, accountService, loginGuard)
+	projectHandler := handler.NewProjectHandler(projectRepo, repository.NewAssetRepository(db), store, authorizer)
 	crawlerHandler := handler.NewCrawlerHandler(contentService)
 	settingsHandler := handler.NewSettingsHandler(db)
-	analysisHandler := handler.NewAnalysisHandler(db)
-	batchHandler := handler.NewBatchHandler(db, contentService)
-	speechHandler := handler.NewSpeechHandler(db)
+	analysisHandler := handler.NewAnalysisHandler(db, mediaCache, authorizer)
+	batchHandler := handler.NewBatchHandler(db, contentService, mediaCache, authorizer)
+	speechHandler := handler.NewSpeechHandler(db, store, authorizer)
+	imageHandler := handler.NewImageHandler(db, store, authorizer)
+	complianceHandler := handler.NewComplianceHandler()
+	mediaHandler := handler.NewMediaHandler(mediaCache)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceRepo, userRepo, projectRepo, authorizer)
+	tokenHandler := handler.NewTokenHandler(personalTokenRepo, personalTokenService)
+	adminHandler := handler.NewAdminHandler(userRepo, repository.NewUsageRepository(db), projectRepo, repository.NewBatchTaskRepository(db), systemSettingsRepo, loginAttemptRepo, loginGuard, tokenService)
+	oidcHandler := handler.NewOIDCHandler(sso, userRepo, workspaceRepo, identityRepo, tokenService)
+	accountHandler := handler.NewAccountHandler(userRepo, userdataService)
+	auditHandler := handler.NewAuditHandler(auditService, userRepo, authorizer)
+
+	// 审计：请求成功后记录操作人、IP 和变更内容
+	audited := func(action string) gin.HandlerFunc {
+		return middleware.Audit(auditService, action)
+	}
 
 	// API v1 路由组
 	v1 := r.Group("/api/v1")
@@ -40,49 +80,123 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 		// 公开路由 (无需认证)
 		v1.POST("/register", userHandler.Register)
 		v1.POST("/login", userHandler.Login)
+		v1.POST("/token/refresh", userHandler.RefreshToken)
+		v1.POST("/email/verify", userHandler.VerifyEmail)
+		v1.POST("/password/forgot", userHandler.ForgotPassword)
+		v1.POST("/password/reset", audited(model.AuditUserPasswordReset), userHandler.ResetPassword)
+		v1.GET("/oidc/config", oidcHandler.GetConfig)
+		v1.GET("/oidc/login", oidcHandler.Login)
+		v1.GET("/oidc/callback", oidcHandler.Callback)
 
 		// 需要认证的路由
 		auth := v1.Group("")
-		auth.Use(middleware.AuthMiddleware())
+		auth.Use(middleware.AuthMiddleware(tokenService, personalTokenService))
+		auth.Use(middleware.WorkspaceMiddleware(workspaceRepo, authorizer)) // 当前工作区（X-Workspace-ID，默认个人工作区）
 		{
+			// 个人访问令牌只能访问声明了权限范围的接口，账号、设置、工作区和令牌管理仅限登录会话
+			session := middleware.RequireSession()
+			scope := middleware.RequireScope
+
 			// 用户相关
-			auth.GET("/user/profile", userHandler.GetProfile)
-			auth.PUT("/user/profile", userHandler.UpdateProfile)
-			auth.PUT("/user/password", userHandler.ChangePassword)
+			auth.POST("/logout", session, audited(model.AuditUserLogoutAll), userHandler.Logout)
+			auth.GET("/user/profile", session, userHandler.GetProfile)
+			auth.PUT("/user/profile", session, audited(model.AuditUserProfileUpdate), userHandler.UpdateProfile)
+			auth.PUT("/user/password", session, audited(model.AuditUserPasswordChange), userHandler.ChangePassword)
+			auth.POST("/email/verify/resend", session, userHandler.ResendVerification)
+
+			// 账号数据导出与注销
+			auth.POST("/account/export", session, audited(model.AuditUserDataExport), accountHandler.StartExport)
+			auth.GET("/account/export", session, accountHandler.GetExport)
+			auth.GET("/account/export/download", session, accountHandler.DownloadExport)
+			auth.DELETE("/account", session, audited(model.AuditUserAccountDelete), accountHandler.DeleteAccount)
+
+			// 工作区相关
+			auth.GET("/workspaces", session, workspaceHandler.List)
+			auth.POST("/workspaces", session, audited(model.AuditWorkspaceCreate), workspaceHandler.Create)
+			auth.GET("/workspaces/:id", session, workspaceHandler.Get)
+			auth.PUT("/workspaces/:id", session, audited(model.AuditWorkspaceUpdate), workspaceHandler.Update)
+			auth.DELETE("/workspaces/:id", session, audited(model.AuditWorkspaceDelete), workspaceHandler.Delete)
+			auth.POST("/workspaces/:id/members", session, audited(model.AuditWorkspaceMemberAdd), workspaceHandler.AddMember)
+			auth.PUT("/workspaces/:id/members/:user_id", session, audited(model.AuditWorkspaceMemberUpdate), workspaceHandler.UpdateMember)
+			auth.DELETE("/workspaces/:id/members/:user_id", session, audited(model.AuditWorkspaceMemberRemove), workspaceHandler.RemoveMember)
+			auth.GET("/workspaces/:id/settings", session, workspaceHandler.GetSettings)
+			auth.PUT("/workspaces/:id/settings", session, audited(model.AuditWorkspaceSettings), workspaceHandler.SaveSettings)
+
+			// 个人访问令牌
+			auth.GET("/tokens", session, tokenHandler.List)
+			auth.POST("/tokens", session, audited(model.AuditTokenCreate), tokenHandler.Create)
+			auth.DELETE("/tokens/:id", session, audited(model.AuditTokenRevoke), tokenHandler.Revoke)
+
+			// 管理员
+			admin := auth.Group("/admin", session, middleware.RequireAdmin(userRepo))
+			admin.GET("/users", adminHandler.ListUsers)
+			admin.GET("/users/:id", adminHandler.GetUser)
+			admin.PUT("/users/:id", audited(model.AuditAdminUserUpdate), adminHandler.UpdateUser)
+			admin.GET("/users/:id/batches", adminHandler.ListUserBatches)
+			admin.GET("/batch-failures", adminHandler.ListBatchFailures)
+			admin.GET("/system-settings", adminHandler.GetSystemSettings)
+			admin.PUT("/system-settings", audited(model.AuditAdminSystemSettings), adminHandler.SaveSystemSettings)
+			admin.GET("/login-locks", adminHandler.ListLoginLocks)
+			admin.POST("/login-locks/unlock", audited(model.AuditAdminLoginUnlock), adminHandler.UnlockLogin)
+			admin.GET("/login-attempts", adminHandler.ListLoginAttempts)
+
+			// 审计日志（管理员查看全部，工作区所有者查看本工作区）
+			auth.GET("/audit", session, auditHandler.List)
 
 			// 项目相关
-			auth.POST("/projects", projectHandler.Create)
-			auth.GET("/projects", projectHandler.List)
-			auth.GET("/projects/check", projectHandler.GetByURL)       // 检查链接是否已分析（需在 :id 之前）
-			auth.DELETE("/projects/batch", projectHandler.BatchDelete) // 批量删除（需在 :id 之前）
-			auth.GET("/projects/:id", projectHandler.Get)
-			auth.PUT("/projects/:id", projectHandler.Update)
-			auth.DELETE("/projects/:id", projectHandler.Delete)
+			auth.POST("/projects", scope(model.ScopeProjectsWrite), projectHandler.Create)
+			auth.GET("/projects", scope(model.ScopeProjectsRead), projectHandler.List)
+			auth.GET("/projects/check", scope(model.ScopeProjectsRead), projectHandler.GetByURL)                                                // 检查链接是否已分析（需在 :id 之前）
+			auth.DELETE("/projects/batch", scope(model.ScopeProjectsWrite), audited(model.AuditProjectBatchDelete), projectHandler.BatchDelete) // 批量删除（需在 :id 之前）
+			auth.GET("/projects/:id", scope(model.ScopeProjectsRead), projectHandler.Get)
+			auth.PUT("/projects/:id", scope(model.ScopeProjectsWrite), audited(model.AuditProjectUpdate), projectHandler.Update)
+			auth.DELETE("/projects/:id", scope(model.ScopeProjectsWrite), audited(model.AuditProjectDelete), projectHandler.Delete)
+			auth.GET("/projects/:id/generations", scope(model.ScopeProjectsRead), analysisHandler.ListGenerations)
+			auth.GET("/projects/:id/audio", scope(model.ScopeProjectsRead, model.ScopeSpeech), speechHandler.ListProjectAudio)
+			auth.POST("/projects/:id/images", scope(model.ScopeImages), imageHandler.GenerateImages)
+			auth.GET("/projects/:id/images", scope(model.ScopeProjectsRead, model.ScopeImages), imageHandler.ListProjectImages)
 
 			// 爬虫相关
-			auth.POST("/crawl", crawlerHandler.Crawl)
+			auth.POST("/crawl", scope(model.ScopeAnalyze), crawlerHandler.Crawl)
+			auth.GET("/media/:hash", scope(model.ScopeProjectsRead, model.ScopeAnalyze), mediaHandler.GetMedia) // 爬取时缓存的笔记图片
 
 			// 设置相关
-			auth.GET("/settings/llm", settingsHandler.GetLLMConfig)
-			auth.POST("/settings/api-config", settingsHandler.SaveApiConfig)           // 模块1: API 配置
-			auth.POST("/settings/model-config", settingsHandler.SaveModelConfig)       // 模块2: 模型选择
-			auth.POST("/settings/generate-config", settingsHandler.SaveGenerateConfig) // 模块3: 生成设置
-			auth.POST("/settings/task-type", settingsHandler.SaveTaskType)             // 新增: 任务类型偏好
+			auth.GET("/settings/llm", session, settingsHandler.GetLLMConfig)
+			auth.POST("/settings/api-config", session, audited(model.AuditSettingsApiConfig), settingsHandler.SaveApiConfig)                 // 模块1: API 配置
+			auth.POST("/settings/model-config", session, audited(model.AuditSettingsModelConfig), settingsHandler.SaveModelConfig)           // 模块2: 模型选择
+			auth.POST("/settings/generate-config", session, audited(model.AuditSettingsGenerateConfig), settingsHandler.SaveGenerateConfig)  // 模块3: 生成设置
+			auth.POST("/settings/task-type", session, audited(model.AuditSettingsTaskType), settingsHandler.SaveTaskType)                    // 新增: 任务类型偏好
+			auth.POST("/settings/tts-config", session, audited(model.AuditSettingsTTSConfig), settingsHandler.SaveTTSConfig)                 // 语音合成服务商
+			auth.POST("/settings/image-gen-config", session, audited(model.AuditSettingsImageGenConfig), settingsHandler.SaveImageGenConfig) // 图像生成服务商
 
 			// 分析与生成相关
-			auth.POST("/analyze", analysisHandler.Analyze)
-			auth.POST("/analyze-images", analysisHandler.AnalyzeImages)
-			auth.POST("/generate", analysisHandler.Generate)
+			auth.POST("/analyze", scope(model.ScopeAnalyze), analysisHandler.Analyze)
+			auth.POST("/analyze-images", scope(model.ScopeAnalyze), analysisHandler.AnalyzeImages)
+			auth.POST("/generate", scope(model.ScopeAnalyze), analysisHandler.Generate)
+			auth.POST("/generate/refine", scope(model.ScopeAnalyze), analysisHandler.Refine)
+
+			// 合规检查
+			auth.POST("/compliance/check", scope(model.ScopeAnalyze), complianceHandler.Check)
 
 			// 批量任务相关
-			auth.POST("/batch/analyze", batchHandler.CreateBatchAnalyze)
-			auth.GET("/batch/:id", batchHandler.GetBatchStatus)
-			auth.GET("/batch/list", batchHandler.ListBatchTasks)
+			auth.POST("/batch/analyze", scope(model.ScopeBatch), batchHandler.CreateBatchAnalyze)
+			auth.GET("/batch/:id", scope(model.ScopeBatch), batchHandler.GetBatchStatus)
+			auth.GET("/batch/list", scope(model.ScopeBatch), batchHandler.ListBatchTasks)
 
 			// 语音合成相关
-			auth.POST("/speech/generate", speechHandler.GenerateSpeech)
-			auth.GET("/speech/voices", speechHandler.GetVoices)
-			auth.GET("/speech/models", speechHandler.GetModels)
+			auth.POST("/speech/generate", scope(model.ScopeSpeech), speechHandler.GenerateSpeech)
+			auth.GET("/speech/voices", scope(model.ScopeSpeech), speechHandler.GetVoices)
+			auth.GET("/speech/models", scope(model.ScopeSpeech), speechHandler.GetModels)
+			auth.GET("/speech/providers", scope(model.ScopeSpeech), speechHandler.GetProviders)
+			auth.POST("/speech/script/parse", scope(model.ScopeSpeech), speechHandler.ParseScript)
+			auth.GET("/speech/:id", scope(model.ScopeProjectsRead, model.ScopeSpeech), speechHandler.StreamSpeech) // 需在 voices/models 之后注册
+			auth.GET("/speech/:id/subtitles", scope(model.ScopeProjectsRead, model.ScopeSpeech), speechHandler.ExportSubtitles)
+			auth.DELETE("/speech/:id", scope(model.ScopeSpeech), audited(model.AuditSpeechDelete), speechHandler.DeleteSpeech)
+
+			// 图像生成相关
+			auth.GET("/images/providers", scope(model.ScopeImages), imageHandler.GetProviders)
+			auth.GET("/images/:id", scope(model.ScopeProjectsRead, model.ScopeImages), imageHandler.GetImage) // 需在 providers 之后注册
+			auth.DELETE("/images/:id", scope(model.ScopeImages), audited(model.AuditImageDelete), imageHandler.DeleteImage)
 		}
 	}
 
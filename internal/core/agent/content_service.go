@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"copycat/internal/core/crawler"
+	"copycat/internal/core/media"
 	"copycat/internal/repository"
 
 	"gorm.io/datatypes"
@@ -15,13 +16,15 @@ import (
 type ContentService struct {
 	crawlerManager *crawler.CrawlerManager
 	projectRepo    repository.ProjectRepository
+	mediaCache     *media.Cache
 }
 
-// NewContentService 创建内容服务
-func NewContentService(projectRepo repository.ProjectRepository) *ContentService {
+// NewContentService 创建内容服务，mediaCache 为 nil 时不缓存笔记图片
+func NewContentService(projectRepo repository.ProjectRepository, mediaCache *media.Cache) *ContentService {
 	return &ContentService{
 		crawlerManager: crawler.NewCrawlerManager(),
 		projectRepo:    projectRepo,
+		mediaCache:     mediaCache,
 	}
 }
 
@@ -36,6 +39,7 @@ func (s *ContentService) CrawlAndSave(ctx context.Context, projectID interface{}
 	if !result.Success {
 		return result, nil
 	}
+	s.cacheMedia(ctx, result)
 
 	// 如果项目 ID 有效，更新项目内容
 	if projectID != nil {
@@ -47,9 +51,25 @@ func (s *ContentService) CrawlAndSave(ctx context.Context, projectID interface{}
 	return result, nil
 }
 
-// CrawlOnly 仅爬取内容，不保存
+// CrawlOnly 仅爬取内容，不保存到项目（笔记图片仍会下载到媒体缓存）
 func (s *ContentService) CrawlOnly(ctx context.Context, url string) (*crawler.CrawlResult, error) {
-	return s.crawlerManager.Crawl(ctx, url)
+	result, err := s.crawlerManager.Crawl(ctx, url)
+	if err == nil && result.Success {
+		s.cacheMedia(ctx, result)
+	}
+	return result, err
+}
+
+// cacheMedia 趁图片链接还有效时下载封面和图片，填充代理地址；单张失败不影响爬取结果
+func (s *ContentService) cacheMedia(ctx context.Context, result *crawler.CrawlResult) {
+	if s.mediaCache == nil || result.Content == nil {
+		return
+	}
+	content := result.Content
+	urls := append([]string{content.CoverURL}, content.Images...)
+	proxied := s.mediaCache.FetchAll(ctx, urls)
+	content.CachedCover = proxied[0]
+	content.CachedImages = proxied[1:]
 }
 
 // updateProject 更新项目内容
@@ -0,0 +1,222 @@
+// Package audit 审计日志：记录安全相关和修改数据的操作（谁、何时、从哪个 IP、对什么做了什么），
+// 变更内容中的密钥、密码和令牌只记录是否有值，不落库明文
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"copycat/internal/model"
+	"copycat/internal/repository"
+)
+
+// ContextKey 请求上下文中当前审计记录的键（由 Audit 中间件写入，处理器补充目标和变更内容）
+const ContextKey = "auditEntry"
+
+// Redacted 脱敏后的非空敏感值
+const Redacted = "[REDACTED]"
+
+// maxValueLen 变更内容中长文本（文案、爬取内容等）只保留前若干个字符
+const maxValueLen = 200
+
+// sensitiveKeys 字段名包含这些片段时脱敏
+var sensitiveKeys = []string{"api_key", "apikey", "password", "secret", "token"}
+
+// ignoredKeys 不参与比较的字段
+var ignoredKeys = map[string]bool{"id": true, "created_at": true, "updated_at": true, "user": true}
+
+// Change 单个字段的变更
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Entry 一条待写入的审计记录
+type Entry struct {
+	ActorID     int64
+	WorkspaceID int64
+	Action      string
+	TargetType  string
+	TargetID    string
+	IP          string
+	UserAgent   string
+	Changes     map[string]Change
+	Details     map[string]interface{}
+
+	discarded bool
+}
+
+// SetTarget 设置操作对象，e 为 nil 时忽略（路由未挂审计中间件）
+func (e *Entry) SetTarget(targetType string, targetID interface{}) {
+	if e == nil {
+		return
+	}
+	e.TargetType = targetType
+	e.TargetID = fmt.Sprint(targetID)
+}
+
+// SetWorkspace 设置操作所属的工作区（工作区所有者可以查看）
+func (e *Entry) SetWorkspace(workspaceID int64) {
+	if e != nil {
+		e.WorkspaceID = workspaceID
+	}
+}
+
+// SetActor 设置操作人（公开接口在处理器中确认用户后设置，如重置密码）
+func (e *Entry) SetActor(userID int64) {
+	if e != nil {
+		e.ActorID = userID
+	}
+}
+
+// SetChanges 记录修改前后的差异（敏感字段脱敏）
+func (e *Entry) SetChanges(before, after interface{}) {
+	if e != nil {
+		e.Changes = Diff(before, after)
+	}
+}
+
+// Discard 本次请求不需要记录（如未产生实际变更）
+func (e *Entry) Discard() {
+	if e != nil {
+		e.discarded = true
+	}
+}
+
+// Discarded 是否已被处理器丢弃
+func (e *Entry) Discarded() bool {
+	return e == nil || e.discarded
+}
+
+// AddDetail 记录附加信息
+func (e *Entry) AddDetail(key string, value interface{}) {
+	if e == nil {
+		return
+	}
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+}
+
+// Service 审计日志服务
+type Service struct {
+	repo repository.AuditLogRepository
+}
+
+// NewService 创建审计日志服务
+func NewService(repo repository.AuditLogRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// Record 写入一条审计记录
+func (s *Service) Record(ctx context.Context, entry *Entry) error {
+	changes, err := marshalOptional(entry.Changes)
+	if err != nil {
+		return err
+	}
+	details, err := marshalOptional(entry.Details)
+	if err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, &model.AuditLog{
+		ActorID:     entry.ActorID,
+		WorkspaceID: entry.WorkspaceID,
+		Action:      entry.Action,
+		TargetType:  entry.TargetType,
+		TargetID:    truncate(entry.TargetID, 100),
+		IP:          truncate(entry.IP, 64),
+		UserAgent:   truncate(entry.UserAgent, 500),
+		Changes:     changes,
+		Details:     details,
+	})
+}
+
+// List 按条件分页查询审计记录
+func (s *Service) List(ctx context.Context, filter repository.AuditLogFilter, page, pageSize int) ([]*model.AuditLog, int64, error) {
+	return s.repo.List(ctx, filter, page, pageSize)
+}
+
+// Diff 比较两个对象按 JSON 字段名展开后的差异，敏感字段只记录是否有值，长文本截断
+func Diff(before, after interface{}) map[string]Change {
+	from, to := toMap(before), toMap(after)
+	changes := make(map[string]Change)
+	for key, newValue := range to {
+		if ignoredKeys[key] {
+			continue
+		}
+		oldValue, existed := from[key]
+		if existed && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes[key] = Change{From: sanitize(key, oldValue), To: sanitize(key, newValue)}
+	}
+	for key, oldValue := range from {
+		if _, ok := to[key]; !ok && !ignoredKeys[key] {
+			changes[key] = Change{From: sanitize(key, oldValue), To: nil}
+		}
+	}
+	return changes
+}
+
+// IsSensitive 字段是否需要脱敏
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitize 敏感字段的非空值替换为 Redacted，超长文本截断
+func sanitize(key string, value interface{}) interface{} {
+	if value == nil || value == "" {
+		return value
+	}
+	if IsSensitive(key) {
+		return Redacted
+	}
+	if str, ok := value.(string); ok {
+		if runes := []rune(str); len(runes) > maxValueLen {
+			return fmt.Sprintf("%s...(%d chars)", string(runes[:maxValueLen]), len(runes))
+		}
+	}
+	return value
+}
+
+// toMap 将结构体按 JSON 标签展开为 map（json:"-" 的字段不参与比较），nil 时返回空 map
+func toMap(v interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	if v == nil {
+		return m
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return m
+	}
+	_ = json.Unmarshal(data, &m)
+	return m
+}
+
+func marshalOptional[T any](v map[string]T) ([]byte, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit data: %w", err)
+	}
+	return data, nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/logger"
+	"copycat/pkg/mailer"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// 一次性令牌参数
+const (
+	emailVerifyTTL    = 24 * time.Hour   // 邮箱验证链接有效期
+	passwordResetTTL  = 30 * time.Minute // 重置密码链接有效期
+	mailResendBackoff = time.Minute      // 同一用途两封邮件的最小间隔
+	mailSendTimeout   = time.Minute      // 后台发送邮件的超时时间
+	userTokenBytes    = 32
+	defaultLinkBase   = "http://localhost:3000"
+)
+
+// ErrTooFrequent 邮件发送过于频繁
+var ErrTooFrequent = errors.New("please wait before requesting another email")
+
+// AccountService 邮箱验证和找回密码：签发一次性令牌并通过邮件发送链接
+type AccountService struct {
+	users    repository.UserRepository
+	tokens   repository.UserTokenRepository
+	sessions *TokenService
+	mailer   mailer.Mailer
+	linkBase string
+}
+
+// NewAccountService 创建账号服务，linkBase 为邮件链接指向的前端地址
+func NewAccountService(users repository.UserRepository, tokens repository.UserTokenRepository, sessions *TokenService, m mailer.Mailer, linkBase string) *AccountService {
+	if linkBase == "" {
+		linkBase = defaultLinkBase
+	}
+	return &AccountService{
+		users:    users,
+		tokens:   tokens,
+		sessions: sessions,
+		mailer:   m,
+		linkBase: strings.TrimRight(linkBase, "/"),
+	}
+}
+
+// SendVerification 发送邮箱验证邮件（已验证时不发送），之前未使用的验证链接随即失效
+func (s *AccountService) SendVerification(ctx context.Context, user *model.User) error {
+	if user.EmailVerified {
+		return nil
+	}
+	plain, err := s.issue(ctx, user, model.UserTokenEmailVerify, emailVerifyTTL)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "验证你的 CopyCat 邮箱",
+		Body: fmt.Sprintf("你好%s，\n\n请在 24 小时内打开以下链接完成邮箱验证：\n\n%s\n\n如果这不是你本人的操作，请忽略本邮件。\n",
+			greetingName(user), s.link("/verify-email", plain)),
+	})
+}
+
+// VerifyEmail 使用验证令牌完成邮箱验证
+func (s *AccountService) VerifyEmail(ctx context.Context, plain string) (*model.User, error) {
+	user, err := s.consume(ctx, model.UserTokenEmailVerify, plain)
+	if err != nil {
+		return nil, err
+	}
+	if !user.EmailVerified {
+		user.EmailVerified = true
+		if err := s.users.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
+
+// RequestPasswordReset 在后台发送重置密码邮件并立即返回，调用方无法从结果或耗时判断邮箱是否注册
+func (s *AccountService) RequestPasswordReset(email string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		if err := s.sendPasswordReset(ctx, email); err != nil {
+			logger.Error("[Account] failed to send password reset mail to %s: %v", email, err)
+		}
+	}()
+}
+
+// sendPasswordReset 发送重置密码邮件，邮箱未注册或请求过于频繁时静默返回
+func (s *AccountService) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	plain, err := s.issue(ctx, user, model.UserTokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		if errors.Is(err, ErrTooFrequent) {
+			return nil
+		}
+		return err
+	}
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "重置你的 CopyCat 密码",
+		Body: fmt.Sprintf("你好%s，\n\n我们收到了重置密码的请求，请在 30 分钟内打开以下链接设置新密码：\n\n%s\n\n链接只能使用一次。如果这不是你本人的操作，请忽略本邮件，你的密码不会改变。\n",
+			greetingName(user), s.link("/reset-password", plain)),
+	})
+}
+
+// ResetPassword 使用重置令牌设置新密码，并使该用户所有已登录的会话失效，返回密码被重置的用户
+func (s *AccountService) ResetPassword(ctx context.Context, plain, newPassword string) (*model.User, error) {
+	user, err := s.consume(ctx, model.UserTokenPasswordReset, plain)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = string(hashed)
+	// 能收到重置邮件说明邮箱属于该用户
+	user.EmailVerified = true
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.sessions.RevokeAll(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// issue 签发一次性令牌，返回明文
+func (s *AccountService) issue(ctx context.Context, user *model.User, purpose string, ttl time.Duration) (string, error) {
+	latest, err := s.tokens.LatestCreatedAt(ctx, user.ID, purpose)
+	if err != nil {
+		return "", err
+	}
+	if latest != nil && time.Since(*latest) < mailResendBackoff {
+		return "", ErrTooFrequent
+	}
+	if err := s.tokens.InvalidateByUserID(ctx, user.ID, purpose); err != nil {
+		return "", err
+	}
+
+	raw := make([]byte, userTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate user token: %w", err)
+	}
+	plain := base64.RawURLEncoding.EncodeToString(raw)
+	if err := s.tokens.Create(ctx, &model.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		TokenHash: hashToken(plain),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+	logger.Info("[Account] issued %s token for user %d", purpose, user.ID)
+	return plain, nil
+}
+
+// consume 使用一次性令牌并返回所属用户；令牌无效、已使用、已过期或邮箱已变更时返回 ErrInvalidToken
+func (s *AccountService) consume(ctx context.Context, purpose, plain string) (*model.User, error) {
+	token, err := s.tokens.Consume(ctx, purpose, hashToken(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	user, err := s.users.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, token.Email) {
+		return nil, ErrInvalidToken
+	}
+	return user, nil
+}
+
+// link 邮件中的前端链接
+func (s *AccountService) link(path, token string) string {
+	return s.linkBase + path + "?token=" + url.QueryEscape(token)
+}
+
+func greetingName(user *model.User) string {
+	if user.Nickname != "" {
+		return " " + user.Nickname
+	}
+	return ""
+}
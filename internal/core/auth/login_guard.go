@@ -0,0 +1,234 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/logger"
+)
+
+// 登录防暴力破解默认参数
+const (
+	defaultFreeAttempts    = 3  // 账号连续失败多少次之后开始递增延迟
+	defaultMaxFailures     = 10 // 账号连续失败多少次后锁定
+	defaultIPFreeAttempts  = 10 // 同一 IP 连续失败多少次之后开始递增延迟
+	defaultIPMaxFailures   = 50 // 同一 IP 连续失败多少次后锁定
+	defaultBaseDelay       = 1  // 首次延迟（秒），之后每次失败翻倍
+	defaultMaxDelay        = 60 // 延迟上限（秒）
+	defaultLockoutMinutes  = 15 // 锁定时长（分钟）
+	defaultWindowMinutes   = 30 // 距上次失败超过该时间后重新计数（分钟）
+	throttleKeyEmailPrefix = "email:"
+	throttleKeyIPPrefix    = "ip:"
+)
+
+// LoginGuardConfig 登录防暴力破解配置
+type LoginGuardConfig struct {
+	FreeAttempts   int `mapstructure:"free_attempts"`
+	MaxFailures    int `mapstructure:"max_failures"`
+	IPFreeAttempts int `mapstructure:"ip_free_attempts"`
+	IPMaxFailures  int `mapstructure:"ip_max_failures"`
+	BaseDelay      int `mapstructure:"base_delay"`      // 秒
+	MaxDelay       int `mapstructure:"max_delay"`       // 秒
+	LockoutMinutes int `mapstructure:"lockout_minutes"` // 分钟
+	WindowMinutes  int `mapstructure:"window_minutes"`  // 分钟
+}
+
+// LoginAttemptInfo 一次登录尝试的上下文
+type LoginAttemptInfo struct {
+	UserID    int64
+	Email     string
+	IP        string
+	UserAgent string
+}
+
+// LoginGuard 按账号和 IP 统计连续失败次数：超过免费次数后要求等待递增的时间，超过上限后临时锁定
+type LoginGuard struct {
+	cfg  LoginGuardConfig
+	repo repository.LoginAttemptRepository
+}
+
+// NewLoginGuard 创建登录防护，未配置的参数使用默认值
+func NewLoginGuard(cfg LoginGuardConfig, repo repository.LoginAttemptRepository) *LoginGuard {
+	defaults := []struct {
+		v   *int
+		def int
+	}{
+		{&cfg.FreeAttempts, defaultFreeAttempts},
+		{&cfg.MaxFailures, defaultMaxFailures},
+		{&cfg.IPFreeAttempts, defaultIPFreeAttempts},
+		{&cfg.IPMaxFailures, defaultIPMaxFailures},
+		{&cfg.BaseDelay, defaultBaseDelay},
+		{&cfg.MaxDelay, defaultMaxDelay},
+		{&cfg.LockoutMinutes, defaultLockoutMinutes},
+		{&cfg.WindowMinutes, defaultWindowMinutes},
+	}
+	for _, d := range defaults {
+		if *d.v <= 0 {
+			*d.v = d.def
+		}
+	}
+	return &LoginGuard{cfg: cfg, repo: repo}
+}
+
+// throttleLimit 一个限流键的免费次数和锁定上限
+type throttleLimit struct {
+	key       string
+	free, max int
+}
+
+// Reserve 验证密码前检查账号和 IP 是否允许尝试登录：允许时先把本次尝试计为一次失败（登录成功后退还），
+// 不允许时返回需要等待的时间且不计数。检查和计数在同一事务中锁定限流记录完成，并发请求不能同时通过检查
+func (g *LoginGuard) Reserve(ctx context.Context, info LoginAttemptInfo) (time.Duration, error) {
+	limits := g.limits(info)
+	keys := make([]string, len(limits))
+	for i, l := range limits {
+		keys[i] = l.key
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	err := g.repo.LockThrottles(ctx, keys, func(throttles []*model.LoginThrottle) (bool, error) {
+		for i, throttle := range throttles {
+			if g.expired(throttle, now) {
+				throttle.Failures = 0
+				throttle.LockedUntil = nil
+				continue
+			}
+			if throttle.LockedUntil != nil && now.Before(*throttle.LockedUntil) {
+				wait = maxDuration(wait, throttle.LockedUntil.Sub(now))
+				continue
+			}
+			if throttle.Failures >= limits[i].free {
+				ready := throttle.LastFailureAt.Add(g.delay(throttle.Failures - limits[i].free))
+				if now.Before(ready) {
+					wait = maxDuration(wait, ready.Sub(now))
+				}
+			}
+		}
+		if wait > 0 {
+			return false, nil
+		}
+
+		for i, throttle := range throttles {
+			throttle.Failures++
+			throttle.LastFailureAt = now
+			if throttle.Failures >= limits[i].max {
+				until := now.Add(time.Duration(g.cfg.LockoutMinutes) * time.Minute)
+				throttle.LockedUntil = &until
+				logger.Warn("[Login] %s locked until %s after %d attempts", throttle.Key, until.Format(time.RFC3339), throttle.Failures)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return wait, nil
+}
+
+// Fail 记录一次失败登录（失败次数已在 Reserve 中计入）
+func (g *LoginGuard) Fail(ctx context.Context, info LoginAttemptInfo, result string) error {
+	return g.record(ctx, info, false, result)
+}
+
+// Succeed 记录一次成功登录：清除账号的失败计数，退还 IP 预先计入的次数（IP 已有的失败计数保留，避免用自己的账号刷新计数）
+func (g *LoginGuard) Succeed(ctx context.Context, info LoginAttemptInfo) error {
+	if err := g.record(ctx, info, true, model.LoginResultSuccess); err != nil {
+		return err
+	}
+	if _, err := g.repo.DeleteThrottle(ctx, EmailThrottleKey(info.Email)); err != nil {
+		return err
+	}
+	return g.repo.RefundThrottle(ctx, IPThrottleKey(info.IP), g.cfg.IPMaxFailures)
+}
+
+// Release 密码正确但拒绝登录（账号被禁用、邮箱未验证等）：只记录结果，退还预先计入的失败次数
+func (g *LoginGuard) Release(ctx context.Context, info LoginAttemptInfo, result string) error {
+	if err := g.record(ctx, info, false, result); err != nil {
+		return err
+	}
+	for _, l := range g.limits(info) {
+		if err := g.repo.RefundThrottle(ctx, l.key, l.max); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Record 只记录登录结果，不影响失败计数（被限流等）
+func (g *LoginGuard) Record(ctx context.Context, info LoginAttemptInfo, result string) error {
+	return g.record(ctx, info, false, result)
+}
+
+// Unlock 管理员解锁账号或 IP，返回是否存在需要清除的记录
+func (g *LoginGuard) Unlock(ctx context.Context, key string) (bool, error) {
+	unlocked, err := g.repo.DeleteThrottle(ctx, key)
+	if err == nil && unlocked {
+		logger.Info("[Login] %s unlocked", key)
+	}
+	return unlocked, err
+}
+
+// EmailThrottleKey 账号限流键
+func EmailThrottleKey(email string) string {
+	return throttleKeyEmailPrefix + NormalizeEmail(email)
+}
+
+// IPThrottleKey IP 限流键
+func IPThrottleKey(ip string) string {
+	return throttleKeyIPPrefix + ip
+}
+
+// NormalizeEmail 邮箱统一转小写去空格，避免大小写变体绕过计数
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (g *LoginGuard) limits(info LoginAttemptInfo) []throttleLimit {
+	return []throttleLimit{
+		{EmailThrottleKey(info.Email), g.cfg.FreeAttempts, g.cfg.MaxFailures},
+		{IPThrottleKey(info.IP), g.cfg.IPFreeAttempts, g.cfg.IPMaxFailures},
+	}
+}
+
+func (g *LoginGuard) record(ctx context.Context, info LoginAttemptInfo, success bool, result string) error {
+	return g.repo.Create(ctx, &model.LoginAttempt{
+		UserID:    info.UserID,
+		Email:     NormalizeEmail(info.Email),
+		IP:        info.IP,
+		UserAgent: truncate(info.UserAgent, 500),
+		Success:   success,
+		Result:    result,
+	})
+}
+
+// expired 距上次失败已超过统计窗口且未处于锁定中，计数作废
+func (g *LoginGuard) expired(t *model.LoginThrottle, now time.Time) bool {
+	if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+		return false
+	}
+	return now.Sub(t.LastFailureAt) > time.Duration(g.cfg.WindowMinutes)*time.Minute
+}
+
+// delay 超过免费次数后第 n 次（从 0 开始）失败需要等待的时间
+func (g *LoginGuard) delay(n int) time.Duration {
+	d := time.Duration(g.cfg.BaseDelay) * time.Second
+	limit := time.Duration(g.cfg.MaxDelay) * time.Second
+	for i := 0; i < n && d < limit; i++ {
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+	return d
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"copycat/internal/model"
+	"copycat/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// PersonalTokenPrefix 个人访问令牌前缀，用于和 JWT 区分
+const PersonalTokenPrefix = "ccp_"
+
+const (
+	personalTokenBytes = 32
+	displayPrefixLen   = 12          // 列表中展示的令牌前缀长度
+	lastUsedInterval   = time.Minute // 最后使用时间的最小更新间隔，避免每个请求都写库
+)
+
+// IsPersonalToken 是否为个人访问令牌（否则按 JWT 处理）
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
+
+// PersonalTokenService 个人访问令牌的签发和校验
+type PersonalTokenService struct {
+	users  repository.UserRepository
+	tokens repository.PersonalAccessTokenRepository
+}
+
+// NewPersonalTokenService 创建个人访问令牌服务
+func NewPersonalTokenService(users repository.UserRepository, tokens repository.PersonalAccessTokenRepository) *PersonalTokenService {
+	return &PersonalTokenService{users: users, tokens: tokens}
+}
+
+// Create 创建个人访问令牌，返回令牌记录和只展示一次的明文
+func (s *PersonalTokenService) Create(ctx context.Context, userID int64, name string, scopes []string, expiresAt *time.Time) (*model.PersonalAccessToken, string, error) {
+	raw := make([]byte, personalTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("failed to generate personal access token: %w", err)
+	}
+	plain := PersonalTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	token := &model.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plain[:displayPrefixLen],
+		TokenHash: hashToken(plain),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.tokens.Create(ctx, token); err != nil {
+		return nil, "", err
+	}
+	return token, plain, nil
+}
+
+// Authenticate 校验个人访问令牌，已吊销、已过期或用户不存在时返回 ErrInvalidToken，账号被禁用时返回 ErrAccountDisabled
+func (s *PersonalTokenService) Authenticate(ctx context.Context, plain string) (*model.PersonalAccessToken, error) {
+	token, err := s.tokens.GetByHash(ctx, hashToken(plain))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if token.RevokedAt != nil || (token.ExpiresAt != nil && now.After(*token.ExpiresAt)) {
+		return nil, ErrInvalidToken
+	}
+	user, err := s.users.GetByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > lastUsedInterval {
+		// 记录最后使用时间失败不影响本次请求
+		if err := s.tokens.TouchLastUsed(ctx, token.ID, now); err == nil {
+			token.LastUsedAt = &now
+		}
+	}
+	return token, nil
+}
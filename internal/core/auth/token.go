@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"copycat/internal/model"
+	"copycat/internal/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 默认令牌有效期
+const (
+	defaultAccessExpireMinutes = 15 // 访问令牌有效期（分钟）
+	defaultRefreshExpireDays   = 30 // 刷新令牌有效期（天）
+	refreshTokenBytes          = 32
+)
+
+// 令牌校验失败原因
+var (
+	ErrInvalidToken    = errors.New("invalid or expired token")
+	ErrTokenReused     = errors.New("refresh token reuse detected")
+	ErrAccountDisabled = errors.New("account has been disabled")
+)
+
+// Config JWT 配置
+type Config struct {
+	Secret              string `mapstructure:"secret"`
+	AccessExpireMinutes int    `mapstructure:"access_expire_minutes"` // 访问令牌有效期（分钟）
+	RefreshExpireDays   int    `mapstructure:"refresh_expire_days"`   // 刷新令牌有效期（天）
+}
+
+// TokenPair 登录或刷新后下发的令牌
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	RefreshToken     string    `json:"refresh_token"`
+	ExpiresIn        int64     `json:"expires_in"` // 访问令牌剩余有效期（秒）
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// ClientInfo 签发刷新令牌时记录的客户端信息
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// accessClaims 访问令牌声明，ver 与用户的令牌版本不一致时令牌失效
+type accessClaims struct {
+	UserID  int64 `json:"user_id"`
+	Version int   `json:"ver"`
+	jwt.RegisteredClaims
+}
+
+// TokenService 签发短期访问令牌（HS256 JWT）和服务端保存的轮换刷新令牌
+type TokenService struct {
+	cfg    Config
+	users  repository.UserRepository
+	tokens repository.RefreshTokenRepository
+}
+
+// NewTokenService 创建令牌服务，未配置的有效期使用默认值
+func NewTokenService(cfg Config, users repository.UserRepository, tokens repository.RefreshTokenRepository) *TokenService {
+	if cfg.AccessExpireMinutes <= 0 {
+		cfg.AccessExpireMinutes = defaultAccessExpireMinutes
+	}
+	if cfg.RefreshExpireDays <= 0 {
+		cfg.RefreshExpireDays = defaultRefreshExpireDays
+	}
+	return &TokenService{cfg: cfg, users: users, tokens: tokens}
+}
+
+// Issue 登录成功后签发一组新令牌（新的刷新令牌家族）
+func (s *TokenService) Issue(ctx context.Context, user *model.User, client ClientInfo) (*TokenPair, error) {
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+	// 顺带清理该用户已过期的刷新令牌，失败不影响登录
+	_ = s.tokens.DeleteExpired(ctx, user.ID)
+
+	pair, record, err := s.newPair(user, uuid.NewString(), client)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.Create(ctx, record); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Refresh 用刷新令牌换取新令牌，旧刷新令牌随即作废。已作废的令牌被再次使用时吊销整个家族
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*TokenPair, error) {
+	record, err := s.tokens.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if record.RevokedAt != nil {
+		if err := s.tokens.RevokeFamily(ctx, record.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTokenReused
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.users.GetByID(ctx, record.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if user.TokenVersion != record.TokenVersion {
+		return nil, ErrInvalidToken
+	}
+	if user.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
+	pair, next, err := s.newPair(user, record.FamilyID, client)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.Rotate(ctx, record, next); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenUsed) {
+			// 并发刷新时另一个请求已完成轮换，按重放处理
+			if err := s.tokens.RevokeFamily(ctx, record.FamilyID); err != nil {
+				return nil, err
+			}
+			return nil, ErrTokenReused
+		}
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Revoke 退出登录：作废该刷新令牌所在的家族（令牌不属于该用户时忽略）
+func (s *TokenService) Revoke(ctx context.Context, userID int64, refreshToken string) error {
+	record, err := s.tokens.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if record.UserID != userID {
+		return nil
+	}
+	return s.tokens.RevokeFamily(ctx, record.FamilyID)
+}
+
+// RevokeAll 使用户在所有设备上的令牌失效：递增令牌版本（已签发的访问令牌立即失效）并作废全部刷新令牌
+func (s *TokenService) RevokeAll(ctx context.Context, userID int64) error {
+	if err := s.users.IncrementTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+	return s.tokens.RevokeAllByUserID(ctx, userID)
+}
+
+// Authenticate 校验访问令牌并返回用户 ID，令牌版本落后于用户当前版本时视为已吊销，账号被禁用时返回 ErrAccountDisabled
+func (s *TokenService) Authenticate(ctx context.Context, accessToken string) (int64, error) {
+	claims := &accessClaims{}
+	token, err := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(s.cfg.Secret), nil
+	})
+	if err != nil || !token.Valid || claims.UserID == 0 {
+		return 0, ErrInvalidToken
+	}
+
+	user, err := s.users.GetByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrInvalidToken
+		}
+		return 0, err
+	}
+	if user.TokenVersion != claims.Version {
+		return 0, ErrInvalidToken
+	}
+	if user.Disabled {
+		return 0, ErrAccountDisabled
+	}
+	return user.ID, nil
+}
+
+// newPair 生成访问令牌和刷新令牌，返回待保存的刷新令牌记录
+func (s *TokenService) newPair(user *model.User, familyID string, client ClientInfo) (*TokenPair, *model.RefreshToken, error) {
+	now := time.Now()
+	accessTTL := time.Duration(s.cfg.AccessExpireMinutes) * time.Minute
+	access := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims{
+		UserID:  user.ID,
+		Version: user.TokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(accessTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	accessToken, err := access.SignedString([]byte(s.cfg.Secret))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	raw := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+	expiresAt := now.AddDate(0, 0, s.cfg.RefreshExpireDays)
+
+	record := &model.RefreshToken{
+		UserID:       user.ID,
+		TokenHash:    hashToken(refreshToken),
+		FamilyID:     familyID,
+		TokenVersion: user.TokenVersion,
+		UserAgent:    truncate(client.UserAgent, 500),
+		ClientIP:     truncate(client.IP, 64),
+		ExpiresAt:    expiresAt,
+	}
+	pair := &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(accessTTL.Seconds()),
+		RefreshExpiresAt: expiresAt,
+	}
+	return pair, record, nil
+}
+
+// hashToken 刷新令牌只以 SHA-256 形式落库
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
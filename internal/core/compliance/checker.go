@@ -0,0 +1,235 @@
+package compliance
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Checker 合规检查器（构建后只读，可并发使用）
+type Checker struct {
+	version  int
+	words    []compiledWord
+	patterns []compiledPattern
+}
+
+type compiledWord struct {
+	word []rune // 已转小写
+	rule *WordRule
+}
+
+type compiledPattern struct {
+	re   *regexp.Regexp
+	rule *PatternRule
+}
+
+// Issue 单个命中项（偏移量为原文中的 rune 下标，左闭右开）
+type Issue struct {
+	Start       int      `json:"start"`
+	End         int      `json:"end"`
+	Text        string   `json:"text"`        // 命中的原文
+	Category    string   `json:"category"`    // 违规类别
+	Severity    string   `json:"severity"`    // 严重程度
+	Message     string   `json:"message"`     // 说明
+	Suggestions []string `json:"suggestions"` // 替换建议，为空表示建议删除
+}
+
+// Result 检查结果
+type Result struct {
+	Passed         bool           `json:"passed"`          // 没有 high 级别问题即视为通过
+	Platform       string         `json:"platform"`        // 检查所用平台，为空表示通用规则
+	LexiconVersion int            `json:"lexicon_version"` // 词库版本
+	Counts         map[string]int `json:"counts"`          // 各严重程度的命中数
+	Issues         []Issue        `json:"issues"`          // 命中项，按出现位置排序
+	Suggested      string         `json:"suggested"`       // 按第一条建议替换后的文本
+}
+
+// Check 使用当前生效的词库检查文本
+func Check(text, platform string) *Result {
+	return Default().Check(text, platform)
+}
+
+// Check 检查文本中的违禁词和联系方式，platform 为空时应用全部规则
+func (c *Checker) Check(text, platform string) *Result {
+	original := []rune(text)
+	lower := []rune(toLower(text))
+
+	var issues []Issue
+	for _, w := range c.words {
+		if !appliesTo(w.rule.Platforms, platform) {
+			continue
+		}
+		for _, start := range indexAll(lower, w.word) {
+			end := start + len(w.word)
+			if isException(lower, start, end, w.rule.Exceptions) {
+				continue
+			}
+			issues = append(issues, Issue{
+				Start:       start,
+				End:         end,
+				Text:        string(original[start:end]),
+				Category:    w.rule.Category,
+				Severity:    w.rule.Severity,
+				Message:     w.rule.Message,
+				Suggestions: nonNil(w.rule.Suggestions),
+			})
+		}
+	}
+
+	for _, p := range c.patterns {
+		if !appliesTo(p.rule.Platforms, platform) {
+			continue
+		}
+		for _, loc := range p.re.FindAllStringIndex(text, -1) {
+			start := utf8.RuneCountInString(text[:loc[0]])
+			end := start + utf8.RuneCountInString(text[loc[0]:loc[1]])
+			issues = append(issues, Issue{
+				Start:       start,
+				End:         end,
+				Text:        text[loc[0]:loc[1]],
+				Category:    p.rule.Category,
+				Severity:    p.rule.Severity,
+				Message:     p.rule.Message,
+				Suggestions: nonNil(p.rule.Suggestions),
+			})
+		}
+	}
+
+	issues = removeOverlaps(issues)
+
+	result := &Result{
+		Passed:         true,
+		Platform:       platform,
+		LexiconVersion: c.version,
+		Counts:         map[string]int{SeverityHigh: 0, SeverityMedium: 0, SeverityLow: 0},
+		Issues:         issues,
+		Suggested:      applySuggestions(original, issues),
+	}
+	for _, issue := range issues {
+		result.Counts[issue.Severity]++
+		if issue.Severity == SeverityHigh {
+			result.Passed = false
+		}
+	}
+	return result
+}
+
+// removeOverlaps 重叠的命中只保留更长的一个（如 "最好" 优先于 "最"），同长度时保留更严重的
+func removeOverlaps(issues []Issue) []Issue {
+	sort.SliceStable(issues, func(i, j int) bool {
+		li, lj := issues[i].End-issues[i].Start, issues[j].End-issues[j].Start
+		if li != lj {
+			return li > lj
+		}
+		return severityRank(issues[i].Severity) > severityRank(issues[j].Severity)
+	})
+
+	kept := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		overlapped := false
+		for _, k := range kept {
+			if issue.Start < k.End && k.Start < issue.End {
+				overlapped = true
+				break
+			}
+		}
+		if !overlapped {
+			kept = append(kept, issue)
+		}
+	}
+
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Start < kept[j].Start })
+	return kept
+}
+
+// applySuggestions 用每个命中项的第一条建议替换原文，没有建议的直接删除
+func applySuggestions(original []rune, issues []Issue) string {
+	var b strings.Builder
+	last := 0
+	for _, issue := range issues {
+		b.WriteString(string(original[last:issue.Start]))
+		if len(issue.Suggestions) > 0 {
+			b.WriteString(issue.Suggestions[0])
+		}
+		last = issue.End
+	}
+	b.WriteString(string(original[last:]))
+	return b.String()
+}
+
+// isException 判断命中位置是否落在某个例外词内部
+func isException(text []rune, start, end int, exceptions []string) bool {
+	word := string(text[start:end])
+	for _, ex := range exceptions {
+		exRunes := []rune(toLower(ex))
+		offset := strings.Index(string(exRunes), word)
+		if offset < 0 {
+			continue
+		}
+		exStart := start - utf8.RuneCountInString(string(exRunes)[:offset])
+		exEnd := exStart + len(exRunes)
+		if exStart >= 0 && exEnd <= len(text) && string(text[exStart:exEnd]) == string(exRunes) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexAll 返回 word 在 text 中所有出现位置（rune 下标）
+func indexAll(text, word []rune) []int {
+	var positions []int
+	if len(word) == 0 {
+		return positions
+	}
+	for i := 0; i+len(word) <= len(text); i++ {
+		match := true
+		for j := range word {
+			if text[i+j] != word[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+func appliesTo(platforms []string, platform string) bool {
+	if len(platforms) == 0 || platform == "" {
+		return true
+	}
+	for _, p := range platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	}
+	return 0
+}
+
+// toLower 逐字符转小写，保证转换前后 rune 数量一致，偏移量可直接对应原文
+func toLower(s string) string {
+	return strings.Map(unicode.ToLower, s)
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,191 @@
+package compliance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testChecker(t *testing.T) *Checker {
+	t.Helper()
+	checker, err := NewChecker(&Lexicon{
+		Version: 1,
+		Rules: []WordRule{
+			{Words: []string{"最", "最好"}, Category: CategoryAdvertisingLaw, Severity: SeverityHigh, Suggestions: []string{"很"}, Exceptions: []string{"最近"}},
+			{Words: []string{"NO.1"}, Category: CategoryAdvertisingLaw, Severity: SeverityMedium},
+			{Words: []string{"私信"}, Category: CategoryDiversion, Severity: SeverityLow, Platforms: []string{"douyin"}},
+		},
+		Patterns: []PatternRule{
+			{Name: "phone", Pattern: `1[3-9]\d{9}`, Category: CategoryContactInfo, Severity: SeverityHigh},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewChecker: %v", err)
+	}
+	return checker
+}
+
+// span 命中项的位置和原文
+type span struct {
+	Start, End int
+	Text       string
+}
+
+func spans(issues []Issue) []span {
+	s := make([]span, 0, len(issues))
+	for _, issue := range issues {
+		s = append(s, span{issue.Start, issue.End, issue.Text})
+	}
+	return s
+}
+
+func TestCheckerCheck(t *testing.T) {
+	checker := testChecker(t)
+
+	tests := []struct {
+		name      string
+		text      string
+		platform  string
+		want      []span
+		passed    bool
+		suggested string
+	}{
+		{
+			name:      "长词优先于重叠的短词",
+			text:      "这是最好的产品",
+			want:      []span{{2, 4, "最好"}},
+			suggested: "这是很的产品",
+		},
+		{
+			name:      "例外词不命中",
+			text:      "最近很忙",
+			want:      []span{},
+			passed:    true,
+			suggested: "最近很忙",
+		},
+		{
+			name:      "表情按一个 rune 计算偏移",
+			text:      "😀最好",
+			want:      []span{{1, 3, "最好"}},
+			suggested: "😀很",
+		},
+		{
+			name:      "忽略大小写并保留原文",
+			text:      "全网No.1",
+			want:      []span{{2, 6, "No.1"}},
+			passed:    true,
+			suggested: "全网",
+		},
+		{
+			name:      "正则命中的字节偏移转为 rune 偏移",
+			text:      "电话：13812345678，最",
+			want:      []span{{3, 14, "13812345678"}, {15, 16, "最"}},
+			suggested: "电话：，很",
+		},
+		{
+			name:      "平台规则不适用",
+			text:      "私信我",
+			platform:  "xiaohongshu",
+			want:      []span{},
+			passed:    true,
+			suggested: "私信我",
+		},
+		{
+			name:      "平台规则适用",
+			text:      "私信我",
+			platform:  "douyin",
+			want:      []span{{0, 2, "私信"}},
+			passed:    true,
+			suggested: "我",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checker.Check(tt.text, tt.platform)
+			if got := spans(result.Issues); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("issues = %+v, want %+v", got, tt.want)
+			}
+			if result.Passed != tt.passed {
+				t.Errorf("Passed = %v, want %v", result.Passed, tt.passed)
+			}
+			if result.Suggested != tt.suggested {
+				t.Errorf("Suggested = %q, want %q", result.Suggested, tt.suggested)
+			}
+		})
+	}
+}
+
+func TestRemoveOverlaps(t *testing.T) {
+	tests := []struct {
+		name   string
+		issues []Issue
+		want   []Issue
+	}{
+		{
+			name: "保留更长的命中",
+			issues: []Issue{
+				{Start: 2, End: 3, Severity: SeverityHigh},
+				{Start: 2, End: 4, Severity: SeverityLow},
+			},
+			want: []Issue{{Start: 2, End: 4, Severity: SeverityLow}},
+		},
+		{
+			name: "同长度保留更严重的",
+			issues: []Issue{
+				{Start: 0, End: 2, Severity: SeverityLow},
+				{Start: 1, End: 3, Severity: SeverityHigh},
+			},
+			want: []Issue{{Start: 1, End: 3, Severity: SeverityHigh}},
+		},
+		{
+			name: "相邻不算重叠并按位置排序",
+			issues: []Issue{
+				{Start: 5, End: 7, Severity: SeverityMedium},
+				{Start: 0, End: 5, Severity: SeverityLow},
+			},
+			want: []Issue{
+				{Start: 0, End: 5, Severity: SeverityLow},
+				{Start: 5, End: 7, Severity: SeverityMedium},
+			},
+		},
+		{
+			name:   "空",
+			issues: nil,
+			want:   []Issue{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := removeOverlaps(tt.issues); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("removeOverlaps = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLexicon(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "有效词库", data: `{"version":2,"rules":[{"words":["最"],"severity":"high"}]}`},
+		{name: "未知严重程度", data: `{"rules":[{"words":["最"],"severity":"fatal"}]}`, wantErr: true},
+		{name: "无效正则", data: `{"patterns":[{"name":"bad","pattern":"(","severity":"low"}]}`, wantErr: true},
+		{name: "无效 JSON", data: `{`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseLexicon([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseLexicon error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := ParseLexicon(defaultLexicon); err != nil {
+		t.Errorf("default lexicon: %v", err)
+	}
+}
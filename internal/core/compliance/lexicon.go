@@ -0,0 +1,153 @@
+package compliance
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"copycat/pkg/logger"
+)
+
+// LexiconFile 外部词库文件路径；存在时覆盖内置词库，修改后无需重启即可生效
+var LexiconFile = "lexicon/compliance.json"
+
+//go:embed default_lexicon.json
+var defaultLexicon []byte
+
+// 严重程度
+const (
+	SeverityHigh   = "high"   // 大概率限流或违规，发布前必须修改
+	SeverityMedium = "medium" // 存在风险，建议修改
+	SeverityLow    = "low"    // 轻微风险，仅提示
+)
+
+// 违规类别
+const (
+	CategoryAdvertisingLaw = "advertising_law" // 广告法极限词
+	CategoryMedicalClaim   = "medical_claim"   // 医疗功效宣称
+	CategoryContactInfo    = "contact_info"    // 站外联系方式
+	CategoryDiversion      = "diversion"       // 导流用语
+	CategoryEngagementBait = "engagement_bait" // 诱导互动
+)
+
+// Lexicon 词库文件结构
+type Lexicon struct {
+	Version  int           `json:"version"`
+	Rules    []WordRule    `json:"rules"`    // 关键词规则
+	Patterns []PatternRule `json:"patterns"` // 正则规则（手机号、微信号等）
+}
+
+// WordRule 一组共享类别、严重程度和替换建议的关键词
+type WordRule struct {
+	Words       []string `json:"words"`
+	Category    string   `json:"category"`
+	Severity    string   `json:"severity"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions"`
+	Exceptions  []string `json:"exceptions"` // 包含关键词但不违规的常用词，如 "最近" 之于 "最"
+	Platforms   []string `json:"platforms"`  // 仅对这些平台生效，为空表示全部平台
+}
+
+// PatternRule 正则匹配规则
+type PatternRule struct {
+	Name        string   `json:"name"`
+	Pattern     string   `json:"pattern"`
+	Category    string   `json:"category"`
+	Severity    string   `json:"severity"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions"` // 为空时建议直接删除
+	Platforms   []string `json:"platforms"`
+}
+
+// ParseLexicon 解析词库并编译为检查器
+func ParseLexicon(data []byte) (*Checker, error) {
+	var lexicon Lexicon
+	if err := json.Unmarshal(data, &lexicon); err != nil {
+		return nil, fmt.Errorf("failed to parse lexicon: %w", err)
+	}
+	return NewChecker(&lexicon)
+}
+
+// NewChecker 根据词库构建检查器
+func NewChecker(lexicon *Lexicon) (*Checker, error) {
+	checker := &Checker{version: lexicon.Version}
+
+	for i := range lexicon.Rules {
+		rule := &lexicon.Rules[i]
+		if err := validateSeverity(rule.Severity); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		for _, word := range rule.Words {
+			if word == "" {
+				continue
+			}
+			checker.words = append(checker.words, compiledWord{word: []rune(toLower(word)), rule: rule})
+		}
+	}
+
+	for i := range lexicon.Patterns {
+		rule := &lexicon.Patterns[i]
+		if err := validateSeverity(rule.Severity); err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", rule.Name, err)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile pattern %s: %w", rule.Name, err)
+		}
+		checker.patterns = append(checker.patterns, compiledPattern{re: re, rule: rule})
+	}
+
+	return checker, nil
+}
+
+func validateSeverity(severity string) error {
+	switch severity {
+	case SeverityHigh, SeverityMedium, SeverityLow:
+		return nil
+	}
+	return fmt.Errorf("unknown severity %q", severity)
+}
+
+var (
+	defaultMu      sync.Mutex
+	defaultChecker *Checker
+	defaultModTime time.Time // 已加载外部词库的修改时间，零值表示使用内置词库
+)
+
+// Default 返回当前生效的检查器；外部词库文件变更后自动重新加载，加载失败时保留上一个可用版本
+func Default() *Checker {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	info, statErr := os.Stat(LexiconFile)
+	if statErr == nil && !info.ModTime().Equal(defaultModTime) {
+		data, err := os.ReadFile(LexiconFile)
+		if err == nil {
+			var checker *Checker
+			if checker, err = ParseLexicon(data); err == nil {
+				logger.LLMInfo("[Compliance] 已加载词库文件: %s (%d 个关键词, %d 条正则)", LexiconFile, len(checker.words), len(checker.patterns))
+				defaultChecker = checker
+				defaultModTime = info.ModTime()
+				return defaultChecker
+			}
+		}
+		logger.LLMWarn("[Compliance] 加载词库文件失败: %v，继续使用当前词库", err)
+		// 记录修改时间，避免每次请求都重复解析同一个错误文件
+		defaultModTime = info.ModTime()
+	}
+
+	if defaultChecker == nil || (statErr != nil && !defaultModTime.IsZero()) {
+		checker, err := ParseLexicon(defaultLexicon)
+		if err != nil {
+			// 内置词库随代码发布，解析失败属于编码错误
+			panic(err)
+		}
+		defaultChecker = checker
+		defaultModTime = time.Time{}
+	}
+	return defaultChecker
+}
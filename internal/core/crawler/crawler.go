@@ -37,7 +37,7 @@ func (m *CrawlerManager) Register(crawler Crawler) {
 
 // Crawl 根据 URL 自动选择爬虫进行爬取
 func (m *CrawlerManager) Crawl(ctx context.Context, url string) (*CrawlResult, error) {
-	platform := m.detectPlatform(url)
+	platform := DetectPlatform(url)
 	if platform == PlatformUnknown {
 		return &CrawlResult{
 			Success:  false,
@@ -58,8 +58,8 @@ func (m *CrawlerManager) Crawl(ctx context.Context, url string) (*CrawlResult, e
 	return crawler.Crawl(ctx, url)
 }
 
-// detectPlatform 根据 URL 检测平台
-func (m *CrawlerManager) detectPlatform(url string) Platform {
+// DetectPlatform 根据 URL 检测平台
+func DetectPlatform(url string) Platform {
 	url = strings.ToLower(url)
 
 	if strings.Contains(url, "xiaohongshu.com") || strings.Contains(url, "xhslink.com") {
@@ -37,6 +37,10 @@ type NoteContent struct {
 	Images []string `json:"images"` // 图片列表
 	Video  *Video   `json:"video,omitempty"`
 
+	// 本地缓存的代理地址（爬取后由媒体缓存填充，与 Images 一一对应，缓存失败的为空）
+	CachedCover  string   `json:"cached_cover,omitempty"`
+	CachedImages []string `json:"cached_images,omitempty"`
+
 	// 标签
 	Tags []string `json:"tags"`
 
@@ -0,0 +1,218 @@
+package imagegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"copycat/pkg/logger"
+)
+
+// 阿里云百炼（通义万相）文生图 API 配置
+const (
+	DashScopeImageAPIURL = "https://dashscope.aliyuncs.com/api/v1/services/aigc/text2image/image-synthesis"
+	DashScopeTaskAPIURL  = "https://dashscope.aliyuncs.com/api/v1/tasks/"
+
+	dashScopePollInterval = 2 * time.Second // 任务轮询间隔
+	dashScopeTaskTimeout  = 3 * time.Minute // 单个任务最长等待时间
+)
+
+// DashScopeModels 通义万相文生图模型
+var DashScopeModels = []Model{
+	{ID: "wanx2.1-t2i-turbo", Name: "通义万相2.1-Turbo", Description: "生成速度快，性价比高"},
+	{ID: "wanx2.1-t2i-plus", Name: "通义万相2.1-Plus", Description: "细节更丰富，速度较慢"},
+	{ID: "wanx-v1", Name: "通义万相", Description: "旧版模型"},
+}
+
+// dashScopeSizes 通义万相可用尺寸
+var dashScopeSizes = [][2]int{{768, 1024}, {1024, 1024}, {720, 1280}, {1024, 768}, {1280, 720}}
+
+// DashScopeProvider 通义万相文生图服务（异步任务接口）
+type DashScopeProvider struct {
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewDashScopeProvider 创建通义万相图像生成服务商
+func NewDashScopeProvider(apiKey string) *DashScopeProvider {
+	return &DashScopeProvider{
+		apiKey: apiKey,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// dashScopeImageRequest 文生图请求结构
+type dashScopeImageRequest struct {
+	Model string `json:"model"`
+	Input struct {
+		Prompt         string `json:"prompt"`
+		NegativePrompt string `json:"negative_prompt,omitempty"`
+	} `json:"input"`
+	Parameters struct {
+		Size string `json:"size"`
+		N    int    `json:"n"`
+	} `json:"parameters"`
+}
+
+// dashScopeTaskResponse 创建任务与查询任务的响应结构
+type dashScopeTaskResponse struct {
+	Output struct {
+		TaskID     string `json:"task_id"`
+		TaskStatus string `json:"task_status"` // PENDING/RUNNING/SUCCEEDED/FAILED/UNKNOWN
+		Code       string `json:"code"`
+		Message    string `json:"message"`
+		Results    []struct {
+			URL          string `json:"url"`
+			ActualPrompt string `json:"actual_prompt"`
+			Code         string `json:"code"`
+			Message      string `json:"message"`
+		} `json:"results"`
+	} `json:"output"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+// Name 服务商标识
+func (p *DashScopeProvider) Name() string { return ProviderDashScope }
+
+// Capabilities 服务商能力
+func (p *DashScopeProvider) Capabilities() Capabilities {
+	return Capabilities{
+		NegativePrompt: true,
+		MaxCount:       4,
+		RequiresAPIKey: true,
+	}
+}
+
+// Models 可用模型
+func (p *DashScopeProvider) Models() []Model {
+	return DashScopeModels
+}
+
+// Generate 提交异步任务并轮询结果，任务成功后下载图片
+func (p *DashScopeProvider) Generate(req Request) ([]Image, error) {
+	if req.Model == "" {
+		req.Model = DashScopeModels[0].ID
+	}
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	width, height := nearestSize(req.Aspect, dashScopeSizes)
+
+	body := dashScopeImageRequest{Model: req.Model}
+	body.Input.Prompt = req.Prompt
+	body.Input.NegativePrompt = req.NegativePrompt
+	body.Parameters.Size = fmt.Sprintf("%d*%d", width, height)
+	body.Parameters.N = req.Count
+
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+	httpReq, err := http.NewRequest("POST", DashScopeImageAPIURL, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	httpReq.Header.Set("X-DashScope-Async", "enable")
+
+	logger.Info("图像生成请求: provider=dashscope, model=%s, size=%s, n=%d", req.Model, body.Parameters.Size, req.Count)
+
+	task, err := p.do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if task.Output.TaskID == "" {
+		return nil, fmt.Errorf("图像生成 API 未返回任务ID")
+	}
+
+	task, err = p.waitTask(task.Output.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]Image, 0, len(task.Output.Results))
+	for _, r := range task.Output.Results {
+		if r.URL == "" {
+			// 部分图片可能因内容审核失败，其余图片仍然返回
+			logger.Warn("通义万相单张图片生成失败: code=%s, message=%s", r.Code, r.Message)
+			continue
+		}
+		data, err := downloadImage(p.httpClient, r.URL)
+		if err != nil {
+			return nil, err
+		}
+		img, err := newImage(data, width, height, r.ActualPrompt)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("图像生成任务没有返回图片")
+	}
+	return images, nil
+}
+
+// waitTask 轮询任务状态直到成功、失败或超时
+func (p *DashScopeProvider) waitTask(taskID string) (*dashScopeTaskResponse, error) {
+	deadline := time.Now().Add(dashScopeTaskTimeout)
+	for {
+		time.Sleep(dashScopePollInterval)
+
+		httpReq, err := http.NewRequest("GET", DashScopeTaskAPIURL+taskID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("创建请求失败: %w", err)
+		}
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+		task, err := p.do(httpReq)
+		if err != nil {
+			return nil, err
+		}
+		switch task.Output.TaskStatus {
+		case "SUCCEEDED":
+			return task, nil
+		case "FAILED", "UNKNOWN":
+			logger.Error("通义万相任务失败: task_id=%s, code=%s, message=%s", taskID, task.Output.Code, task.Output.Message)
+			return nil, fmt.Errorf("图像生成任务失败: %s", task.Output.Message)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("图像生成任务超时: %s", taskID)
+		}
+	}
+}
+
+// do 发送请求并解析任务响应
+func (p *DashScopeProvider) do(httpReq *http.Request) (*dashScopeTaskResponse, error) {
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	var task dashScopeTaskResponse
+	if err := json.Unmarshal(respBody, &task); err != nil {
+		return nil, fmt.Errorf("解析响应失败: HTTP %d: %w", resp.StatusCode, err)
+	}
+	if task.Code != "" {
+		logger.Error("图像生成 API 错误: code=%s, message=%s, request_id=%s", task.Code, task.Message, task.RequestID)
+		return nil, fmt.Errorf("图像生成 API 错误: %s - %s", task.Code, task.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("图像生成 API 错误: HTTP %d", resp.StatusCode)
+	}
+	return &task, nil
+}
@@ -0,0 +1,268 @@
+package imagegen
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg" // 注册 JPEG 解码器，用于读取图片尺寸
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxImageBytes 单张图片最大字节数
+const maxImageBytes = 20 << 20
+
+// 图像生成服务商标识
+const (
+	ProviderDashScope = "dashscope" // 阿里云百炼（通义万相）
+	ProviderOpenAI    = "openai"    // OpenAI 及兼容 /images/generations 接口的服务
+	ProviderLocal     = "local"     // 本地占位图（离线开发/演示用）
+)
+
+// ProviderTypes 所有支持的服务商（按展示顺序）
+var ProviderTypes = []string{ProviderDashScope, ProviderOpenAI, ProviderLocal}
+
+// providerLabels 服务商展示名称
+var providerLabels = map[string]string{
+	ProviderDashScope: "阿里云百炼（通义万相）",
+	ProviderOpenAI:    "OpenAI 兼容接口",
+	ProviderLocal:     "本地占位图",
+}
+
+// ProviderLabel 服务商展示名称
+func ProviderLabel(name string) string {
+	if label, ok := providerLabels[name]; ok {
+		return label
+	}
+	return name
+}
+
+// IsValidProvider 验证服务商标识是否有效
+func IsValidProvider(name string) bool {
+	_, ok := providerLabels[name]
+	return ok
+}
+
+// 画面比例
+const (
+	Aspect3x4  = "3:4"  // 小红书封面/内页
+	Aspect1x1  = "1:1"  // 方图
+	Aspect9x16 = "9:16" // 竖屏视频封面
+	Aspect4x3  = "4:3"
+	Aspect16x9 = "16:9" // 公众号头图/横屏视频
+)
+
+// DefaultAspect 默认画面比例
+const DefaultAspect = Aspect3x4
+
+// Aspects 支持的画面比例
+var Aspects = []string{Aspect3x4, Aspect1x1, Aspect9x16, Aspect4x3, Aspect16x9}
+
+// IsValidAspect 验证画面比例是否有效
+func IsValidAspect(aspect string) bool {
+	for _, a := range Aspects {
+		if a == aspect {
+			return true
+		}
+	}
+	return false
+}
+
+// Model 图像生成模型信息
+type Model struct {
+	ID          string `json:"id"`          // 模型ID
+	Name        string `json:"name"`        // 模型名称
+	Description string `json:"description"` // 描述
+}
+
+// Capabilities 服务商能力说明
+type Capabilities struct {
+	NegativePrompt bool `json:"negative_prompt"`  // 是否支持反向提示词
+	MaxCount       int  `json:"max_count"`        // 单次请求最多生成张数
+	RequiresAPIKey bool `json:"requires_api_key"` // 是否需要 API Key
+	CustomBaseURL  bool `json:"custom_base_url"`  // 是否支持自定义接口地址
+	CustomModels   bool `json:"custom_models"`    // 是否接受列表之外的模型ID
+	Offline        bool `json:"offline"`          // 是否离线运行
+}
+
+// Request 单次生成请求
+type Request struct {
+	Prompt         string // 提示词
+	NegativePrompt string // 反向提示词（服务商不支持时忽略）
+	Model          string // 模型，为空时使用默认模型
+	Aspect         string // 画面比例，由服务商换算为最接近的可用尺寸
+	Count          int    // 生成张数
+}
+
+// Image 生成的图片
+type Image struct {
+	Data          []byte // 图片数据
+	Format        string // png/jpeg/webp
+	Width         int    // 宽度
+	Height        int    // 高度
+	RevisedPrompt string // 服务商改写后的提示词（可选）
+}
+
+// Provider 图像生成服务商
+type Provider interface {
+	// Name 服务商标识
+	Name() string
+	// Capabilities 服务商能力
+	Capabilities() Capabilities
+	// Models 可用模型，第一个为默认模型
+	Models() []Model
+	// Generate 按提示词生成图片
+	Generate(req Request) ([]Image, error)
+}
+
+// ProviderConfig 创建服务商所需的配置
+type ProviderConfig struct {
+	Type    string // 服务商标识，为空时使用 dashscope
+	APIKey  string // API Key
+	BaseURL string // 自定义接口地址（仅 OpenAI 兼容接口）
+}
+
+// NewProvider 按配置创建服务商
+func NewProvider(cfg ProviderConfig) (Provider, error) {
+	switch cfg.Type {
+	case "", ProviderDashScope:
+		return NewDashScopeProvider(cfg.APIKey), nil
+	case ProviderOpenAI:
+		return NewOpenAIProvider(cfg.APIKey, cfg.BaseURL), nil
+	case ProviderLocal:
+		return NewLocalProvider(), nil
+	}
+	return nil, fmt.Errorf("不支持的图像生成服务商: %s", cfg.Type)
+}
+
+// ResolveModel 校验模型，为空时使用服务商默认模型；兼容接口允许列表之外的模型ID
+func ResolveModel(p Provider, model string) (string, error) {
+	models := p.Models()
+	if len(models) == 0 {
+		return "", fmt.Errorf("%s没有可用的模型", ProviderLabel(p.Name()))
+	}
+	if model == "" {
+		return models[0].ID, nil
+	}
+	for _, m := range models {
+		if m.ID == model {
+			return model, nil
+		}
+	}
+	if p.Capabilities().CustomModels {
+		return model, nil
+	}
+	return "", fmt.Errorf("无效的模型: %s", model)
+}
+
+// BuildPrompt 将原图的生成提示词迁移到新主题：新主题作为画面主体，
+// 保留原图的场景、光线、构图和整体风格描述
+func BuildPrompt(topic, imagePrompt, overallStyle string) string {
+	var parts []string
+	if topic = strings.TrimSpace(topic); topic != "" {
+		parts = append(parts, "画面主题："+topic)
+	}
+	if imagePrompt = strings.TrimSpace(imagePrompt); imagePrompt != "" {
+		if topic != "" {
+			parts = append(parts, "参考原图的场景、光线、构图与质感（主体替换为上述主题）："+imagePrompt)
+		} else {
+			parts = append(parts, imagePrompt)
+		}
+	}
+	if overallStyle = strings.TrimSpace(overallStyle); overallStyle != "" {
+		parts = append(parts, "整体风格："+overallStyle)
+	}
+	return strings.Join(parts, "。")
+}
+
+// ContentType 图片格式对应的 MIME 类型
+func ContentType(format string) string {
+	switch format {
+	case "jpeg":
+		return "image/jpeg"
+	case "webp":
+		return "image/webp"
+	}
+	return "image/png"
+}
+
+// DetectFormat 根据文件头识别图片格式，无法识别时返回空字符串
+func DetectFormat(data []byte) string {
+	switch {
+	case len(data) >= 8 && string(data[:8]) == "\x89PNG\r\n\x1a\n":
+		return "png"
+	case len(data) >= 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF:
+		return "jpeg"
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "webp"
+	}
+	return ""
+}
+
+// aspectRatio 解析 "3:4" 形式的比例
+func aspectRatio(aspect string) (int, int) {
+	var w, h int
+	if _, err := fmt.Sscanf(aspect, "%d:%d", &w, &h); err != nil || w <= 0 || h <= 0 {
+		return 3, 4
+	}
+	return w, h
+}
+
+// nearestSize 从候选尺寸中选出宽高比最接近的一个
+func nearestSize(aspect string, sizes [][2]int) (int, int) {
+	w, h := aspectRatio(aspect)
+	target := float64(w) / float64(h)
+	best := sizes[0]
+	bestDiff := -1.0
+	for _, s := range sizes {
+		diff := float64(s[0])/float64(s[1]) - target
+		if diff < 0 {
+			diff = -diff
+		}
+		if bestDiff < 0 || diff < bestDiff {
+			best, bestDiff = s, diff
+		}
+	}
+	return best[0], best[1]
+}
+
+// imageSize 读取 PNG/JPEG 图片尺寸，无法识别时返回 0
+func imageSize(data []byte) (int, int) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0
+	}
+	return cfg.Width, cfg.Height
+}
+
+// downloadImage 下载服务商返回的临时图片地址
+func downloadImage(httpClient *http.Client, url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("下载图片失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载图片失败: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("读取图片数据失败: %w", err)
+	}
+	return data, nil
+}
+
+// newImage 识别格式和尺寸，尺寸无法识别时使用请求的尺寸
+func newImage(data []byte, width, height int, revisedPrompt string) (Image, error) {
+	format := DetectFormat(data)
+	if format == "" {
+		return Image{}, fmt.Errorf("无法识别的图片格式")
+	}
+	if w, h := imageSize(data); w > 0 {
+		width, height = w, h
+	}
+	return Image{Data: data, Format: format, Width: width, Height: height, RevisedPrompt: revisedPrompt}, nil
+}
@@ -0,0 +1,82 @@
+package imagegen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// localMaxSide 占位图长边像素
+const localMaxSide = 1024
+
+// LocalProvider 本地占位图：按提示词哈希生成渐变色块，用于离线开发和演示，不调用任何外部服务
+type LocalProvider struct{}
+
+// NewLocalProvider 创建本地占位图服务商
+func NewLocalProvider() *LocalProvider {
+	return &LocalProvider{}
+}
+
+// Name 服务商标识
+func (p *LocalProvider) Name() string { return ProviderLocal }
+
+// Capabilities 服务商能力
+func (p *LocalProvider) Capabilities() Capabilities {
+	return Capabilities{
+		MaxCount: 4,
+		Offline:  true,
+	}
+}
+
+// Models 可用模型
+func (p *LocalProvider) Models() []Model {
+	return []Model{{ID: "placeholder", Name: "占位图", Description: "按提示词生成固定配色的渐变图，同一提示词结果相同"}}
+}
+
+// Generate 生成 PNG 占位图，尺寸与请求的画面比例一致
+func (p *LocalProvider) Generate(req Request) ([]Image, error) {
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+	w, h := aspectRatio(req.Aspect)
+	width, height := localMaxSide, localMaxSide
+	if w > h {
+		height = localMaxSide * h / w
+	} else {
+		width = localMaxSide * w / h
+	}
+
+	images := make([]Image, 0, req.Count)
+	for i := 0; i < req.Count; i++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s#%d", req.Prompt, i)))
+		from := color.RGBA{sum[0], sum[1], sum[2], 255}
+		to := color.RGBA{sum[3], sum[4], sum[5], 255}
+
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				t := float64(x+y) / float64(width+height-2)
+				img.Set(x, y, color.RGBA{
+					R: lerp(from.R, to.R, t),
+					G: lerp(from.G, to.G, t),
+					B: lerp(from.B, to.B, t),
+					A: 255,
+				})
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, fmt.Errorf("编码占位图失败: %w", err)
+		}
+		images = append(images, Image{Data: buf.Bytes(), Format: "png", Width: width, Height: height})
+	}
+	return images, nil
+}
+
+func lerp(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
@@ -0,0 +1,200 @@
+package imagegen
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"copycat/pkg/logger"
+)
+
+// OpenAIDefaultBaseURL OpenAI 官方接口地址
+const OpenAIDefaultBaseURL = "https://api.openai.com/v1"
+
+// OpenAIModels OpenAI 图像生成模型
+var OpenAIModels = []Model{
+	{ID: "gpt-image-1", Name: "GPT Image 1", Description: "理解能力强，中文提示词效果好"},
+	{ID: "dall-e-3", Name: "DALL·E 3", Description: "单次只能生成 1 张，会自动改写提示词"},
+}
+
+// 各模型支持的尺寸
+var (
+	gptImageSizes = [][2]int{{1024, 1024}, {1024, 1536}, {1536, 1024}}
+	dalle3Sizes   = [][2]int{{1024, 1024}, {1024, 1792}, {1792, 1024}}
+)
+
+// OpenAIProvider OpenAI 及兼容 /images/generations 接口的图像生成服务
+type OpenAIProvider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOpenAIProvider 创建 OpenAI 兼容图像生成服务商，baseURL 为空时使用官方地址
+func NewOpenAIProvider(apiKey, baseURL string) *OpenAIProvider {
+	if baseURL == "" {
+		baseURL = OpenAIDefaultBaseURL
+	}
+	return &OpenAIProvider{
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 3 * time.Minute,
+		},
+	}
+}
+
+// openAIImageRequest /images/generations 请求结构
+type openAIImageRequest struct {
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n"`
+	Size           string `json:"size"`
+	ResponseFormat string `json:"response_format,omitempty"` // gpt-image 系列不接受该参数，固定返回 base64
+}
+
+// openAIImageResponse /images/generations 响应结构
+type openAIImageResponse struct {
+	Data []struct {
+		B64JSON       string `json:"b64_json"`
+		URL           string `json:"url"`
+		RevisedPrompt string `json:"revised_prompt"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// Name 服务商标识
+func (p *OpenAIProvider) Name() string { return ProviderOpenAI }
+
+// Capabilities 服务商能力
+func (p *OpenAIProvider) Capabilities() Capabilities {
+	return Capabilities{
+		MaxCount:       4,
+		RequiresAPIKey: p.baseURL == OpenAIDefaultBaseURL,
+		CustomBaseURL:  true,
+		CustomModels:   true,
+	}
+}
+
+// Models 可用模型
+func (p *OpenAIProvider) Models() []Model {
+	return OpenAIModels
+}
+
+// Generate 生成图片；dall-e-3 单次只能生成 1 张，需要多张时逐张请求
+func (p *OpenAIProvider) Generate(req Request) ([]Image, error) {
+	if req.Model == "" {
+		req.Model = OpenAIModels[0].ID
+	}
+	if req.Count <= 0 {
+		req.Count = 1
+	}
+
+	sizes := gptImageSizes
+	if req.Model == "dall-e-3" {
+		sizes = dalle3Sizes
+	}
+	width, height := nearestSize(req.Aspect, sizes)
+
+	perRequest := req.Count
+	if req.Model == "dall-e-3" {
+		perRequest = 1
+	}
+
+	var images []Image
+	for len(images) < req.Count {
+		n := perRequest
+		if remaining := req.Count - len(images); n > remaining {
+			n = remaining
+		}
+		batch, err := p.generate(req, n, width, height)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, batch...)
+	}
+	return images, nil
+}
+
+func (p *OpenAIProvider) generate(req Request, n, width, height int) ([]Image, error) {
+	body := openAIImageRequest{
+		Model:  req.Model,
+		Prompt: req.Prompt,
+		N:      n,
+		Size:   fmt.Sprintf("%dx%d", width, height),
+	}
+	if !strings.HasPrefix(req.Model, "gpt-image") {
+		body.ResponseFormat = "b64_json"
+	}
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", p.baseURL+"/images/generations", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	logger.Info("图像生成请求: provider=openai, model=%s, size=%s, n=%d", req.Model, body.Size, n)
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	var result openAIImageResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("解析响应失败: HTTP %d: %w", resp.StatusCode, err)
+	}
+	if result.Error != nil {
+		logger.Error("图像生成 API 错误: status=%d, message=%s", resp.StatusCode, result.Error.Message)
+		return nil, fmt.Errorf("图像生成 API 错误: %s", result.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("图像生成 API 错误: HTTP %d", resp.StatusCode)
+	}
+
+	images := make([]Image, 0, len(result.Data))
+	for _, d := range result.Data {
+		var data []byte
+		if d.B64JSON != "" {
+			data, err = base64.StdEncoding.DecodeString(d.B64JSON)
+			if err != nil {
+				return nil, fmt.Errorf("解码图片数据失败: %w", err)
+			}
+		} else if d.URL != "" {
+			data, err = downloadImage(p.httpClient, d.URL)
+			if err != nil {
+				return nil, err
+			}
+		}
+		img, err := newImage(data, width, height, d.RevisedPrompt)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("图像生成响应中没有图片数据")
+	}
+	return images, nil
+}
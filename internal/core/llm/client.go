@@ -18,6 +18,10 @@ type Config struct {
 	ApiKey   string
 	Model    string
 	BaseURL  string // 可选自定义 API 地址
+
+	// ImageLoader 可选，将图片地址转换为 Base64 data URL（如从媒体缓存读取），
+	// 设置后多模态请求对所有服务商都发送 Base64，不再让服务商自行下载
+	ImageLoader func(imageURL string) (string, error)
 }
 
 // Client LLM 客户端
@@ -308,8 +312,15 @@ func (c *Client) ChatWithImages(text string, imageURLs []string) (string, error)
 	for _, url := range imageURLs {
 		var imageContent string
 
-		// Kimi/Moonshot 需要使用 Base64 格式
-		if c.config.Provider == "moonshot" {
+		if c.config.ImageLoader != nil {
+			dataURL, err := c.config.ImageLoader(url)
+			if err != nil {
+				log.Printf("[LLM] 读取缓存图片失败: %v，跳过该图片", err)
+				continue
+			}
+			imageContent = dataURL
+		} else if c.config.Provider == "moonshot" {
+			// Kimi/Moonshot 需要使用 Base64 格式
 			base64Data, err := downloadImageAsBase64(url)
 			if err != nil {
 				log.Printf("[LLM] 下载图片失败: %v，跳过该图片", err)
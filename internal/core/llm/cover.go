@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"copycat/pkg/logger"
+)
+
+// ========== 封面与轮播顺序分析 ==========
+
+// 提示词文件
+const (
+	AnalyzeCoverPromptFile    = "analyze_cover.txt"
+	AnalyzeCarouselPromptFile = "analyze_carousel.txt"
+)
+
+// 封面版式模板
+const (
+	CoverLayoutSingleSubject = "single_subject" // 单主体大图
+	CoverLayoutTextPoster    = "text_poster"    // 大字报/纯文字海报
+	CoverLayoutCollage       = "collage"        // 多图拼贴
+	CoverLayoutBeforeAfter   = "before_after"   // 前后对比
+	CoverLayoutListing       = "listing"        // 清单/合集平铺
+	CoverLayoutSelfie        = "selfie"         // 真人出镜/自拍
+	CoverLayoutScreenshot    = "screenshot"     // 截图/聊天记录
+	CoverLayoutOther         = "other"          // 其他
+)
+
+// CoverLayouts 封面版式及中文名称
+var CoverLayouts = map[string]string{
+	CoverLayoutSingleSubject: "单主体大图",
+	CoverLayoutTextPoster:    "大字报/纯文字海报",
+	CoverLayoutCollage:       "多图拼贴",
+	CoverLayoutBeforeAfter:   "前后对比",
+	CoverLayoutListing:       "清单/合集平铺",
+	CoverLayoutSelfie:        "真人出镜/自拍",
+	CoverLayoutScreenshot:    "截图/聊天记录",
+	CoverLayoutOther:         "其他",
+}
+
+var defaultAnalyzeCoverPrompt = `你是一位小红书封面设计与点击率分析专家。下面是一篇笔记的封面图，请分析它为什么能吸引用户点击。
+
+版式模板只能从以下取值中选择一个：single_subject(单主体大图)、text_poster(大字报/纯文字海报)、collage(多图拼贴)、before_after(前后对比)、listing(清单/合集平铺)、selfie(真人出镜/自拍)、screenshot(截图/聊天记录)、other(其他)。
+
+请用 JSON 格式回复：
+{
+  "has_text_overlay": true,
+  "headline_text": "封面上的标题文字原文（没有文字则为空）",
+  "text_style": "文字的字体、字号占比、颜色、描边/底色和位置",
+  "layout_template": "版式模板取值",
+  "layout_description": "版式构成说明",
+  "face_present": true,
+  "face_count": 1,
+  "face_description": "人脸的表情、视线方向和占画面比例（没有人脸则为空）",
+  "click_hooks": ["吸引点击的要素"],
+  "suggestions": "仿写封面时的建议"
+}
+
+请只回复 JSON，不要其他文字。`
+
+var defaultAnalyzeCarouselPrompt = `你是一位小红书图文笔记叙事分析专家。下面是一篇笔记按顺序排列的全部图片（第 1 张为封面），请分析图片顺序如何讲述一个完整的故事。
+
+每张图的角色只能从以下取值中选择：hook(钩子)、context(背景/痛点铺垫)、detail(细节/步骤展开)、proof(效果/证明)、summary(总结/清单)、cta(引导互动/关注)。
+
+请用 JSON 格式回复：
+{
+  "slides": [
+    {"index": 1, "role": "hook", "purpose": "这张图在叙事中的作用", "transition": "与下一张图的衔接方式"}
+  ],
+  "narrative_arc": "整组图片的叙事结构",
+  "pacing": "信息密度与节奏安排",
+  "retention_tactics": ["让用户继续往后翻的技巧"],
+  "suggestions": "仿写时如何安排图片顺序"
+}
+
+请只回复 JSON，不要其他文字。`
+
+// CoverAnalysisResult 封面分析结果
+type CoverAnalysisResult struct {
+	HasTextOverlay    bool     `json:"has_text_overlay"`   // 是否有文字叠加
+	HeadlineText      string   `json:"headline_text"`      // 封面标题文字
+	TextStyle         string   `json:"text_style"`         // 文字样式与位置
+	LayoutTemplate    string   `json:"layout_template"`    // 版式模板
+	LayoutDescription string   `json:"layout_description"` // 版式说明
+	FacePresent       bool     `json:"face_present"`       // 是否有人脸
+	FaceCount         int      `json:"face_count"`         // 人脸数量
+	FaceDescription   string   `json:"face_description"`   // 人脸表情/视线
+	ClickHooks        []string `json:"click_hooks"`        // 吸引点击的要素
+	Suggestions       string   `json:"suggestions"`        // 仿写建议
+}
+
+// CarouselSlide 轮播中单张图片的叙事作用
+type CarouselSlide struct {
+	Index      int    `json:"index"`
+	Role       string `json:"role"`       // 叙事角色
+	Purpose    string `json:"purpose"`    // 作用说明
+	Transition string `json:"transition"` // 与下一张的衔接
+}
+
+// CarouselAnalysisResult 轮播顺序分析结果
+type CarouselAnalysisResult struct {
+	Slides           []CarouselSlide `json:"slides"`
+	NarrativeArc     string          `json:"narrative_arc"`     // 叙事结构
+	Pacing           string          `json:"pacing"`            // 节奏安排
+	RetentionTactics []string        `json:"retention_tactics"` // 引导翻页的技巧
+	Suggestions      string          `json:"suggestions"`       // 仿写建议
+}
+
+// AnalyzeCover 分析封面图（文字叠加、标题文案、版式模板、人脸）
+func (c *Client) AnalyzeCover(coverURL string) (*CoverAnalysisResult, error) {
+	if coverURL == "" {
+		return nil, fmt.Errorf("没有提供封面图")
+	}
+
+	logger.LLMInfo("[LLM Service] 开始分析封面: %s", coverURL)
+
+	promptTemplate := loadPrompt(AnalyzeCoverPromptFile, defaultAnalyzeCoverPrompt)
+	response, err := c.ChatWithImages(promptTemplate, []string{coverURL})
+	if err != nil {
+		logger.LLMInfo("[LLM Service] 封面分析失败: %v", err)
+		return nil, fmt.Errorf("调用多模态 LLM 失败: %w", err)
+	}
+
+	var result CoverAnalysisResult
+	if err := json.Unmarshal([]byte(extractJSON(response)), &result); err != nil {
+		logger.LLMInfo("[LLM Service] 封面分析 JSON 解析失败: %v", err)
+		logger.LLMInfo("   原始响应: %s", response)
+		return nil, fmt.Errorf("解析封面分析结果失败: %w", err)
+	}
+
+	// 版式不在列表中时归为其他；有人脸但数量缺失时按 1 计；识别出标题文字即视为有文字叠加
+	if _, ok := CoverLayouts[result.LayoutTemplate]; !ok {
+		result.LayoutTemplate = CoverLayoutOther
+	}
+	if !result.FacePresent {
+		result.FaceCount = 0
+	} else if result.FaceCount == 0 {
+		result.FaceCount = 1
+	}
+	if result.HeadlineText != "" {
+		result.HasTextOverlay = true
+	}
+
+	logger.LLMInfo("[LLM Service] 封面分析成功: layout=%s, text=%v, face=%d", result.LayoutTemplate, result.HasTextOverlay, result.FaceCount)
+	return &result, nil
+}
+
+// AnalyzeCarousel 分析多图笔记的图片顺序如何组织叙事（至少 2 张）
+func (c *Client) AnalyzeCarousel(imageURLs []string) (*CarouselAnalysisResult, error) {
+	if len(imageURLs) < 2 {
+		return nil, fmt.Errorf("轮播分析至少需要 2 张图片")
+	}
+
+	logger.LLMInfo("[LLM Service] 开始分析轮播顺序 (数量: %d)", len(imageURLs))
+
+	promptTemplate := loadPrompt(AnalyzeCarouselPromptFile, defaultAnalyzeCarouselPrompt)
+	response, err := c.ChatWithImages(promptTemplate, imageURLs)
+	if err != nil {
+		logger.LLMInfo("[LLM Service] 轮播分析失败: %v", err)
+		return nil, fmt.Errorf("调用多模态 LLM 失败: %w", err)
+	}
+
+	var result CarouselAnalysisResult
+	if err := json.Unmarshal([]byte(extractJSON(response)), &result); err != nil {
+		logger.LLMInfo("[LLM Service] 轮播分析 JSON 解析失败: %v", err)
+		logger.LLMInfo("   原始响应: %s", response)
+		return nil, fmt.Errorf("解析轮播分析结果失败: %w", err)
+	}
+
+	logger.LLMInfo("[LLM Service] 轮播分析成功: %d 张, 叙事结构: %s", len(result.Slides), result.NarrativeArc)
+	return &result, nil
+}
@@ -0,0 +1,244 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 平台标识（与 crawler.Platform 取值保持一致）
+const (
+	PlatformXiaohongshu = "xiaohongshu"
+	PlatformDouyin      = "douyin"
+	PlatformWechat      = "wechat"
+)
+
+// PlatformLimit 平台发布限制（字数按 rune 计算）
+type PlatformLimit struct {
+	TitleMaxChars int `json:"title_max_chars"` // 标题最大字数
+	BodyMaxChars  int `json:"body_max_chars"`  // 正文最大字数
+	MaxHashtags   int `json:"max_hashtags"`    // 最多话题标签数
+}
+
+// PlatformLimits 各平台发布限制
+var PlatformLimits = map[string]PlatformLimit{
+	PlatformXiaohongshu: {TitleMaxChars: 20, BodyMaxChars: 1000, MaxHashtags: 10},
+	PlatformDouyin:      {TitleMaxChars: 30, BodyMaxChars: 1000, MaxHashtags: 5},
+	PlatformWechat:      {TitleMaxChars: 64, BodyMaxChars: 20000, MaxHashtags: 3},
+}
+
+// LimitViolation 超出平台限制的字段
+type LimitViolation struct {
+	Field   string `json:"field"`   // title/body/hashtags
+	Limit   int    `json:"limit"`   // 限制值
+	Actual  int    `json:"actual"`  // 实际值
+	Message string `json:"message"` // 提示信息
+}
+
+// ParsedContent 结构化后的生成文案
+type ParsedContent struct {
+	Title      string           `json:"title"`      // 标题
+	Body       string           `json:"body"`       // 正文（保留原始格式和表情）
+	Hashtags   []string         `json:"hashtags"`   // 话题标签（不含 #）
+	PlainText  string           `json:"plain_text"` // 去除表情和话题标签的纯文本正文
+	Platform   string           `json:"platform"`   // 校验所用平台
+	Violations []LimitViolation `json:"violations"` // 超限项
+}
+
+// sectionMarkerPattern 匹配行首的【xxx】段落标记，兼容 LLM 常见的 **【标题】** 写法
+var sectionMarkerPattern = regexp.MustCompile(`(?m)^[ \t>#*]*【([^】\n]{1,10})】[*]*[:：]?`)
+
+// hashtagPattern 匹配 #话题、#话题# 以及小红书导出的 #话题[话题]# 格式
+var hashtagPattern = regexp.MustCompile(`#([^\s#\[\]【】，。,.!！?？]+)(?:\[话题\])?#?`)
+
+// ParseGeneratedContent 将 【标题】…【正文】… 格式的生成文案拆分为结构化字段，并按平台限制校验
+func ParseGeneratedContent(raw, platform string) *ParsedContent {
+	if _, ok := PlatformLimits[platform]; !ok {
+		platform = PlatformXiaohongshu
+	}
+
+	sections, order := splitSections(raw)
+
+	result := &ParsedContent{
+		Platform:   platform,
+		Hashtags:   []string{},
+		Violations: []LimitViolation{},
+	}
+
+	result.Title = firstLine(sections["标题"])
+
+	// 图文文案有【正文】段；视频脚本等没有正文段时，除标题外的所有段落都视为正文
+	if body, ok := sections["正文"]; ok {
+		result.Body = body
+	} else {
+		parts := make([]string, 0, len(order))
+		for _, name := range order {
+			if name == "标题" {
+				continue
+			}
+			if name == "" {
+				parts = append(parts, sections[name])
+			} else {
+				parts = append(parts, "【"+name+"】\n"+sections[name])
+			}
+		}
+		result.Body = strings.TrimSpace(strings.Join(parts, "\n\n"))
+	}
+
+	// 话题标签可能在正文末尾，也可能单独放在【话题】/【标签】段
+	tagSource := result.Body
+	for _, name := range []string{"话题", "标签", "话题标签"} {
+		if s, ok := sections[name]; ok {
+			tagSource += "\n" + s
+		}
+	}
+	result.Hashtags = extractHashtags(tagSource)
+
+	result.PlainText = toPlainText(result.Body)
+	result.Violations = ValidatePlatformLimits(result, platform)
+
+	return result
+}
+
+// ValidatePlatformLimits 校验结构化文案是否超出平台限制
+func ValidatePlatformLimits(content *ParsedContent, platform string) []LimitViolation {
+	limit, ok := PlatformLimits[platform]
+	if !ok {
+		limit = PlatformLimits[PlatformXiaohongshu]
+	}
+
+	violations := make([]LimitViolation, 0)
+
+	if n := utf8.RuneCountInString(content.Title); limit.TitleMaxChars > 0 && n > limit.TitleMaxChars {
+		violations = append(violations, LimitViolation{
+			Field:   "title",
+			Limit:   limit.TitleMaxChars,
+			Actual:  n,
+			Message: fmt.Sprintf("标题 %d 字，超出平台限制 %d 字", n, limit.TitleMaxChars),
+		})
+	}
+
+	if n := utf8.RuneCountInString(content.Body); limit.BodyMaxChars > 0 && n > limit.BodyMaxChars {
+		violations = append(violations, LimitViolation{
+			Field:   "body",
+			Limit:   limit.BodyMaxChars,
+			Actual:  n,
+			Message: fmt.Sprintf("正文 %d 字，超出平台限制 %d 字", n, limit.BodyMaxChars),
+		})
+	}
+
+	if n := len(content.Hashtags); limit.MaxHashtags > 0 && n > limit.MaxHashtags {
+		violations = append(violations, LimitViolation{
+			Field:   "hashtags",
+			Limit:   limit.MaxHashtags,
+			Actual:  n,
+			Message: fmt.Sprintf("话题标签 %d 个，超出平台限制 %d 个", n, limit.MaxHashtags),
+		})
+	}
+
+	return violations
+}
+
+// splitSections 按【xxx】标记切分文本，返回段落内容及出现顺序；标记前的内容记在空名下
+func splitSections(raw string) (map[string]string, []string) {
+	sections := make(map[string]string)
+	order := make([]string, 0)
+
+	matches := sectionMarkerPattern.FindAllStringSubmatchIndex(raw, -1)
+	if len(matches) == 0 {
+		sections[""] = strings.TrimSpace(raw)
+		return sections, []string{""}
+	}
+
+	if lead := strings.TrimSpace(raw[:matches[0][0]]); lead != "" {
+		sections[""] = lead
+		order = append(order, "")
+	}
+
+	for i, m := range matches {
+		name := strings.TrimSpace(raw[m[2]:m[3]])
+		end := len(raw)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		text := strings.TrimSpace(strings.Trim(strings.TrimSpace(raw[m[1]:end]), "*"))
+
+		// 同名段落（如 LLM 重复输出）只保留第一次
+		if _, exists := sections[name]; exists {
+			continue
+		}
+		sections[name] = text
+		order = append(order, name)
+	}
+
+	return sections, order
+}
+
+// firstLine 取第一行非空文本
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// extractHashtags 提取去重后的话题标签
+func extractHashtags(text string) []string {
+	tags := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, m := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		tag := strings.TrimSpace(m[1])
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// toPlainText 去除表情符号和话题标签，压缩多余空白
+func toPlainText(text string) string {
+	text = hashtagPattern.ReplaceAllString(text, "")
+
+	var b strings.Builder
+	for _, r := range text {
+		if isEmojiRune(r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	cleaned := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			cleaned = append(cleaned, line)
+		}
+	}
+	return strings.Join(cleaned, "\n")
+}
+
+// isEmojiRune 判断是否为表情符号或其修饰字符
+func isEmojiRune(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // 表情、符号、象形文字、补充符号
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // 杂项符号、装饰符号
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // 箭头、星形等
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F: // 变体选择符
+		return true
+	case r == 0x200D || r == 0x20E3: // 零宽连接符、组合键帽
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // 旗帜标签
+		return true
+	}
+	return unicode.Is(unicode.So, r) && r > 0x2000
+}
@@ -0,0 +1,178 @@
+package llm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGeneratedContent(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		platform  string
+		title     string
+		body      string
+		hashtags  []string
+		plainText string
+		platformW string
+	}{
+		{
+			name:      "标题和正文",
+			raw:       "【标题】周末去哪玩\n【正文】超好玩的地方🎉\n#旅行 #周末#\n",
+			platform:  PlatformXiaohongshu,
+			title:     "周末去哪玩",
+			body:      "超好玩的地方🎉\n#旅行 #周末#",
+			hashtags:  []string{"旅行", "周末"},
+			plainText: "超好玩的地方",
+			platformW: PlatformXiaohongshu,
+		},
+		{
+			name:      "markdown 加粗标记和冒号",
+			raw:       "**【标题】** 标题A\n**【正文】**：正文内容",
+			platform:  PlatformDouyin,
+			title:     "标题A",
+			body:      "正文内容",
+			hashtags:  []string{},
+			plainText: "正文内容",
+			platformW: PlatformDouyin,
+		},
+		{
+			name:      "没有段落标记",
+			raw:       "  只有一段文字  ",
+			platform:  PlatformWechat,
+			title:     "",
+			body:      "只有一段文字",
+			hashtags:  []string{},
+			plainText: "只有一段文字",
+			platformW: PlatformWechat,
+		},
+		{
+			name:      "没有正文段时其余段落拼为正文",
+			raw:       "【标题】脚本\n【开场】大家好\n【结尾】下期见",
+			platform:  PlatformDouyin,
+			title:     "脚本",
+			body:      "【开场】\n大家好\n\n【结尾】\n下期见",
+			hashtags:  []string{},
+			plainText: "【开场】\n大家好\n【结尾】\n下期见",
+			platformW: PlatformDouyin,
+		},
+		{
+			name:      "重复段落只保留第一次",
+			raw:       "好的，以下是文案：\n【标题】A\n【标题】B\n【正文】C",
+			platform:  PlatformXiaohongshu,
+			title:     "A",
+			body:      "C",
+			hashtags:  []string{},
+			plainText: "C",
+			platformW: PlatformXiaohongshu,
+		},
+		{
+			name:      "单独的话题段和小红书话题格式",
+			raw:       "【标题】t\n【正文】body #旅行[话题]#\n【话题】#旅行 #美食",
+			platform:  PlatformXiaohongshu,
+			title:     "t",
+			body:      "body #旅行[话题]#",
+			hashtags:  []string{"旅行", "美食"},
+			plainText: "body",
+			platformW: PlatformXiaohongshu,
+		},
+		{
+			name:      "未知平台按小红书处理",
+			raw:       "【标题】t\n【正文】b",
+			platform:  "weibo",
+			title:     "t",
+			body:      "b",
+			hashtags:  []string{},
+			plainText: "b",
+			platformW: PlatformXiaohongshu,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseGeneratedContent(tt.raw, tt.platform)
+			if got.Title != tt.title {
+				t.Errorf("Title = %q, want %q", got.Title, tt.title)
+			}
+			if got.Body != tt.body {
+				t.Errorf("Body = %q, want %q", got.Body, tt.body)
+			}
+			if !reflect.DeepEqual(got.Hashtags, tt.hashtags) {
+				t.Errorf("Hashtags = %q, want %q", got.Hashtags, tt.hashtags)
+			}
+			if got.PlainText != tt.plainText {
+				t.Errorf("PlainText = %q, want %q", got.PlainText, tt.plainText)
+			}
+			if got.Platform != tt.platformW {
+				t.Errorf("Platform = %q, want %q", got.Platform, tt.platformW)
+			}
+		})
+	}
+}
+
+func TestValidatePlatformLimits(t *testing.T) {
+	tags := func(n int) []string {
+		s := make([]string, n)
+		for i := range s {
+			s[i] = "tag"
+		}
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		content  ParsedContent
+		platform string
+		want     []LimitViolation
+	}{
+		{
+			name:     "未超限",
+			content:  ParsedContent{Title: strings.Repeat("字", 20), Body: "正文", Hashtags: tags(10)},
+			platform: PlatformXiaohongshu,
+			want:     []LimitViolation{},
+		},
+		{
+			name:     "标题按 rune 计数",
+			content:  ParsedContent{Title: strings.Repeat("字", 21)},
+			platform: PlatformXiaohongshu,
+			want:     []LimitViolation{{Field: "title", Limit: 20, Actual: 21}},
+		},
+		{
+			name:     "抖音正文和话题数",
+			content:  ParsedContent{Body: strings.Repeat("a", 1001), Hashtags: tags(6)},
+			platform: PlatformDouyin,
+			want: []LimitViolation{
+				{Field: "body", Limit: 1000, Actual: 1001},
+				{Field: "hashtags", Limit: 5, Actual: 6},
+			},
+		},
+		{
+			name:     "公众号标题更长",
+			content:  ParsedContent{Title: strings.Repeat("字", 64), Hashtags: tags(4)},
+			platform: PlatformWechat,
+			want:     []LimitViolation{{Field: "hashtags", Limit: 3, Actual: 4}},
+		},
+		{
+			name:     "未知平台按小红书限制",
+			content:  ParsedContent{Title: strings.Repeat("字", 21)},
+			platform: "weibo",
+			want:     []LimitViolation{{Field: "title", Limit: 20, Actual: 21}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidatePlatformLimits(&tt.content, tt.platform)
+			for i := range got {
+				if got[i].Message == "" {
+					t.Errorf("violation %d has empty message", i)
+				}
+				got[i].Message = ""
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
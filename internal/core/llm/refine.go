@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"copycat/pkg/logger"
+)
+
+// RefinePromptFile 精修提示词文件
+const RefinePromptFile = "refine.txt"
+
+var defaultRefinePrompt = `你是一位爆款文案创作专家，正在和用户一起打磨一篇仿写文案。
+
+原标题：
+{{title}}
+
+原文案：
+{{content}}
+
+文案分析：
+{{analysis}}
+
+新主题：{{topic}}
+
+用户会针对你最新的一版提出修改意见。只按意见调整，未提及的部分保持原有风格和结构，保持上一版的输出格式，只输出修改后的完整内容。`
+
+// RefineTurn 精修对话中的一轮：Instruction 为用户指令（原始生成为空），Content 为该轮产出
+type RefineTurn struct {
+	Instruction string
+	Content     string
+}
+
+// RefineContent 基于原始分析上下文和历史版本，按指令精修单条生成内容
+// history 按从原始版本到最新版本的顺序排列，至少包含原始版本
+func (c *Client) RefineContent(originalTitle, originalContent string, analysisResult *AnalysisResult, newTopic string, history []RefineTurn, instruction string) (string, error) {
+	if len(history) == 0 {
+		return "", fmt.Errorf("缺少待精修的版本")
+	}
+	if strings.TrimSpace(instruction) == "" {
+		return "", fmt.Errorf("精修指令不能为空")
+	}
+
+	logger.LLMInfo("[LLM Service] 开始精修文案 (历史轮数: %d)", len(history))
+	logger.LLMInfo("   - 新主题: %s", newTopic)
+	logger.LLMInfo("   - 精修指令: %s", instruction)
+
+	analysisJSON, _ := json.MarshalIndent(analysisResult, "", "  ")
+
+	promptTemplate := loadPrompt(RefinePromptFile, defaultRefinePrompt)
+	systemPrompt := strings.ReplaceAll(promptTemplate, "{{title}}", originalTitle)
+	systemPrompt = strings.ReplaceAll(systemPrompt, "{{content}}", originalContent)
+	systemPrompt = strings.ReplaceAll(systemPrompt, "{{analysis}}", string(analysisJSON))
+	systemPrompt = strings.ReplaceAll(systemPrompt, "{{topic}}", newTopic)
+
+	// 把每个历史版本还原成 user/assistant 轮次，让模型看到完整的修改脉络
+	messages := []Message{
+		{Role: "system", Content: systemPrompt},
+	}
+	for i, turn := range history {
+		userContent := turn.Instruction
+		if i == 0 || userContent == "" {
+			userContent = fmt.Sprintf("请为新主题「%s」创作一篇仿写文案。", newTopic)
+		}
+		messages = append(messages,
+			Message{Role: "user", Content: userContent},
+			Message{Role: "assistant", Content: turn.Content},
+		)
+	}
+	messages = append(messages, Message{Role: "user", Content: instruction})
+
+	response, err := c.Chat(messages)
+	if err != nil {
+		logger.LLMInfo("[LLM Service] 精修失败: %v", err)
+		return "", fmt.Errorf("调用 LLM 失败: %w", err)
+	}
+
+	result := strings.TrimSpace(response)
+	logger.LLMInfo("[LLM Service] 精修成功 (长度: %d 字符)", len(result))
+	return result, nil
+}
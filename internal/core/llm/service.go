@@ -103,6 +103,7 @@ type AnalysisResult struct {
 	Audio           *AudioAnalysis           `json:"audio,omitempty"`
 	AudioAtmosphere *AudioAtmosphereAnalysis `json:"audio_atmosphere,omitempty"` // 新命名
 	TagsAndSEO      *TagsAndSEOAnalysis      `json:"tags_&_seo,omitempty"`       // 新字段
+	VideoFrames     []VideoFrame             `json:"video_frames,omitempty"`     // 视觉分析依据的关键帧
 }
 
 // HookAnalysis 开头钩子分析
@@ -352,6 +353,11 @@ func (c *Client) AnalyzeVideoContent(title, content string) (*AnalysisResult, er
 		return nil, fmt.Errorf("调用 LLM 失败: %w", err)
 	}
 
+	return parseVideoAnalysis(response)
+}
+
+// parseVideoAnalysis 解析视频分析响应
+func parseVideoAnalysis(response string) (*AnalysisResult, error) {
 	// 尝试提取 JSON
 	jsonStr := extractJSON(response)
 	logger.LLMInfo("[LLM Service] 提取的 JSON (长度: %d)", len(jsonStr))
@@ -389,175 +395,31 @@ func (c *Client) AnalyzeVideoContent(title, content string) (*AnalysisResult, er
 	return &result, nil
 }
 
-// GenerateContent 生成仿写文案
-func (c *Client) GenerateContent(originalTitle, originalContent string, analysisResult *AnalysisResult, newTopic string) (string, error) {
-	logger.LLMInfo("[LLM Service] 开始生成仿写文案")
-	logger.LLMInfo("   - 新主题: %s", newTopic)
-	logger.LLMInfo("   - 原标题: %s", originalTitle)
-	logger.LLMInfo("   - 原文长度: %d 字", len(originalContent))
-
-	analysisJSON, _ := json.MarshalIndent(analysisResult, "", "  ")
-
-	// 从文件加载提示词模板
-	promptTemplate := loadPrompt(GeneratePromptFile, defaultGeneratePrompt)
-
-	// 替换占位符
-	prompt := strings.ReplaceAll(promptTemplate, "{{title}}", originalTitle)
-	prompt = strings.ReplaceAll(prompt, "{{content}}", originalContent)
-	prompt = strings.ReplaceAll(prompt, "{{analysis}}", string(analysisJSON))
-	prompt = strings.ReplaceAll(prompt, "{{topic}}", newTopic)
-
-	messages := []Message{
-		{Role: "user", Content: prompt},
-	}
-
-	response, err := c.Chat(messages)
-	if err != nil {
-		logger.LLMInfo("[LLM Service] 生成失败: %v", err)
-		return "", fmt.Errorf("调用 LLM 失败: %w", err)
-	}
-
-	result := strings.TrimSpace(response)
-	logger.LLMInfo("[LLM Service] 生成成功 (长度: %d 字符)", len(result))
-	resultPreview := result
-	if len(resultPreview) > 200 {
-		resultPreview = resultPreview[:200] + "..."
-	}
-	logger.LLMInfo("   - 生成内容预览: %s", strings.ReplaceAll(resultPreview, "\n", " "))
-
-	return result, nil
-}
-
-// GenerateVideoScript 生成视频脚本仿写（包含时间线、分镜头、拍摄建议）
-func (c *Client) GenerateVideoScript(originalTitle, originalContent string, analysisResult *AnalysisResult, newTopic string) (string, error) {
-	logger.LLMInfo("[LLM Service] 开始生成视频脚本仿写")
-	logger.LLMInfo("   - 新主题: %s", newTopic)
-	logger.LLMInfo("   - 原标题: %s", originalTitle)
-	logger.LLMInfo("   - 原文长度: %d 字", len(originalContent))
-
-	analysisJSON, _ := json.MarshalIndent(analysisResult, "", "  ")
-
-	// 从文件加载视频脚本生成提示词模板
-	promptTemplate := loadPrompt(GenerateVideoPromptFile, defaultGeneratePrompt)
-
-	// 替换占位符
-	prompt := strings.ReplaceAll(promptTemplate, "{{title}}", originalTitle)
-	prompt = strings.ReplaceAll(prompt, "{{content}}", originalContent)
-	prompt = strings.ReplaceAll(prompt, "{{analysis}}", string(analysisJSON))
-	prompt = strings.ReplaceAll(prompt, "{{topic}}", newTopic)
-
-	messages := []Message{
-		{Role: "user", Content: prompt},
-	}
-
-	response, err := c.Chat(messages)
-	if err != nil {
-		logger.LLMInfo("[LLM Service] 视频脚本生成失败: %v", err)
-		return "", fmt.Errorf("调用 LLM 失败: %w", err)
-	}
-
-	result := strings.TrimSpace(response)
-	logger.LLMInfo("[LLM Service] 视频脚本生成成功 (长度: %d 字符)", len(result))
-	resultPreview := result
-	if len(resultPreview) > 300 {
-		resultPreview = resultPreview[:300] + "..."
-	}
-	logger.LLMInfo("   - 生成脚本预览: %s", strings.ReplaceAll(resultPreview, "\n", " "))
-
-	return result, nil
+// GenerateMultipleVideoScripts 并发生成多条视频脚本仿写（每条单独请求，避免单次输出过长超时）
+func (c *Client) GenerateMultipleVideoScripts(originalTitle, originalContent string, analysisResult *AnalysisResult, newTopic string, opts VariantOptions) ([]VariantResult, error) {
+	logger.LLMInfo("[LLM Service] 开始生成多条视频脚本 (目标条数: %d)", opts.Count)
+	base := buildGeneratePrompt(GenerateVideoPromptFile, originalTitle, originalContent, analysisResult, newTopic)
+	return c.GenerateVariants(base, opts)
 }
 
-// GenerateMultipleVideoScripts 生成多条视频脚本仿写（逐条生成，避免超时）
-func (c *Client) GenerateMultipleVideoScripts(originalTitle, originalContent string, analysisResult *AnalysisResult, newTopic string, count int) ([]string, error) {
-	if count <= 0 {
-		count = 1
-	}
-
-	logger.LLMInfo("[LLM Service] 开始生成多条视频脚本 (目标条数: %d)", count)
-	logger.LLMInfo("   - 新主题: %s", newTopic)
-	logger.LLMInfo("   - 原标题: %s", originalTitle)
-
-	results := make([]string, 0, count)
-
-	// 逐条生成，避免单次请求输出过多导致超时
-	for i := 0; i < count; i++ {
-		logger.LLMInfo("[LLM Service] 正在生成第 %d/%d 条视频脚本...", i+1, count)
-
-		content, err := c.GenerateVideoScript(originalTitle, originalContent, analysisResult, newTopic)
-		if err != nil {
-			logger.LLMInfo("[LLM Service] 生成第 %d 条失败: %v", i+1, err)
-			// 如果已经有结果了，返回已有的；否则返回错误
-			if len(results) > 0 {
-				logger.LLMInfo("[LLM Service] 返回已生成的 %d 条结果", len(results))
-				return results, nil
-			}
-			return nil, err
-		}
-		results = append(results, content)
-		logger.LLMInfo("[LLM Service] 成功生成第 %d/%d 条", i+1, count)
-	}
-
-	logger.LLMInfo("[LLM Service] 多条视频脚本生成完成 (实际条数: %d)", len(results))
-	return results, nil
+// GenerateMultipleContent 并发生成多条仿写文案（每条单独请求，保证返回条数与请求一致）
+func (c *Client) GenerateMultipleContent(originalTitle, originalContent string, analysisResult *AnalysisResult, newTopic string, opts VariantOptions) ([]VariantResult, error) {
+	logger.LLMInfo("[LLM Service] 开始生成多条仿写文案 (目标条数: %d)", opts.Count)
+	base := buildGeneratePrompt(GeneratePromptFile, originalTitle, originalContent, analysisResult, newTopic)
+	return c.GenerateVariants(base, opts)
 }
 
-// GenerateMultipleContent 生成多条仿写文案
-func (c *Client) GenerateMultipleContent(originalTitle, originalContent string, analysisResult *AnalysisResult, newTopic string, count int) ([]string, error) {
-	if count <= 1 {
-		// 单条生成走原逻辑
-		content, err := c.GenerateContent(originalTitle, originalContent, analysisResult, newTopic)
-		if err != nil {
-			return nil, err
-		}
-		return []string{content}, nil
-	}
-
-	logger.LLMInfo("[LLM Service] 开始生成多条仿写文案 (条数: %d)", count)
-	logger.LLMInfo("   - 新主题: %s", newTopic)
-	logger.LLMInfo("   - 原标题: %s", originalTitle)
-
+// buildGeneratePrompt 加载生成类提示词模板并替换占位符
+func buildGeneratePrompt(promptFile, originalTitle, originalContent string, analysisResult *AnalysisResult, newTopic string) string {
 	analysisJSON, _ := json.MarshalIndent(analysisResult, "", "  ")
 
-	// 从文件加载提示词模板
-	promptTemplate := loadPrompt(GeneratePromptFile, defaultGeneratePrompt)
+	promptTemplate := loadPrompt(promptFile, defaultGeneratePrompt)
 
-	// 替换占位符
 	prompt := strings.ReplaceAll(promptTemplate, "{{title}}", originalTitle)
 	prompt = strings.ReplaceAll(prompt, "{{content}}", originalContent)
 	prompt = strings.ReplaceAll(prompt, "{{analysis}}", string(analysisJSON))
 	prompt = strings.ReplaceAll(prompt, "{{topic}}", newTopic)
-
-	// 添加多条生成的指令
-	multiPrompt := fmt.Sprintf(`%s
-
-【重要】请生成 %d 条不同风格的仿写文案，每条之间用以下分隔符分开：
-
-===SEPARATOR===
-
-确保每条文案都有独特的表达方式和切入角度，但都要围绕"%s"这个主题。`, prompt, count, newTopic)
-
-	messages := []Message{
-		{Role: "user", Content: multiPrompt},
-	}
-
-	response, err := c.Chat(messages)
-	if err != nil {
-		logger.LLMInfo("[LLM Service] 多条生成失败: %v", err)
-		return nil, fmt.Errorf("调用 LLM 失败: %w", err)
-	}
-
-	// 分割多条内容
-	parts := strings.Split(response, "===SEPARATOR===")
-	results := make([]string, 0, len(parts))
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		if trimmed != "" {
-			results = append(results, trimmed)
-		}
-	}
-
-	logger.LLMInfo("[LLM Service] 多条生成成功 (实际条数: %d)", len(results))
-	return results, nil
+	return prompt
 }
 
 // extractJSON 从文本中提取 JSON
@@ -599,13 +461,14 @@ var defaultAnalyzeImagePrompt = `你是一位视觉内容分析专家。请分
 
 // ImageAnalysisItem 单张图片分析结果
 type ImageAnalysisItem struct {
-	Index       int    `json:"index"`
-	Composition string `json:"composition"`  // 构图分析
-	Technique   string `json:"technique"`    // 拍摄技巧
-	Highlight   string `json:"highlight"`    // 视觉爆点
-	ColorTone   string `json:"color_tone"`   // 色调风格
-	Mood        string `json:"mood"`         // 情绪氛围
-	ImagePrompt string `json:"image_prompt"` // AI 图像生成提示词
+	Index          int    `json:"index"`
+	Composition    string `json:"composition"`     // 构图分析
+	Technique      string `json:"technique"`       // 拍摄技巧
+	Highlight      string `json:"highlight"`       // 视觉爆点
+	ColorTone      string `json:"color_tone"`      // 色调风格
+	Mood           string `json:"mood"`            // 情绪氛围
+	ImagePrompt    string `json:"image_prompt"`    // AI 图像生成提示词
+	NegativePrompt string `json:"negative_prompt"` // 生成时需要避免的元素
 }
 
 // ImageAnalysisResult 图片分析结果
@@ -0,0 +1,296 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"copycat/internal/core/similarity"
+	"copycat/pkg/logger"
+)
+
+// 多变体生成默认参数
+const (
+	DefaultGenerateConcurrency = 3   // 并发请求数（避免触发服务商限流）
+	DefaultSimilarityLimit     = 0.6 // 变体间相似度上限，超过视为雷同
+	DefaultMaxRerolls          = 2   // 雷同变体最多重新生成次数
+	variantAvoidPreviewChars   = 120 // 重新生成时附带的雷同版本摘要长度
+	variantShingleSize         = 2   // 变体间比较使用字符二元组，对同主题改写更敏感
+)
+
+// StyleDirective 单条变体的风格指令，用于强制拉开变体之间的差异
+type StyleDirective struct {
+	Angle    string `json:"angle"`     // 切入角度
+	Tone     string `json:"tone"`      // 语气
+	HookType string `json:"hook_type"` // 开头钩子类型
+}
+
+// 风格指令候选池；第一条变体沿用原文风格，其余从候选池中错位组合
+var (
+	directiveAngles    = []string{"亲身经历分享", "干货清单盘点", "反常识观点", "对比测评", "场景故事", "避坑提醒", "新手入门指南", "前后变化对比"}
+	directiveTones     = []string{"亲切闺蜜", "犀利吐槽", "专业权威", "温暖治愈", "幽默搞笑", "真诚克制"}
+	directiveHookTypes = []string{"悬念式", "提问式", "数字式", "冲突式", "利益式", "反转式", "情绪共鸣式"}
+)
+
+// originalStyleDirective 沿用原文风格的指令（单条生成时与旧行为一致）
+var originalStyleDirective = StyleDirective{
+	Angle:    "沿用原文的切入角度",
+	Tone:     "沿用原文的语气风格",
+	HookType: "沿用原标题的技巧",
+}
+
+// VariantValidator 对单条生成内容做额外校验；返回非空字符串表示不合格，
+// 内容会作为修改意见附加到提示词中重新生成
+type VariantValidator func(content string) string
+
+// VariantOptions 多变体生成参数
+type VariantOptions struct {
+	Count           int              // 目标条数
+	Concurrency     int              // 并发数
+	SimilarityLimit float64          // 变体间相似度上限（0-1）
+	MaxRerolls      int              // 雷同/校验不通过的变体最多重新生成次数
+	Validate        VariantValidator // 可选的额外校验（如原创度检查）
+}
+
+// DefaultVariantOptions 返回指定条数的默认生成参数
+func DefaultVariantOptions(count int) VariantOptions {
+	return VariantOptions{
+		Count:           count,
+		Concurrency:     DefaultGenerateConcurrency,
+		SimilarityLimit: DefaultSimilarityLimit,
+		MaxRerolls:      DefaultMaxRerolls,
+	}
+}
+
+// VariantResult 单条变体的生成结果；生成失败时 Error 非空、Content 为空
+type VariantResult struct {
+	Index         int            `json:"index"`             // 序号，从 1 开始
+	Directive     StyleDirective `json:"directive"`         // 本条使用的风格指令
+	Content       string         `json:"content"`           // 生成内容
+	Error         string         `json:"error,omitempty"`   // 失败原因
+	Attempts      int            `json:"attempts"`          // 实际请求次数（含雷同重试）
+	MaxSimilarity float64        `json:"max_similarity"`    // 与其他变体的最高相似度
+	Duplicate     bool           `json:"duplicate"`         // 重新生成用完后仍与前面的变体雷同
+	Warning       string         `json:"warning,omitempty"` // 提示信息（如仍然雷同）
+}
+
+// BuildStyleDirectives 为 count 条变体生成互不相同的风格指令
+func BuildStyleDirectives(count int) []StyleDirective {
+	directives := make([]StyleDirective, count)
+	for i := 0; i < count; i++ {
+		if i == 0 {
+			directives[i] = originalStyleDirective
+			continue
+		}
+		// 三个维度使用不同步长错位组合，保证相邻变体在每个维度上都不同
+		n := i - 1
+		directives[i] = StyleDirective{
+			Angle:    directiveAngles[n%len(directiveAngles)],
+			Tone:     directiveTones[(n*2+1)%len(directiveTones)],
+			HookType: directiveHookTypes[(n*3+2)%len(directiveHookTypes)],
+		}
+	}
+	return directives
+}
+
+// GenerateVariants 使用有界并发池生成 opts.Count 条变体，并对雷同变体重新生成
+// 返回结果条数始终等于 opts.Count；只有全部失败时才返回 error
+func (c *Client) GenerateVariants(basePrompt string, opts VariantOptions) ([]VariantResult, error) {
+	if opts.Count <= 0 {
+		opts.Count = 1
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultGenerateConcurrency
+	}
+	if opts.Concurrency > opts.Count {
+		opts.Concurrency = opts.Count
+	}
+
+	directives := BuildStyleDirectives(opts.Count)
+	results := make([]VariantResult, opts.Count)
+	for i := range results {
+		results[i] = VariantResult{Index: i + 1, Directive: directives[i]}
+	}
+
+	// 第一轮：所有变体并发生成
+	pending := make([]int, opts.Count)
+	for i := range pending {
+		pending[i] = i
+	}
+	c.runVariantJobs(basePrompt, results, pending, nil, opts.Concurrency)
+
+	// 后续轮次：只重新生成雷同或校验不通过的那几条，并把原因作为修改意见告诉模型
+	for round := 0; round < opts.MaxRerolls; round++ {
+		feedback := make(map[int]string)
+		if opts.SimilarityLimit > 0 {
+			for i, text := range findDuplicateVariants(results, opts.SimilarityLimit) {
+				feedback[i] = text
+			}
+		}
+		if opts.Validate != nil {
+			for i := range results {
+				if results[i].Error != "" {
+					continue
+				}
+				if text := opts.Validate(results[i].Content); text != "" {
+					feedback[i] += text
+				}
+			}
+		}
+		if len(feedback) == 0 {
+			break
+		}
+
+		indexes := make([]int, 0, len(feedback))
+		for i := range results {
+			if _, ok := feedback[i]; ok {
+				indexes = append(indexes, i)
+			}
+		}
+		logger.LLMInfo("[LLM Service] 第 %d 轮重新生成: %d 条变体雷同或未通过校验", round+1, len(indexes))
+		c.runVariantJobs(basePrompt, results, indexes, feedback, opts.Concurrency)
+	}
+	updateMaxSimilarity(results)
+
+	// 重新生成轮次用完后仍然雷同的变体照常返回，但标记出来让调用方提示用户
+	if opts.SimilarityLimit > 0 {
+		for i := range findDuplicateVariants(results, opts.SimilarityLimit) {
+			results[i].Duplicate = true
+			results[i].Warning = fmt.Sprintf("与其他版本相似度 %.0f%%，重新生成 %d 轮后仍然雷同", results[i].MaxSimilarity*100, opts.MaxRerolls)
+		}
+	}
+
+	succeeded := 0
+	for _, r := range results {
+		if r.Error == "" {
+			succeeded++
+		}
+	}
+	logger.LLMInfo("[LLM Service] 多变体生成完成 (成功: %d/%d)", succeeded, opts.Count)
+
+	if succeeded == 0 {
+		return results, fmt.Errorf("全部 %d 条变体生成失败: %s", opts.Count, results[0].Error)
+	}
+	return results, nil
+}
+
+// runVariantJobs 用 worker 池并发生成指定序号的变体，结果写回 results
+func (c *Client) runVariantJobs(basePrompt string, results []VariantResult, indexes []int, feedback map[int]string, concurrency int) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency && w < len(indexes); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				prompt := buildVariantPrompt(basePrompt, results[i].Directive, feedback[i])
+				content, err := c.Chat([]Message{{Role: "user", Content: prompt}})
+
+				// 每个 worker 只写自己负责的下标，无需加锁
+				results[i].Attempts++
+				if err != nil {
+					logger.LLMInfo("[LLM Service] 第 %d 条变体生成失败: %v", i+1, err)
+					// 重新生成失败时保留上一次的内容，不让成功的结果变成失败
+					if results[i].Content == "" {
+						results[i].Error = err.Error()
+					}
+					continue
+				}
+				results[i].Content = strings.TrimSpace(content)
+				results[i].Error = ""
+			}
+		}()
+	}
+
+	for _, i := range indexes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// buildVariantPrompt 在基础提示词后追加本条变体的风格指令和重新生成时的修改意见
+func buildVariantPrompt(basePrompt string, directive StyleDirective, feedback string) string {
+	var b strings.Builder
+	b.WriteString(basePrompt)
+	b.WriteString("\n\n【本条创作要求】\n")
+	fmt.Fprintf(&b, "- 切入角度：%s\n", directive.Angle)
+	fmt.Fprintf(&b, "- 语气风格：%s\n", directive.Tone)
+	fmt.Fprintf(&b, "- 开头钩子：%s\n", directive.HookType)
+
+	if feedback != "" {
+		b.WriteString("\n")
+		b.WriteString(feedback)
+	}
+	return b.String()
+}
+
+// findDuplicateVariants 找出与排在前面的成功变体过于相似的变体，返回下标到修改意见的映射
+func findDuplicateVariants(results []VariantResult, limit float64) map[int]string {
+	avoid := make(map[int][]string)
+
+	for i := range results {
+		if results[i].Error != "" {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if results[j].Error != "" {
+				continue
+			}
+			if variantSimilarity(results[i].Content, results[j].Content) > limit {
+				avoid[i] = append(avoid[i], results[j].Content)
+			}
+		}
+	}
+
+	feedback := make(map[int]string, len(avoid))
+	for i, texts := range avoid {
+		var b strings.Builder
+		b.WriteString("【避免雷同】以下是已经写好的其他版本，请在标题、开头和核心表达上与它们明显区分：\n")
+		for n, text := range texts {
+			fmt.Fprintf(&b, "%d. %s\n", n+1, truncateRunes(text, variantAvoidPreviewChars))
+		}
+		feedback[i] = b.String()
+	}
+	return feedback
+}
+
+// updateMaxSimilarity 计算每条变体与其他变体的最高相似度，供前端展示
+func updateMaxSimilarity(results []VariantResult) {
+	for i := range results {
+		results[i].MaxSimilarity = 0
+	}
+	for i := range results {
+		if results[i].Error != "" {
+			continue
+		}
+		for j := i + 1; j < len(results); j++ {
+			if results[j].Error != "" {
+				continue
+			}
+			sim := variantSimilarity(results[i].Content, results[j].Content)
+			if sim > results[i].MaxSimilarity {
+				results[i].MaxSimilarity = sim
+			}
+			if sim > results[j].MaxSimilarity {
+				results[j].MaxSimilarity = sim
+			}
+		}
+	}
+}
+
+// variantSimilarity 变体之间的相似度（字符二元组 Jaccard，忽略空白和标点）
+func variantSimilarity(a, b string) float64 {
+	return similarity.Jaccard(a, b, variantShingleSize)
+}
+
+// truncateRunes 按字符截断文本
+func truncateRunes(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
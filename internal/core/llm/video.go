@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"copycat/pkg/logger"
+)
+
+// ========== 基于关键帧的视频分析 ==========
+
+// AnalyzeVideoFramesPromptFile 关键帧视频分析提示词文件
+const AnalyzeVideoFramesPromptFile = "analyze_video_frames.txt"
+
+var defaultAnalyzeVideoFramesPrompt = `你是一位短视频总导演与爆款内容拆解专家。下面是一条短视频的标题、文案，以及按时间顺序抽取的关键帧（每帧的时间点见帧列表）。
+
+视觉相关字段（开头钩子的画面、场景、构图、运镜、剪辑节奏）必须依据关键帧中实际看到的内容填写，并尽量标注对应的时间点；听觉相关字段无法从画面获得，请根据文案和画面氛围推导。
+
+请用 JSON 格式回复：
+{
+  "title_analysis": {"original": "{{title}}", "score": 8.5, "hooks": ["吸睛点"], "techniques": ["技巧"]},
+  "hook_strategy": {"type": "开头类型", "description": "开头画面与文案如何抓人", "estimated_duration": "0-3秒", "effectiveness_score": 9},
+  "narrative_logic": {"structure_type": "叙事结构", "pacing": "节奏", "golden_quotes": ["金句"]},
+  "visual_direction": {"suggested_scenes": ["关键帧中出现的场景（含时间点）"], "composition_vibe": "实际构图", "camera_movement_suggestion": "从相邻帧变化判断的运镜", "editing_style": "从镜头切换频率判断的剪辑风格"},
+  "audio_atmosphere": {"bgm_style": "建议BGM", "voice_tone": "建议人声", "sound_effects": ["建议音效"]},
+  "ppp_model": {"people": "人设", "place": "场景", "product": "价值载体"},
+  "viral_mechanics": {"core_logic": "爆款核心", "emotional_triggers": ["情绪触发点"], "replicable_elements": ["可复用元素"]},
+  "tags_&_seo": {"keywords": ["关键词"], "emotion_intensity": 0.8, "word_count": "字数"}
+}
+
+请只回复 JSON，不要其他文字。
+
+标题：{{title}}
+文案：{{content}}
+关键帧：
+{{frames}}`
+
+// VideoFrame 视频关键帧（URL 为媒体缓存代理地址或 data URL）
+type VideoFrame struct {
+	Timestamp float64 `json:"timestamp"` // 时间点（秒）
+	URL       string  `json:"url"`
+}
+
+// AnalyzeVideoWithFrames 结合关键帧的视频内容分析，视觉字段以画面为依据（需要视觉模型）
+func (c *Client) AnalyzeVideoWithFrames(title, content string, frames []VideoFrame) (*AnalysisResult, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("没有提供视频关键帧")
+	}
+
+	logger.LLMInfo("[LLM Service] 开始关键帧视频分析 (标题: %d 字, 正文: %d 字, 关键帧: %d)", len(title), len(content), len(frames))
+
+	var frameList strings.Builder
+	urls := make([]string, len(frames))
+	for i, f := range frames {
+		fmt.Fprintf(&frameList, "第 %d 帧：%s\n", i+1, formatTimestamp(f.Timestamp))
+		urls[i] = f.URL
+	}
+
+	promptTemplate := loadPrompt(AnalyzeVideoFramesPromptFile, defaultAnalyzeVideoFramesPrompt)
+	prompt := strings.ReplaceAll(promptTemplate, "{{title}}", title)
+	prompt = strings.ReplaceAll(prompt, "{{content}}", content)
+	prompt = strings.ReplaceAll(prompt, "{{frames}}", strings.TrimSpace(frameList.String()))
+
+	response, err := c.ChatWithImages(prompt, urls)
+	if err != nil {
+		logger.LLMInfo("[LLM Service] 关键帧视频分析失败: %v", err)
+		return nil, fmt.Errorf("调用多模态 LLM 失败: %w", err)
+	}
+
+	result, err := parseVideoAnalysis(response)
+	if err != nil {
+		return nil, err
+	}
+	result.VideoFrames = frames
+	return result, nil
+}
+
+// formatTimestamp 秒数格式化为 mm:ss.s
+func formatTimestamp(seconds float64) string {
+	m := int(seconds) / 60
+	return fmt.Sprintf("%02d:%04.1f", m, seconds-float64(m*60))
+}
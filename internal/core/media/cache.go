@@ -0,0 +1,327 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // 注册解码器，用于读取尺寸和生成压缩图
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/logger"
+	"copycat/pkg/storage"
+)
+
+const (
+	// ProxyPathPrefix 媒体代理地址前缀，后接内容哈希
+	ProxyPathPrefix = "/api/v1/media/"
+
+	maxDownloadBytes = 20 << 20 // 单张图片最大字节数
+	downloadWorkers  = 4        // 批量缓存时的并发下载数
+	userAgent        = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+)
+
+// ErrNotImage 下载的内容不是支持的图片格式
+var ErrNotImage = errors.New("不是支持的图片格式")
+
+// refererRules 图片域名后缀 -> 防盗链要求的 Referer
+var refererRules = []struct {
+	suffix  string
+	referer string
+}{
+	{"xhscdn.com", "https://www.xiaohongshu.com/"},
+	{"xiaohongshu.com", "https://www.xiaohongshu.com/"},
+	{"qpic.cn", "https://mp.weixin.qq.com/"},
+	{"qlogo.cn", "https://mp.weixin.qq.com/"},
+}
+
+// Referer 图片地址对应的 Referer，没有防盗链规则时返回空字符串
+func Referer(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, rule := range refererRules {
+		if host == rule.suffix || strings.HasSuffix(host, "."+rule.suffix) {
+			return rule.referer
+		}
+	}
+	return ""
+}
+
+// ProxyURL 媒体代理地址
+func ProxyURL(hash string) string {
+	return ProxyPathPrefix + hash
+}
+
+// IsValidHash 校验内容哈希格式（64 位小写十六进制）
+func IsValidHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	for _, r := range hash {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// HashFromRef 从代理地址（可带域名）中解析内容哈希，不是代理地址时返回空字符串
+func HashFromRef(ref string) string {
+	if u, err := url.Parse(ref); err == nil {
+		ref = u.Path
+	}
+	i := strings.Index(ref, ProxyPathPrefix)
+	if i < 0 {
+		return ""
+	}
+	hash := strings.TrimSuffix(ref[i+len(ProxyPathPrefix):], "/")
+	if !IsValidHash(hash) {
+		return ""
+	}
+	return hash
+}
+
+// Cache 笔记图片缓存：按来源 URL 下载一次，按内容哈希去重保存，并为视觉模型生成压缩图
+type Cache struct {
+	store      storage.Storage
+	repo       repository.MediaObjectRepository
+	httpClient *http.Client
+}
+
+// NewCache 创建图片缓存
+func NewCache(store storage.Storage, repo repository.MediaObjectRepository) *Cache {
+	return &Cache{
+		store: store,
+		repo:  repo,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Get 根据内容哈希获取缓存记录
+func (c *Cache) Get(ctx context.Context, hash string) (*model.MediaObject, error) {
+	return c.repo.GetByHash(ctx, hash)
+}
+
+// Fetch 获取图片缓存：代理地址直接按哈希查找，来源 URL 已缓存时直接返回，否则下载并保存
+func (c *Cache) Fetch(ctx context.Context, rawURL string) (*model.MediaObject, error) {
+	if hash := HashFromRef(rawURL); hash != "" {
+		return c.repo.GetByHash(ctx, hash)
+	}
+	if obj, err := c.repo.GetBySourceURL(ctx, rawURL); err == nil {
+		return obj, nil
+	}
+
+	data, err := c.download(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return c.Save(ctx, rawURL, data)
+}
+
+// Save 保存已获取的图片数据（如视频关键帧），sourceURL 用于记录来源和后续按地址查找
+func (c *Cache) Save(ctx context.Context, sourceURL string, data []byte) (*model.MediaObject, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if obj, err := c.repo.GetByHash(ctx, hash); err == nil {
+		// 同一张图片换了地址（签名过期等），复用已有文件
+		return obj, nil
+	}
+
+	format := detectFormat(data)
+	if format == "" {
+		return nil, fmt.Errorf("%s: %w", sourceURL, ErrNotImage)
+	}
+	obj := &model.MediaObject{
+		Hash:        hash,
+		SourceURL:   sourceURL,
+		ContentType: "image/" + format,
+		Format:      format,
+		Size:        int64(len(data)),
+		StorageKey:  fmt.Sprintf("media/%s/%s/original.%s", hash[:2], hash, format),
+	}
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		obj.Width, obj.Height = cfg.Width, cfg.Height
+	}
+
+	if err := c.store.Put(ctx, obj.StorageKey, bytes.NewReader(data), obj.Size, obj.ContentType); err != nil {
+		return nil, fmt.Errorf("保存图片失败: %w", err)
+	}
+	if err := c.repo.Create(ctx, obj); err != nil {
+		return nil, err
+	}
+	logger.Info("图片已缓存: hash=%s, format=%s, size=%d, url=%s", hash, format, obj.Size, sourceURL)
+	return obj, nil
+}
+
+// FetchAll 并发缓存多张图片，返回与输入一一对应的代理地址（失败的为空字符串）
+func (c *Cache) FetchAll(ctx context.Context, urls []string) []string {
+	proxied := make([]string, len(urls))
+	sem := make(chan struct{}, downloadWorkers)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		if u == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			obj, err := c.Fetch(ctx, u)
+			if err != nil {
+				logger.Warn("缓存图片失败: %s - %v", u, err)
+				return
+			}
+			proxied[i] = ProxyURL(obj.Hash)
+		}(i, u)
+	}
+	wg.Wait()
+	return proxied
+}
+
+// Open 打开原图或压缩图
+func (c *Cache) Open(ctx context.Context, obj *model.MediaObject, vision bool) (storage.Object, string, error) {
+	if vision {
+		if err := c.ensureVision(ctx, obj); err != nil {
+			return nil, "", err
+		}
+		if obj.VisionKey != "" {
+			f, err := c.store.Open(ctx, obj.VisionKey)
+			return f, "image/jpeg", err
+		}
+	}
+	f, err := c.store.Open(ctx, obj.StorageKey)
+	return f, obj.ContentType, err
+}
+
+// DataURL 将图片引用（来源 URL、代理地址或 data URL）转换为视觉模型使用的 Base64 data URL，
+// 优先使用压缩图
+func (c *Cache) DataURL(ctx context.Context, ref string) (string, error) {
+	if strings.HasPrefix(ref, "data:") {
+		return ref, nil
+	}
+	obj, err := c.Fetch(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	f, contentType, err := c.Open(ctx, obj, true)
+	if err != nil {
+		return "", fmt.Errorf("读取缓存图片失败: %w", err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", fmt.Errorf("读取缓存图片失败: %w", err)
+	}
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Loader 绑定上下文的图片加载函数，供 LLM 客户端把图片地址转换为 Base64
+func (c *Cache) Loader(ctx context.Context) func(string) (string, error) {
+	return func(ref string) (string, error) {
+		return c.DataURL(ctx, ref)
+	}
+}
+
+// ensureVision 按需生成压缩图；原图已足够小或无法解码（如 WebP）时直接使用原图
+func (c *Cache) ensureVision(ctx context.Context, obj *model.MediaObject) error {
+	if obj.VisionKey != "" || !needsResize(obj) {
+		return nil
+	}
+
+	f, err := c.store.Open(ctx, obj.StorageKey)
+	if err != nil {
+		return fmt.Errorf("读取缓存图片失败: %w", err)
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("读取缓存图片失败: %w", err)
+	}
+
+	resized, err := resizeForVision(data)
+	if err != nil {
+		logger.Warn("生成压缩图失败，使用原图: hash=%s, err=%v", obj.Hash, err)
+		return nil
+	}
+	key := fmt.Sprintf("media/%s/%s/vision.jpg", obj.Hash[:2], obj.Hash)
+	if err := c.store.Put(ctx, key, bytes.NewReader(resized), int64(len(resized)), "image/jpeg"); err != nil {
+		return fmt.Errorf("保存压缩图失败: %w", err)
+	}
+	if err := c.repo.UpdateVision(ctx, obj.Hash, key, int64(len(resized))); err != nil {
+		return err
+	}
+	obj.VisionKey, obj.VisionSize = key, int64(len(resized))
+	return nil
+}
+
+// download 带 Referer 下载图片
+func (c *Cache) download(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("无效的图片地址: %s", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "image/avif,image/webp,image/png,image/jpeg,*/*")
+	if referer := Referer(rawURL); referer != "" {
+		req.Header.Set("Referer", referer)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("下载图片失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载图片失败: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取图片数据失败: %w", err)
+	}
+	if len(data) > maxDownloadBytes {
+		return nil, fmt.Errorf("图片超过 %d MB", maxDownloadBytes>>20)
+	}
+	return data, nil
+}
+
+// detectFormat 根据文件头识别图片格式
+func detectFormat(data []byte) string {
+	switch {
+	case len(data) >= 8 && string(data[:8]) == "\x89PNG\r\n\x1a\n":
+		return "png"
+	case len(data) >= 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF:
+		return "jpeg"
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "webp"
+	case len(data) >= 6 && (string(data[:6]) == "GIF87a" || string(data[:6]) == "GIF89a"):
+		return "gif"
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	"copycat/internal/model"
+)
+
+// 视觉模型压缩图参数：长边超过上限或文件过大时缩放并转为 JPEG，
+// 既减少上传体积，也避免超出各服务商的单图大小限制
+const (
+	VisionMaxSide  = 1536
+	VisionMaxBytes = 1 << 20
+	visionQuality  = 85
+)
+
+// needsResize 是否需要生成压缩图；尺寸未知说明标准库无法解码（如 WebP），只能使用原图
+func needsResize(obj *model.MediaObject) bool {
+	if obj.Width == 0 || obj.Height == 0 {
+		return false
+	}
+	return obj.Width > VisionMaxSide || obj.Height > VisionMaxSide || obj.Size > VisionMaxBytes
+}
+
+// resizeForVision 等比缩放到长边不超过 VisionMaxSide，透明区域填充白色后编码为 JPEG
+func resizeForVision(data []byte) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %w", err)
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > VisionMaxSide || h > VisionMaxSide {
+		if w >= h {
+			w, h = VisionMaxSide, max(1, h*VisionMaxSide/b.Dx())
+		} else {
+			w, h = max(1, w*VisionMaxSide/b.Dy()), VisionMaxSide
+		}
+	}
+
+	dst := scaleArea(src, w, h)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: visionQuality}); err != nil {
+		return nil, fmt.Errorf("编码压缩图失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// scaleArea 区域平均缩放（缩小时无锯齿），目标尺寸与原图相同时只做白底合成
+func scaleArea(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*sh/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*sh/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*sw/w
+			x1 := max(x0+1, b.Min.X+(x+1)*sw/w)
+
+			var r, g, bl, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					// 预乘 alpha 的颜色叠加到白底：c + (1-a)*白
+					white := uint64(0xffff - ca)
+					r += uint64(cr) + white
+					g += uint64(cg) + white
+					bl += uint64(cb) + white
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: 0xffff,
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"context"
+	"errors"
+
+	"copycat/internal/model"
+	"copycat/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 授权失败原因
+var (
+	ErrNotMember = errors.New("不是该工作区的成员")
+	ErrForbidden = errors.New("当前角色无权执行该操作")
+)
+
+// Action 工作区内的操作
+type Action string
+
+const (
+	ActionView            Action = "view"             // 查看项目、批量任务、生成记录和素材
+	ActionEdit            Action = "edit"             // 创建、分析、生成、修改和删除项目，调用模型
+	ActionManageMembers   Action = "manage_members"   // 邀请、移除成员和修改角色
+	ActionManageSettings  Action = "manage_settings"  // 修改工作区共享 API Key
+	ActionManageWorkspace Action = "manage_workspace" // 重命名和删除工作区
+	ActionViewAudit       Action = "view_audit"       // 查看工作区审计日志
+)
+
+// rolePermissions 各角色允许的操作
+var rolePermissions = map[string]map[Action]bool{
+	model.WorkspaceRoleOwner: {
+		ActionView: true, ActionEdit: true, ActionManageMembers: true, ActionManageSettings: true, ActionManageWorkspace: true, ActionViewAudit: true,
+	},
+	model.WorkspaceRoleEditor: {
+		ActionView: true, ActionEdit: true,
+	},
+	model.WorkspaceRoleViewer: {
+		ActionView: true,
+	},
+}
+
+// ValidRole 是否为有效角色
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Allowed 角色是否允许执行操作
+func Allowed(role string, action Action) bool {
+	return rolePermissions[role][action]
+}
+
+// Authorizer 工作区授权：所有资源访问都按资源所属工作区和用户在其中的角色判断
+type Authorizer struct {
+	workspaces repository.WorkspaceRepository
+	projects   repository.ProjectRepository
+}
+
+// NewAuthorizer 创建授权器
+func NewAuthorizer(workspaces repository.WorkspaceRepository, projects repository.ProjectRepository) *Authorizer {
+	return &Authorizer{workspaces: workspaces, projects: projects}
+}
+
+// Role 用户在工作区中的角色，不是成员时返回 ErrNotMember
+func (a *Authorizer) Role(ctx context.Context, userID, workspaceID int64) (string, error) {
+	member, err := a.workspaces.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrNotMember
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// Authorize 检查用户能否在工作区内执行操作
+func (a *Authorizer) Authorize(ctx context.Context, userID, workspaceID int64, action Action) error {
+	role, err := a.Role(ctx, userID, workspaceID)
+	if err != nil {
+		return err
+	}
+	if !Allowed(role, action) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeProject 检查用户能否对项目执行操作
+func (a *Authorizer) AuthorizeProject(ctx context.Context, userID int64, project *model.Project, action Action) error {
+	return a.Authorize(ctx, userID, project.WorkspaceID, action)
+}
+
+// AuthorizeBatchTask 检查用户能否对批量任务执行操作
+func (a *Authorizer) AuthorizeBatchTask(ctx context.Context, userID int64, task *model.BatchTask, action Action) error {
+	return a.Authorize(ctx, userID, task.WorkspaceID, action)
+}
+
+// AuthorizeProjectID 加载项目并检查权限（生成记录、素材等挂在项目下的资源按所属项目授权）
+func (a *Authorizer) AuthorizeProjectID(ctx context.Context, userID int64, projectID uuid.UUID, action Action) (*model.Project, error) {
+	project, err := a.projects.GetByID(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.AuthorizeProject(ctx, userID, project, action); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// AuthorizeAsset 检查用户能否对素材执行操作：关联项目的素材按项目授权，其余素材只有创建者可以访问。
+// 项目已删除但素材仍在（删除项目会清理素材，此前版本删除项目时遗留的图片和音频）时同样只有创建者可以访问，便于查看和删除
+func (a *Authorizer) AuthorizeAsset(ctx context.Context, userID int64, asset *model.Asset, action Action) error {
+	if asset.ProjectID != nil {
+		_, err := a.AuthorizeProjectID(ctx, userID, *asset.ProjectID, action)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	if asset.UserID != userID {
+		return ErrNotMember
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package similarity
+
+import (
+	"sort"
+	"unicode"
+)
+
+// 默认参数
+const (
+	DefaultShingleSize = 3 // n-gram 大小，中文 3 字能较好区分常用搭配和照搬句子
+	DefaultMinSpanSize = 8 // 高亮的重合片段最少字数，过短的重合多为常用词
+)
+
+// Span 候选文本中与参考文本重合的片段（偏移量为候选原文中的 rune 下标，左闭右开）
+type Span struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// Report 单次比对结果
+type Report struct {
+	Reference        string  `json:"reference"`          // 参考文本标识（source 或项目 ID）
+	Score            float64 `json:"score"`              // 综合相似度 0-1，越高越接近参考文本
+	ShingleOverlap   float64 `json:"shingle_overlap"`    // 候选文本 n-gram 在参考文本中出现的比例
+	CoveredRatio     float64 `json:"covered_ratio"`      // 被重合片段覆盖的字数比例
+	LongestCommon    int     `json:"longest_common"`     // 最长公共子串字数
+	LongestCommonStr string  `json:"longest_common_str"` // 最长公共子串内容
+	Spans            []Span  `json:"spans"`              // 重合片段（用于前端高亮）
+}
+
+// normalized 归一化后的文本：只保留字母和数字，并记录每个字符在原文中的 rune 下标
+type normalized struct {
+	runes     []rune
+	positions []int
+	original  []rune
+}
+
+// normalize 去掉空白、标点和表情，英文转小写，避免换行/标点差异掩盖照搬
+func normalize(text string) normalized {
+	original := []rune(text)
+	n := normalized{
+		runes:     make([]rune, 0, len(original)),
+		positions: make([]int, 0, len(original)),
+		original:  original,
+	}
+	for i, r := range original {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			n.runes = append(n.runes, unicode.ToLower(r))
+			n.positions = append(n.positions, i)
+		}
+	}
+	return n
+}
+
+// shingles 提取 n-gram 集合
+func shingles(runes []rune, size int) map[string]struct{} {
+	set := make(map[string]struct{})
+	if len(runes) < size {
+		return set
+	}
+	for i := 0; i+size <= len(runes); i++ {
+		set[string(runes[i:i+size])] = struct{}{}
+	}
+	return set
+}
+
+// Jaccard 计算两段文本 n-gram 集合的 Jaccard 相似度（对称），适合比较同类文本之间的雷同程度
+func Jaccard(a, b string, size int) float64 {
+	if size <= 0 {
+		size = DefaultShingleSize
+	}
+	sa := shingles(normalize(a).runes, size)
+	sb := shingles(normalize(b).runes, size)
+	if len(sa) == 0 || len(sb) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for g := range sa {
+		if _, ok := sb[g]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(sa)+len(sb)-intersection)
+}
+
+// Compare 比较候选文本与参考文本，返回重合度和高亮片段
+// 综合得分 = 0.6 × 重合片段覆盖率 + 0.4 × n-gram 包含率：
+// 覆盖率反映"整句照搬"，包含率反映"换序拼接"，两者结合比单一指标更难被绕过
+func Compare(candidate, reference, referenceLabel string) Report {
+	cand := normalize(candidate)
+	ref := normalize(reference)
+
+	report := Report{Reference: referenceLabel, Spans: []Span{}}
+	if len(cand.runes) == 0 || len(ref.runes) == 0 {
+		return report
+	}
+
+	// n-gram 包含率：候选文本的 n-gram 有多少出现在参考文本中（非对称，改写稿短于原文时也准确）
+	candShingles := shingles(cand.runes, DefaultShingleSize)
+	refShingles := shingles(ref.runes, DefaultShingleSize)
+	if len(candShingles) > 0 {
+		shared := 0
+		for g := range candShingles {
+			if _, ok := refShingles[g]; ok {
+				shared++
+			}
+		}
+		report.ShingleOverlap = float64(shared) / float64(len(candShingles))
+	}
+
+	// 重合片段：标记候选文本中落在任意共享长 n-gram 内的字符，再合并为连续区间
+	report.Spans, report.CoveredRatio = overlapSpans(cand, ref, DefaultMinSpanSize)
+
+	length, end := longestCommonSubstring(cand.runes, ref.runes)
+	report.LongestCommon = length
+	if length > 0 {
+		report.LongestCommonStr = originalText(cand, end-length, end)
+	}
+
+	report.Score = 0.6*report.CoveredRatio + 0.4*report.ShingleOverlap
+	return report
+}
+
+// overlapSpans 找出候选文本中长度不少于 minSize 的重合片段，并计算覆盖率
+func overlapSpans(cand, ref normalized, minSize int) ([]Span, float64) {
+	spans := []Span{}
+	if len(cand.runes) < minSize || len(ref.runes) < minSize {
+		return spans, 0
+	}
+
+	refGrams := shingles(ref.runes, minSize)
+	covered := make([]bool, len(cand.runes))
+	for i := 0; i+minSize <= len(cand.runes); i++ {
+		if _, ok := refGrams[string(cand.runes[i:i+minSize])]; ok {
+			for j := i; j < i+minSize; j++ {
+				covered[j] = true
+			}
+		}
+	}
+
+	coveredCount := 0
+	start := -1
+	for i := 0; i <= len(covered); i++ {
+		if i < len(covered) && covered[i] {
+			coveredCount++
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			spans = append(spans, Span{
+				Start: cand.positions[start],
+				End:   cand.positions[i-1] + 1,
+				Text:  originalText(cand, start, i),
+			})
+			start = -1
+		}
+	}
+
+	return spans, float64(coveredCount) / float64(len(cand.runes))
+}
+
+// longestCommonSubstring 返回最长公共子串长度及其在 a 中的结束下标（归一化后的下标）
+// 使用滚动数组的 O(n*m) 动态规划，文案长度在几千字以内时足够快
+func longestCommonSubstring(a, b []rune) (int, int) {
+	if len(a) == 0 || len(b) == 0 {
+		return 0, 0
+	}
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	best, bestEnd := 0, 0
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				curr[j] = prev[j-1] + 1
+				if curr[j] > best {
+					best = curr[j]
+					bestEnd = i
+				}
+			} else {
+				curr[j] = 0
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return best, bestEnd
+}
+
+// originalText 将归一化区间 [start, end) 映射回候选原文（保留其间的标点和空白）
+func originalText(n normalized, start, end int) string {
+	if start >= end {
+		return ""
+	}
+	return string(n.original[n.positions[start] : n.positions[end-1]+1])
+}
+
+// Reference 待比对的参考文本
+type Reference struct {
+	Label string // 标识，如 "source" 或项目 ID
+	Text  string
+}
+
+// OriginalityReport 候选文本与一组参考文本的比对汇总
+type OriginalityReport struct {
+	Score     float64  `json:"score"`     // 与所有参考文本比对中的最高得分
+	Threshold float64  `json:"threshold"` // 判定阈值
+	Exceeded  bool     `json:"exceeded"`  // 是否超过阈值（过于接近参考文本）
+	Matches   []Report `json:"matches"`   // 各参考文本的比对结果，按得分从高到低
+}
+
+// Check 将候选文本与多个参考文本比对，只保留有实际重合的结果
+func Check(candidate string, references []Reference, threshold float64) OriginalityReport {
+	result := OriginalityReport{Threshold: threshold, Matches: []Report{}}
+
+	for _, ref := range references {
+		report := Compare(candidate, ref.Text, ref.Label)
+		if report.Score == 0 && len(report.Spans) == 0 {
+			continue
+		}
+		result.Matches = append(result.Matches, report)
+		if report.Score > result.Score {
+			result.Score = report.Score
+		}
+	}
+
+	sort.SliceStable(result.Matches, func(i, j int) bool {
+		return result.Matches[i].Score > result.Matches[j].Score
+	})
+	result.Exceeded = threshold > 0 && result.Score > threshold
+	return result
+}
+
+// SpanTexts 提取报告中所有重合片段的文本（去重，按出现顺序）
+func (r OriginalityReport) SpanTexts() []string {
+	texts := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, m := range r.Matches {
+		for _, s := range m.Spans {
+			if !seen[s.Text] {
+				seen[s.Text] = true
+				texts = append(texts, s.Text)
+			}
+		}
+	}
+	return texts
+}
@@ -0,0 +1,140 @@
+package similarity
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestJaccard(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		size int
+		want float64
+	}{
+		{name: "相同文本", a: "今天天气很好", b: "今天天气很好", size: 3, want: 1},
+		{name: "完全不同", a: "今天天气很好", b: "明日有雨出门", size: 3, want: 0},
+		{name: "忽略大小写和标点", a: "Hello, World!", b: "hello world", size: 3, want: 1},
+		{name: "短于 n-gram", a: "ab", b: "ab", size: 3, want: 0},
+		{name: "部分重合", a: "abcd", b: "abce", size: 3, want: 1.0 / 3},
+		{name: "size 非正数时使用默认值", a: "abcd", b: "abce", size: 0, want: 1.0 / 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Jaccard(tt.a, tt.b, tt.size)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Jaccard(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if rev := Jaccard(tt.b, tt.a, tt.size); math.Abs(rev-got) > 1e-9 {
+				t.Errorf("Jaccard is not symmetric: %v vs %v", got, rev)
+			}
+		})
+	}
+}
+
+func TestLongestCommonSubstring(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		length  int
+		wantEnd int
+	}{
+		{name: "中间重合", a: "xxabcdyy", b: "zabcdz", length: 4, wantEnd: 6},
+		{name: "中文", a: "春眠不觉晓", b: "不觉晓处处", length: 3, wantEnd: 5},
+		{name: "没有重合", a: "abc", b: "xyz", length: 0, wantEnd: 0},
+		{name: "空文本", a: "", b: "abc", length: 0, wantEnd: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			length, end := longestCommonSubstring([]rune(tt.a), []rune(tt.b))
+			if length != tt.length || end != tt.wantEnd {
+				t.Errorf("longestCommonSubstring = (%d, %d), want (%d, %d)", length, end, tt.length, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestOverlapSpans(t *testing.T) {
+	tests := []struct {
+		name      string
+		candidate string
+		reference string
+		spans     []Span
+		covered   float64
+	}{
+		{
+			name:      "偏移量映射回原文",
+			candidate: "xx 12345678 yy",
+			reference: "12345678",
+			spans:     []Span{{Start: 3, End: 11, Text: "12345678"}},
+			covered:   8.0 / 12,
+		},
+		{
+			name:      "片段内保留标点",
+			candidate: "abcd,efgh!",
+			reference: "abcdefgh",
+			spans:     []Span{{Start: 0, End: 9, Text: "abcd,efgh"}},
+			covered:   1,
+		},
+		{
+			name:      "中文按 rune 计算偏移",
+			candidate: "前言：春眠不觉晓处处闻啼鸟",
+			reference: "春眠不觉晓，处处闻啼鸟",
+			spans:     []Span{{Start: 3, End: 13, Text: "春眠不觉晓处处闻啼鸟"}},
+			covered:   10.0 / 12,
+		},
+		{
+			name:      "多个片段",
+			candidate: "abcdefgh---zzzz---ijklmnop",
+			reference: "abcdefgh ijklmnop",
+			spans: []Span{
+				{Start: 0, End: 8, Text: "abcdefgh"},
+				{Start: 18, End: 26, Text: "ijklmnop"},
+			},
+			covered: 16.0 / 20,
+		},
+		{
+			name:      "重合短于最小长度",
+			candidate: "abcdefg xyz",
+			reference: "abcdefg",
+			spans:     []Span{},
+			covered:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans, covered := overlapSpans(normalize(tt.candidate), normalize(tt.reference), DefaultMinSpanSize)
+			if !reflect.DeepEqual(spans, tt.spans) {
+				t.Errorf("spans = %+v, want %+v", spans, tt.spans)
+			}
+			if math.Abs(covered-tt.covered) > 1e-9 {
+				t.Errorf("covered = %v, want %v", covered, tt.covered)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	refs := []Reference{
+		{Label: "other", Text: "完全无关的内容"},
+		{Label: "source", Text: "春眠不觉晓，处处闻啼鸟"},
+	}
+
+	report := Check("前言：春眠不觉晓处处闻啼鸟", refs, 0.5)
+	if len(report.Matches) != 1 || report.Matches[0].Reference != "source" {
+		t.Fatalf("matches = %+v, want only source", report.Matches)
+	}
+	if !report.Exceeded {
+		t.Errorf("score %v should exceed threshold 0.5", report.Score)
+	}
+	if got := report.Matches[0].LongestCommonStr; got != "春眠不觉晓处处闻啼鸟" {
+		t.Errorf("LongestCommonStr = %q", got)
+	}
+	if got := report.SpanTexts(); !reflect.DeepEqual(got, []string{"春眠不觉晓处处闻啼鸟"}) {
+		t.Errorf("SpanTexts = %q", got)
+	}
+}
@@ -0,0 +1,477 @@
+package tts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// 音频格式
+const (
+	FormatMP3 = "mp3"
+	FormatWAV = "wav"
+)
+
+// AudioInfo 音频基本信息
+type AudioInfo struct {
+	Format     string `json:"format"`      // mp3/wav
+	SampleRate int    `json:"sample_rate"` // 采样率
+	Channels   int    `json:"channels"`    // 声道数
+	DurationMs int64  `json:"duration_ms"` // 时长（毫秒）
+}
+
+// ContentType 音频格式对应的 MIME 类型
+func ContentType(format string) string {
+	switch format {
+	case FormatWAV:
+		return "audio/wav"
+	case FormatMP3:
+		return "audio/mpeg"
+	}
+	return "application/octet-stream"
+}
+
+// DetectFormat 根据文件头识别音频格式，无法识别时返回空字符串
+func DetectFormat(data []byte) string {
+	if len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE" {
+		return FormatWAV
+	}
+	if len(data) >= 3 && string(data[0:3]) == "ID3" {
+		return FormatMP3
+	}
+	if len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 {
+		return FormatMP3
+	}
+	return ""
+}
+
+// ProbeAudio 解析音频格式、采样率和时长
+func ProbeAudio(data []byte) (*AudioInfo, error) {
+	switch DetectFormat(data) {
+	case FormatWAV:
+		w, err := parseWAV(data)
+		if err != nil {
+			return nil, err
+		}
+		return w.info(), nil
+	case FormatMP3:
+		m, err := parseMP3(data)
+		if err != nil {
+			return nil, err
+		}
+		return m.info(), nil
+	}
+	return nil, fmt.Errorf("无法识别的音频格式")
+}
+
+// ConcatAudio 将多段同格式音频拼接为一个文件，返回拼接结果和每段的信息
+// WAV 要求各段采样参数一致；MP3 会去掉各段的 ID3 标签和 Xing/Info 帧，只保留音频帧
+func ConcatAudio(parts [][]byte) ([]byte, []*AudioInfo, error) {
+	audio, infos, _, err := ConcatAudioWithGaps(parts, nil)
+	return audio, infos, err
+}
+
+// ConcatAudioWithGaps 拼接音频，并在第 i 段之后插入 gapsMs[i] 毫秒静音（gapsMs 可为 nil，最后一段之后不插入）
+// 返回的 AudioInfo.DurationMs 为各段自身时长；第三个返回值为每段之后实际插入的静音时长（MP3 按帧取整）
+func ConcatAudioWithGaps(parts [][]byte, gapsMs []int) ([]byte, []*AudioInfo, []int64, error) {
+	if len(parts) == 0 {
+		return nil, nil, nil, fmt.Errorf("没有需要拼接的音频")
+	}
+	gapAt := func(i int) int {
+		if i < len(gapsMs) && i < len(parts)-1 && gapsMs[i] > 0 {
+			return gapsMs[i]
+		}
+		return 0
+	}
+	gaps := make([]int64, len(parts))
+
+	format := DetectFormat(parts[0])
+	infos := make([]*AudioInfo, len(parts))
+
+	switch format {
+	case FormatWAV:
+		wavs := make([]*wavAudio, len(parts))
+		for i, p := range parts {
+			if DetectFormat(p) != FormatWAV {
+				return nil, nil, nil, fmt.Errorf("第 %d 段音频格式与第 1 段不一致", i+1)
+			}
+			w, err := parseWAV(p)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("解析第 %d 段音频失败: %w", i+1, err)
+			}
+			if i > 0 && !w.sameFormat(wavs[0]) {
+				return nil, nil, nil, fmt.Errorf("第 %d 段音频采样参数与第 1 段不一致", i+1)
+			}
+			wavs[i] = w
+			infos[i] = w.info()
+		}
+		silences := make([]int, len(parts))
+		for i := range parts {
+			if ms := gapAt(i); ms > 0 {
+				silences[i] = wavs[0].silenceBytes(ms)
+				gaps[i] = int64(ms)
+			}
+		}
+		return joinWAV(wavs, silences), infos, gaps, nil
+
+	case FormatMP3:
+		var buf bytes.Buffer
+		var first *mp3Audio
+		for i, p := range parts {
+			if DetectFormat(p) != FormatMP3 {
+				return nil, nil, nil, fmt.Errorf("第 %d 段音频格式与第 1 段不一致", i+1)
+			}
+			m, err := parseMP3(p)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("解析第 %d 段音频失败: %w", i+1, err)
+			}
+			if first == nil {
+				first = m
+			} else if m.sampleRate != first.sampleRate {
+				return nil, nil, nil, fmt.Errorf("第 %d 段音频采样率与第 1 段不一致", i+1)
+			}
+			buf.Write(m.frames)
+			infos[i] = m.info()
+			if ms := gapAt(i); ms > 0 {
+				frames, duration := first.silence(ms)
+				buf.Write(frames)
+				gaps[i] = duration
+			}
+		}
+		return buf.Bytes(), infos, gaps, nil
+	}
+
+	return nil, nil, nil, fmt.Errorf("无法识别的音频格式")
+}
+
+// ---------- WAV ----------
+
+type wavAudio struct {
+	audioFormat   uint16
+	channels      uint16
+	sampleRate    uint32
+	bitsPerSample uint16
+	pcm           []byte
+}
+
+// parseWAV 解析 RIFF/WAVE，兼容流式输出时 data 块长度为 0 或 0xFFFFFFFF 的情况
+func parseWAV(data []byte) (*wavAudio, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("WAV 数据过短")
+	}
+	w := &wavAudio{}
+	hasFmt := false
+	pos := 12
+
+	for pos+8 <= len(data) {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := pos + 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 || body+16 > len(data) {
+				return nil, fmt.Errorf("WAV fmt 块不完整")
+			}
+			w.audioFormat = binary.LittleEndian.Uint16(data[body:])
+			w.channels = binary.LittleEndian.Uint16(data[body+2:])
+			w.sampleRate = binary.LittleEndian.Uint32(data[body+4:])
+			w.bitsPerSample = binary.LittleEndian.Uint16(data[body+14:])
+			hasFmt = true
+		case "data":
+			end := body + size
+			if size == 0 || size == 0xFFFFFFFF || end > len(data) {
+				end = len(data)
+			}
+			w.pcm = data[body:end]
+		}
+
+		if w.pcm != nil {
+			break
+		}
+		// 块长度为奇数时有 1 字节填充
+		pos = body + size + size%2
+	}
+
+	if !hasFmt || w.pcm == nil {
+		return nil, fmt.Errorf("WAV 缺少 fmt 或 data 块")
+	}
+	if w.channels == 0 || w.sampleRate == 0 || w.bitsPerSample == 0 {
+		return nil, fmt.Errorf("WAV 采样参数无效")
+	}
+	// 丢弃不完整的采样帧，保证拼接后声道对齐
+	if blockAlign := w.blockAlign(); blockAlign > 0 {
+		w.pcm = w.pcm[:len(w.pcm)-len(w.pcm)%blockAlign]
+	}
+	return w, nil
+}
+
+func (w *wavAudio) blockAlign() int {
+	return int(w.channels) * int(w.bitsPerSample) / 8
+}
+
+func (w *wavAudio) sameFormat(other *wavAudio) bool {
+	return w.audioFormat == other.audioFormat && w.channels == other.channels &&
+		w.sampleRate == other.sampleRate && w.bitsPerSample == other.bitsPerSample
+}
+
+func (w *wavAudio) info() *AudioInfo {
+	bytesPerSecond := int64(w.sampleRate) * int64(w.blockAlign())
+	var duration int64
+	if bytesPerSecond > 0 {
+		duration = int64(len(w.pcm)) * 1000 / bytesPerSecond
+	}
+	return &AudioInfo{
+		Format:     FormatWAV,
+		SampleRate: int(w.sampleRate),
+		Channels:   int(w.channels),
+		DurationMs: duration,
+	}
+}
+
+// silenceBytes 指定时长的静音 PCM 字节数（按采样帧对齐）
+func (w *wavAudio) silenceBytes(ms int) int {
+	return int(int64(w.sampleRate)*int64(ms)/1000) * w.blockAlign()
+}
+
+// joinWAV 以第一段的采样参数写出标准 44 字节头，并顺序拼接所有 PCM 数据，
+// silences[i] 为第 i 段之后插入的静音字节数（PCM 全零即静音）
+func joinWAV(parts []*wavAudio, silences []int) []byte {
+	dataSize := 0
+	for i, p := range parts {
+		dataSize += len(p.pcm) + silences[i]
+	}
+	return encodeWAV(parts[0], dataSize, func(buf *bytes.Buffer) {
+		for i, p := range parts {
+			buf.Write(p.pcm)
+			if silences[i] > 0 {
+				buf.Write(make([]byte, silences[i]))
+			}
+		}
+	})
+}
+
+func encodeWAV(format *wavAudio, dataSize int, writeData func(*bytes.Buffer)) []byte {
+	var buf bytes.Buffer
+	buf.Grow(44 + dataSize)
+	le := binary.LittleEndian
+
+	buf.WriteString("RIFF")
+	binary.Write(&buf, le, uint32(36+dataSize))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(&buf, le, uint32(16))
+	binary.Write(&buf, le, format.audioFormat)
+	binary.Write(&buf, le, format.channels)
+	binary.Write(&buf, le, format.sampleRate)
+	binary.Write(&buf, le, format.sampleRate*uint32(format.blockAlign()))
+	binary.Write(&buf, le, uint16(format.blockAlign()))
+	binary.Write(&buf, le, format.bitsPerSample)
+	buf.WriteString("data")
+	binary.Write(&buf, le, uint32(dataSize))
+	writeData(&buf)
+	return buf.Bytes()
+}
+
+// ScaleWAVVolume 按倍数调整 16 位 PCM WAV 的音量（超出范围时削波），
+// 返回是否已处理；其他格式原样返回
+func ScaleWAVVolume(data []byte, gain float64) ([]byte, bool) {
+	if gain == 1 || DetectFormat(data) != FormatWAV {
+		return data, false
+	}
+	w, err := parseWAV(data)
+	if err != nil || w.audioFormat != 1 || w.bitsPerSample != 16 {
+		return data, false
+	}
+
+	pcm := make([]byte, len(w.pcm))
+	for i := 0; i+1 < len(w.pcm); i += 2 {
+		v := float64(int16(binary.LittleEndian.Uint16(w.pcm[i:]))) * gain
+		if v > math.MaxInt16 {
+			v = math.MaxInt16
+		} else if v < math.MinInt16 {
+			v = math.MinInt16
+		}
+		binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(v)))
+	}
+	return encodeWAV(w, len(pcm), func(buf *bytes.Buffer) { buf.Write(pcm) }), true
+}
+
+// ---------- MP3 ----------
+
+type mp3Audio struct {
+	frames     []byte // 去掉标签和 VBR 信息帧后的音频帧
+	header     []byte // 第一个音频帧的帧头，用于生成同参数的静音帧
+	sampleRate int
+	channels   int
+	samples    int64
+}
+
+// silence 生成约 ms 毫秒的静音帧（与首帧同版本/层/采样率/声道，边信息全零即解码为静音），返回帧数据和实际时长
+func (m *mp3Audio) silence(ms int) ([]byte, int64) {
+	header := []byte{m.header[0], m.header[1] | 0x01, m.header[2] &^ 0x02, m.header[3]} // 去掉 CRC 和填充位
+	f, ok := parseMP3Header(header)
+	if !ok {
+		return nil, 0
+	}
+	count := int((int64(ms)*int64(f.sampleRate) + int64(f.samples)*500) / (int64(f.samples) * 1000))
+	frame := make([]byte, f.length)
+	copy(frame, header)
+
+	var buf bytes.Buffer
+	for i := 0; i < count; i++ {
+		buf.Write(frame)
+	}
+	return buf.Bytes(), int64(count) * int64(f.samples) * 1000 / int64(f.sampleRate)
+}
+
+func (m *mp3Audio) info() *AudioInfo {
+	var duration int64
+	if m.sampleRate > 0 {
+		duration = m.samples * 1000 / int64(m.sampleRate)
+	}
+	return &AudioInfo{
+		Format:     FormatMP3,
+		SampleRate: m.sampleRate,
+		Channels:   m.channels,
+		DurationMs: duration,
+	}
+}
+
+// MPEG 音频帧头参数表（kbps / Hz）
+var (
+	mp3BitratesV1 = [3][16]int{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0}, // Layer I
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},    // Layer II
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},     // Layer III
+	}
+	mp3BitratesV2 = [3][16]int{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0}, // Layer I
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},      // Layer II
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},      // Layer III
+	}
+	mp3SampleRates = map[int][3]int{
+		3: {44100, 48000, 32000}, // MPEG-1
+		2: {22050, 24000, 16000}, // MPEG-2
+		0: {11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+// mp3Frame 帧头解析结果
+type mp3Frame struct {
+	length     int
+	samples    int
+	sampleRate int
+	channels   int
+}
+
+// parseMP3Header 解析 4 字节帧头，无效时返回 false
+func parseMP3Header(h []byte) (mp3Frame, bool) {
+	if len(h) < 4 || h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+	version := int(h[1]>>3) & 3
+	layer := int(h[1]>>1) & 3
+	bitrateIndex := int(h[2] >> 4)
+	rateIndex := int(h[2]>>2) & 3
+	padding := int(h[2]>>1) & 1
+	channelMode := int(h[3] >> 6)
+
+	rates, ok := mp3SampleRates[version]
+	if !ok || layer == 0 || rateIndex == 3 || bitrateIndex == 0 || bitrateIndex == 15 {
+		return mp3Frame{}, false
+	}
+	sampleRate := rates[rateIndex]
+	layerIndex := 3 - layer // Layer I=0, II=1, III=2
+
+	var bitrate int
+	if version == 3 {
+		bitrate = mp3BitratesV1[layerIndex][bitrateIndex] * 1000
+	} else {
+		bitrate = mp3BitratesV2[layerIndex][bitrateIndex] * 1000
+	}
+
+	f := mp3Frame{sampleRate: sampleRate, channels: 2}
+	if channelMode == 3 {
+		f.channels = 1
+	}
+	switch {
+	case layerIndex == 0:
+		f.samples = 384
+		f.length = (12*bitrate/sampleRate + padding) * 4
+	case layerIndex == 2 && version != 3:
+		f.samples = 576
+		f.length = 72*bitrate/sampleRate + padding
+	default:
+		f.samples = 1152
+		f.length = 144*bitrate/sampleRate + padding
+	}
+	return f, f.length > 4
+}
+
+// parseMP3 跳过 ID3v2/ID3v1 标签，逐帧扫描统计采样数
+func parseMP3(data []byte) (*mp3Audio, error) {
+	pos := 0
+	if len(data) >= 10 && string(data[0:3]) == "ID3" {
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		pos = 10 + size
+		if data[5]&0x10 != 0 {
+			pos += 10 // footer
+		}
+	}
+	end := len(data)
+	if end-pos >= 128 && string(data[end-128:end-125]) == "TAG" {
+		end -= 128
+	}
+
+	m := &mp3Audio{}
+	var frames bytes.Buffer
+	first := true
+
+	for pos+4 <= end {
+		f, ok := parseMP3Header(data[pos:])
+		if !ok {
+			// 跳过帧间的垃圾字节，重新寻找同步头
+			pos++
+			continue
+		}
+		frameEnd := pos + f.length
+		if frameEnd > end {
+			frameEnd = end
+		}
+		frame := data[pos:frameEnd]
+
+		// 第一帧可能是 Xing/Info/VBRI 信息帧，拼接后出现在中间会被当作一段静音，直接丢弃
+		if first && isVBRInfoFrame(frame) {
+			first = false
+			pos = frameEnd
+			continue
+		}
+		first = false
+
+		if m.sampleRate == 0 {
+			m.sampleRate = f.sampleRate
+			m.channels = f.channels
+			m.header = frame[:4]
+		}
+		m.samples += int64(f.samples)
+		frames.Write(frame)
+		pos = frameEnd
+	}
+
+	if m.samples == 0 {
+		return nil, fmt.Errorf("MP3 中没有有效的音频帧")
+	}
+	m.frames = frames.Bytes()
+	return m, nil
+}
+
+func isVBRInfoFrame(frame []byte) bool {
+	n := len(frame)
+	if n > 64 {
+		n = 64
+	}
+	head := frame[:n]
+	return bytes.Contains(head, []byte("Xing")) || bytes.Contains(head, []byte("Info")) || bytes.Contains(head, []byte("VBRI"))
+}
@@ -0,0 +1,226 @@
+package tts
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// testWAV 生成 PCM WAV
+func testWAV(sampleRate uint32, channels uint16, pcm []byte) []byte {
+	format := &wavAudio{audioFormat: 1, channels: channels, sampleRate: sampleRate, bitsPerSample: 16}
+	return encodeWAV(format, len(pcm), func(buf *bytes.Buffer) { buf.Write(pcm) })
+}
+
+// testMP3Frame 生成指定帧头的 MP3 帧，帧体全零
+func testMP3Frame(t *testing.T, header ...byte) []byte {
+	t.Helper()
+	f, ok := parseMP3Header(header)
+	if !ok {
+		t.Fatalf("invalid mp3 header % x", header)
+	}
+	frame := make([]byte, f.length)
+	copy(frame, header)
+	return frame
+}
+
+func TestConcatAudioWithGapsWAV(t *testing.T) {
+	a := bytes.Repeat([]byte{1}, 1600) // 8kHz 单声道 100ms
+	b := bytes.Repeat([]byte{2}, 800)  // 50ms
+	stereo := bytes.Repeat([]byte{3}, 3200)
+
+	tests := []struct {
+		name      string
+		parts     [][]byte
+		gapsMs    []int
+		wantPCM   []byte
+		wantGaps  []int64
+		durations []int64
+		wantErr   bool
+	}{
+		{
+			name:      "无静音",
+			parts:     [][]byte{testWAV(8000, 1, a), testWAV(8000, 1, b)},
+			wantPCM:   append(append([]byte{}, a...), b...),
+			wantGaps:  []int64{0, 0},
+			durations: []int64{100, 50},
+		},
+		{
+			name:      "段间静音，最后一段之后不插入",
+			parts:     [][]byte{testWAV(8000, 1, a), testWAV(8000, 1, b)},
+			gapsMs:    []int{100, 100},
+			wantPCM:   bytes.Join([][]byte{a, make([]byte, 1600), b}, nil),
+			wantGaps:  []int64{100, 0},
+			durations: []int64{100, 50},
+		},
+		{
+			name:      "立体声静音按采样帧对齐",
+			parts:     [][]byte{testWAV(8000, 2, stereo), testWAV(8000, 2, stereo)},
+			gapsMs:    []int{10},
+			wantPCM:   bytes.Join([][]byte{stereo, make([]byte, 320), stereo}, nil),
+			wantGaps:  []int64{10, 0},
+			durations: []int64{100, 100},
+		},
+		{
+			name:    "采样率不一致",
+			parts:   [][]byte{testWAV(8000, 1, a), testWAV(16000, 1, a)},
+			wantErr: true,
+		},
+		{
+			name:    "格式不一致",
+			parts:   [][]byte{testWAV(8000, 1, a), testMP3Frame(t, 0xFF, 0xFB, 0x90, 0x00)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, infos, gaps, err := ConcatAudioWithGaps(tt.parts, tt.gapsMs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConcatAudioWithGaps: %v", err)
+			}
+
+			w, err := parseWAV(out)
+			if err != nil {
+				t.Fatalf("parse output: %v", err)
+			}
+			if !bytes.Equal(w.pcm, tt.wantPCM) {
+				t.Errorf("pcm length = %d, want %d", len(w.pcm), len(tt.wantPCM))
+			}
+			if !reflect.DeepEqual(gaps, tt.wantGaps) {
+				t.Errorf("gaps = %v, want %v", gaps, tt.wantGaps)
+			}
+			for i, info := range infos {
+				if info.DurationMs != tt.durations[i] {
+					t.Errorf("part %d duration = %d, want %d", i, info.DurationMs, tt.durations[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConcatAudioWithGapsMP3(t *testing.T) {
+	// MPEG-1 Layer III 128kbps 44.1kHz 立体声，每帧 417 字节、1152 个采样
+	frame := testMP3Frame(t, 0xFF, 0xFB, 0x90, 0x00)
+	// 同参数但带 CRC 和填充位，每帧 418 字节
+	padded := testMP3Frame(t, 0xFF, 0xFA, 0x92, 0x00)
+	// 48kHz
+	other := testMP3Frame(t, 0xFF, 0xFB, 0x94, 0x00)
+
+	info := append([]byte{}, frame...)
+	copy(info[36:], "Info")
+	id3 := []byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 0}
+
+	frames := func(f []byte, n int) []byte { return bytes.Repeat(f, n) }
+
+	tests := []struct {
+		name     string
+		parts    [][]byte
+		gapsMs   []int
+		want     []byte
+		wantGaps []int64
+		wantErr  bool
+	}{
+		{
+			name:     "无静音",
+			parts:    [][]byte{frames(frame, 2), frames(frame, 3)},
+			want:     frames(frame, 5),
+			wantGaps: []int64{0, 0},
+		},
+		{
+			name:     "静音按帧取整",
+			parts:    [][]byte{frames(frame, 2), frames(frame, 3)},
+			gapsMs:   []int{500},
+			want:     bytes.Join([][]byte{frames(frame, 2), frames(frame, 19), frames(frame, 3)}, nil),
+			wantGaps: []int64{496, 0},
+		},
+		{
+			name:     "静音帧去掉 CRC 和填充位",
+			parts:    [][]byte{padded, frame},
+			gapsMs:   []int{26},
+			want:     bytes.Join([][]byte{padded, frame, frame}, nil),
+			wantGaps: []int64{26, 0},
+		},
+		{
+			name:     "去掉 ID3 标签和 Info 帧",
+			parts:    [][]byte{bytes.Join([][]byte{id3, info, frames(frame, 2)}, nil), frame},
+			want:     frames(frame, 3),
+			wantGaps: []int64{0, 0},
+		},
+		{
+			name:    "采样率不一致",
+			parts:   [][]byte{frame, other},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, gaps, err := ConcatAudioWithGaps(tt.parts, tt.gapsMs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ConcatAudioWithGaps: %v", err)
+			}
+			if !bytes.Equal(out, tt.want) {
+				t.Errorf("output length = %d, want %d", len(out), len(tt.want))
+			}
+			if !reflect.DeepEqual(gaps, tt.wantGaps) {
+				t.Errorf("gaps = %v, want %v", gaps, tt.wantGaps)
+			}
+		})
+	}
+}
+
+func TestProbeAudio(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    AudioInfo
+		wantErr bool
+	}{
+		{
+			name: "WAV",
+			data: testWAV(8000, 2, make([]byte, 6400)),
+			want: AudioInfo{Format: FormatWAV, SampleRate: 8000, Channels: 2, DurationMs: 200},
+		},
+		{
+			name: "MP3",
+			data: bytes.Repeat(testMP3Frame(t, 0xFF, 0xFB, 0x90, 0xC0), 10),
+			want: AudioInfo{Format: FormatMP3, SampleRate: 44100, Channels: 1, DurationMs: 261},
+		},
+		{
+			name:    "未知格式",
+			data:    []byte("not audio"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ProbeAudio(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProbeAudio: %v", err)
+			}
+			if *info != tt.want {
+				t.Errorf("ProbeAudio = %+v, want %+v", *info, tt.want)
+			}
+		})
+	}
+}
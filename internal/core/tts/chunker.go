@@ -0,0 +1,111 @@
+package tts
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 句末标点：在这些字符之后切分句子
+var sentenceEnders = map[rune]bool{
+	'。': true, '！': true, '？': true, '；': true, '…': true,
+	'!': true, '?': true, ';': true, '\n': true,
+}
+
+// 句内停顿标点：单句超长时优先在这些字符之后切分
+var clauseBreakers = map[rune]bool{
+	'，': true, '、': true, '：': true, ',': true, ':': true, ' ': true,
+}
+
+// 紧跟在句末标点之后、应归入上一句的字符（引号、括号、连续标点）
+var trailingClosers = map[rune]bool{
+	'”': true, '’': true, '」': true, '』': true, '）': true, ')': true, '"': true, '\'': true,
+	'。': true, '！': true, '？': true, '…': true, '!': true, '?': true,
+}
+
+// SplitSentences 按句末标点切分文本，标点保留在句尾，空白句子会被丢弃
+func SplitSentences(text string) []string {
+	runes := []rune(text)
+	var sentences []string
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		if !sentenceEnders[runes[i]] {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && trailingClosers[runes[end]] {
+			end++
+		}
+		if s := strings.TrimSpace(string(runes[start:end])); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = end
+		i = end - 1
+	}
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// SplitText 将长文本切分为不超过 maxChars 字的分段：
+// 尽量把完整句子合并到同一段；单句超长时在逗号等停顿处切开，仍超长才按字数硬切
+func SplitText(text string, maxChars int) []string {
+	if maxChars <= 0 {
+		return []string{strings.TrimSpace(text)}
+	}
+
+	var chunks []string
+	var current []rune
+	flush := func() {
+		if s := strings.TrimSpace(string(current)); s != "" {
+			chunks = append(chunks, s)
+		}
+		current = current[:0]
+	}
+
+	for _, sentence := range SplitSentences(text) {
+		for _, piece := range splitLongSentence([]rune(sentence), maxChars) {
+			if len(current) > 0 && len(current)+len(piece) > maxChars {
+				flush()
+			}
+			current = append(current, piece...)
+		}
+	}
+	flush()
+	return chunks
+}
+
+// splitLongSentence 将超过 maxChars 的句子在停顿标点处切开
+func splitLongSentence(sentence []rune, maxChars int) [][]rune {
+	var pieces [][]rune
+	for len(sentence) > maxChars {
+		cut := -1
+		for i := maxChars - 1; i > maxChars/2; i-- {
+			if clauseBreakers[sentence[i]] {
+				cut = i + 1
+				break
+			}
+		}
+		if cut < 0 {
+			cut = maxChars
+			// 避免把英文单词从中间切断
+			for i := maxChars; i > maxChars/2; i-- {
+				if !isWordRune(sentence[i-1]) || !isWordRune(sentence[i]) {
+					cut = i
+					break
+				}
+			}
+		}
+		pieces = append(pieces, sentence[:cut])
+		sentence = sentence[cut:]
+	}
+	if len(sentence) > 0 {
+		pieces = append(pieces, sentence)
+	}
+	return pieces
+}
+
+func isWordRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,58 @@
+package tts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "句末标点", text: "你好。今天天气不错！", want: []string{"你好。", "今天天气不错！"}},
+		{name: "引号归入上一句", text: "他说：“走吧！”然后离开了。", want: []string{"他说：“走吧！”", "然后离开了。"}},
+		{name: "连续标点", text: "真的吗？！好吧", want: []string{"真的吗？！", "好吧"}},
+		{name: "空行丢弃", text: "第一行\n\n第二行", want: []string{"第一行", "第二行"}},
+		{name: "英文", text: "Hi! How are you? Fine", want: []string{"Hi!", "How are you?", "Fine"}},
+		{name: "只有空白", text: " \n ", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitSentences(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxChars int
+		want     []string
+	}{
+		{name: "整句合并", text: "一二三。四五六。七八九。", maxChars: 8, want: []string{"一二三。四五六。", "七八九。"}},
+		{name: "不限长度", text: " 一二三。四五六。 ", maxChars: 0, want: []string{"一二三。四五六。"}},
+		{name: "长句在逗号处切开", text: "一二三四五，六七八九十。", maxChars: 8, want: []string{"一二三四五，", "六七八九十。"}},
+		{name: "没有停顿标点时硬切", text: "一二三四五六七八九十", maxChars: 4, want: []string{"一二三四", "五六七八", "九十"}},
+		{name: "英文在空格处切开", text: "hello world foo", maxChars: 8, want: []string{"hello", "world", "foo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitText(tt.text, tt.maxChars)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitText(%q, %d) = %q, want %q", tt.text, tt.maxChars, got, tt.want)
+			}
+			for _, chunk := range got {
+				if tt.maxChars > 0 && len([]rune(chunk)) > tt.maxChars {
+					t.Errorf("chunk %q exceeds %d chars", chunk, tt.maxChars)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"copycat/pkg/logger"
+)
+
+// 本地引擎
+const (
+	LocalEnginePiper  = "piper"     // Piper 神经网络 TTS，音色为模型目录下的 *.onnx 文件
+	LocalEngineEspeak = "espeak-ng" // eSpeak NG 共振峰合成，音质一般但无需模型文件
+
+	defaultLocalTimeout = 120 // 单段合成超时（秒）
+	localMaxChars       = 500 // 单段最大字数
+	espeakDefaultSpeed  = 175 // espeak-ng 默认语速（词/分钟）
+	espeakDefaultPitch  = 50  // espeak-ng 默认音调（0-99）
+)
+
+// Config 语音合成的服务端配置
+type Config struct {
+	Local LocalConfig `mapstructure:"local"`
+}
+
+// LocalConfig 本地引擎配置。可执行文件和模型目录只能由服务端配置文件指定，不接受用户输入
+type LocalConfig struct {
+	Engine    string `mapstructure:"engine"`     // piper / espeak-ng，为空表示未启用
+	Binary    string `mapstructure:"binary"`     // 可执行文件路径，为空时按引擎名在 PATH 中查找
+	ModelsDir string `mapstructure:"models_dir"` // piper 模型目录
+	Timeout   int    `mapstructure:"timeout"`    // 单段合成超时（秒）
+}
+
+// espeakVoices eSpeak NG 常用音色（+f3 为女声变体）
+var espeakVoices = []Voice{
+	{ID: "cmn", Name: "普通话-男声", Description: "eSpeak NG 普通话", Gender: "男", Languages: "中/英"},
+	{ID: "cmn+f3", Name: "普通话-女声", Description: "eSpeak NG 普通话", Gender: "女", Languages: "中/英"},
+	{ID: "yue", Name: "粤语-男声", Description: "eSpeak NG 粤语", Gender: "男", Languages: "粤语"},
+	{ID: "en-us", Name: "英语-男声", Description: "eSpeak NG 美式英语", Gender: "男", Languages: "英"},
+	{ID: "en-us+f3", Name: "英语-女声", Description: "eSpeak NG 美式英语", Gender: "女", Languages: "英"},
+}
+
+// LocalProvider 调用本机 TTS 引擎离线合成 WAV 音频
+type LocalProvider struct {
+	cfg LocalConfig
+}
+
+// NewLocalProvider 创建本地引擎服务商
+func NewLocalProvider(cfg LocalConfig) *LocalProvider {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultLocalTimeout
+	}
+	return &LocalProvider{cfg: cfg}
+}
+
+// Name 服务商标识
+func (p *LocalProvider) Name() string { return ProviderLocal }
+
+// Capabilities 服务商能力：espeak-ng 支持语速/音调/音量和 SSML，piper 只支持语速
+func (p *LocalProvider) Capabilities() Capabilities {
+	caps := Capabilities{
+		Formats: []string{FormatWAV},
+		Offline: true,
+	}
+	switch p.cfg.Engine {
+	case LocalEngineEspeak:
+		caps.Rate, caps.Pitch, caps.Volume, caps.SSML = true, true, true, true
+	case LocalEnginePiper:
+		caps.Rate = true
+	}
+	return caps
+}
+
+// Models 本地引擎只有一个模型，即配置的引擎本身；未配置时为空
+func (p *LocalProvider) Models() []Model {
+	switch p.cfg.Engine {
+	case LocalEnginePiper:
+		return []Model{{ID: LocalEnginePiper, Name: "Piper", Description: "本地神经网络语音合成", VoiceCount: len(p.piperVoices()), Languages: "取决于模型", MaxChars: localMaxChars}}
+	case LocalEngineEspeak:
+		return []Model{{ID: LocalEngineEspeak, Name: "eSpeak NG", Description: "本地共振峰语音合成", VoiceCount: len(espeakVoices), Languages: "中/粤/英", MaxChars: localMaxChars}}
+	}
+	return nil
+}
+
+// Voices 获取可用音色
+func (p *LocalProvider) Voices(model string) []Voice {
+	switch p.cfg.Engine {
+	case LocalEnginePiper:
+		return p.piperVoices()
+	case LocalEngineEspeak:
+		voices := make([]Voice, len(espeakVoices))
+		for i, v := range espeakVoices {
+			v.Model = LocalEngineEspeak
+			voices[i] = v
+		}
+		return voices
+	}
+	return nil
+}
+
+// piperVoices 列出模型目录下的 *.onnx 模型，文件名（如 zh_CN-huayan-medium）即音色ID
+func (p *LocalProvider) piperVoices() []Voice {
+	if p.cfg.ModelsDir == "" {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(p.cfg.ModelsDir, "*.onnx"))
+	if err != nil {
+		return nil
+	}
+	sort.Strings(files)
+
+	voices := make([]Voice, 0, len(files))
+	for _, f := range files {
+		id := strings.TrimSuffix(filepath.Base(f), ".onnx")
+		lang := strings.SplitN(id, "-", 2)[0]
+		languages := lang
+		if strings.HasPrefix(lang, "zh") {
+			languages = "中/英"
+		}
+		voices = append(voices, Voice{ID: id, Name: id, Description: "Piper 模型", Languages: languages, Model: LocalEnginePiper})
+	}
+	return voices
+}
+
+// Synthesize 调用本地引擎合成 WAV 音频：文本通过标准输入传入，避免命令行注入和长度限制
+func (p *LocalProvider) Synthesize(req SynthesisRequest) ([]byte, int, error) {
+	text, voice := req.Text, req.Voice
+	characters := utf8.RuneCountInString(StripMarkup(text))
+	if p.cfg.Engine != LocalEnginePiper && p.cfg.Engine != LocalEngineEspeak {
+		return nil, 0, fmt.Errorf("服务端未配置本地语音合成引擎")
+	}
+	binary := p.cfg.Binary
+	if binary == "" {
+		binary = p.cfg.Engine
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, 0, fmt.Errorf("本地语音合成引擎不可用: %w", err)
+	}
+
+	out, err := os.CreateTemp("", "copycat-tts-*.wav")
+	if err != nil {
+		return nil, 0, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	out.Close()
+	defer os.Remove(out.Name())
+
+	var args []string
+	switch p.cfg.Engine {
+	case LocalEnginePiper:
+		// 音色已通过列表校验，这里再确认不含路径，防止读取模型目录之外的文件
+		if voice == "" || filepath.Base(voice) != voice {
+			return nil, 0, fmt.Errorf("无效的音色: %s", voice)
+		}
+		args = []string{"--model", filepath.Join(p.cfg.ModelsDir, voice+".onnx"), "--output_file", out.Name()}
+		if req.Options.Rate != 0 {
+			args = append(args, "--length_scale", strconv.FormatFloat(1/req.Options.Rate, 'f', 3, 64))
+		}
+	case LocalEngineEspeak:
+		args = []string{"-v", voice, "-b", "1", "-m", "-w", out.Name()}
+		if req.Options.Rate != 0 {
+			args = append(args, "-s", strconv.Itoa(int(espeakDefaultSpeed*req.Options.Rate)))
+		}
+		if req.Options.Pitch != 0 {
+			args = append(args, "-p", strconv.Itoa(clampInt(int(espeakDefaultPitch*req.Options.Pitch), 0, 99)))
+		}
+		if req.Options.Volume != 0 {
+			// espeak-ng 振幅 0-200，默认 100 对应音量 50
+			args = append(args, "-a", strconv.Itoa(req.Options.Volume*2))
+		}
+		args = append(args, "--stdin")
+		// -m 模式按 SSML 解析；Capabilities 声明了 SSML，客户端传入的文本已转义
+		text = "<speak>" + text + "</speak>"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(p.cfg.Timeout)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	logger.Info("TTS 请求: provider=local, engine=%s, voice=%s, text_length=%d", p.cfg.Engine, voice, characters)
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > 500 {
+			msg = msg[:500]
+		}
+		logger.Error("本地 TTS 合成失败: %v, stderr=%s", err, msg)
+		return nil, 0, fmt.Errorf("本地语音合成失败: %w", err)
+	}
+
+	audio, err := os.ReadFile(out.Name())
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取合成结果失败: %w", err)
+	}
+	if DetectFormat(audio) != FormatWAV {
+		return nil, 0, fmt.Errorf("本地语音合成引擎没有输出 WAV 音频")
+	}
+	return audio, characters, nil
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,178 @@
+package tts
+
+import (
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"copycat/pkg/logger"
+)
+
+// 长文本合成参数
+const (
+	DefaultChunkConcurrency = 3     // 分段并发合成数（避免触发服务商限流）
+	MaxLongTextChars        = 20000 // 长文本合成的最大字数
+	chunkMaxAttempts        = 2     // 单段合成失败时的最多尝试次数
+)
+
+// ChunkTiming 单个分段在拼接后音频中的位置
+type ChunkTiming struct {
+	Index      int    `json:"index"`             // 序号，从 0 开始
+	Text       string `json:"text"`              // 分段文本
+	StartMs    int64  `json:"start_ms"`          // 开始时间（毫秒）
+	EndMs      int64  `json:"end_ms"`            // 结束时间（毫秒）
+	DurationMs int64  `json:"duration_ms"`       // 时长（毫秒）
+	Characters int    `json:"characters"`        // 计费字符数
+	Speaker    string `json:"speaker,omitempty"` // 说话人（多角色配音时）
+	Voice      string `json:"voice,omitempty"`   // 使用的音色（多角色配音时）
+
+	Sentences []SentenceTiming `json:"sentences,omitempty"` // 服务商返回的句级时间戳（可选）
+}
+
+// LongSynthesizeResult 长文本合成结果
+type LongSynthesizeResult struct {
+	Audio      []byte        `json:"-"`           // 拼接后的完整音频
+	Format     string        `json:"format"`      // 音频格式 (mp3/wav)
+	SampleRate int           `json:"sample_rate"` // 采样率
+	DurationMs int64         `json:"duration_ms"` // 总时长（毫秒）
+	Characters int           `json:"characters"`  // 计费字符总数
+	Chunks     []ChunkTiming `json:"chunks"`      // 各分段时间轴
+	Warnings   []string      `json:"warnings"`    // 被忽略或近似处理的参数
+}
+
+// SynthesizeLong 合成任意长度的文本：先按 <break> 停顿切开，再按句切分为不超过模型单次限制的分段，
+// 有界并发合成后在服务端拼接为同一格式的单个音频文件（停顿处插入静音），并返回每段的时间轴
+func (c *Client) SynthesizeLong(text, voice, model string, opts SynthesisOptions, concurrency int) (*LongSynthesizeResult, error) {
+	modelInfo, err := c.ResolveModel(model)
+	if err != nil {
+		return nil, err
+	}
+	model = modelInfo.ID
+	if err := c.ValidateVoice(voice, model); err != nil {
+		return nil, err
+	}
+	caps := c.provider.Capabilities()
+	if err := opts.Validate(caps); err != nil {
+		return nil, err
+	}
+	if n := utf8.RuneCountInString(StripMarkup(text)); n > MaxLongTextChars {
+		return nil, fmt.Errorf("文本长度 %d 超过上限 %d 字", n, MaxLongTextChars)
+	}
+
+	var jobs []chunkJob
+	for _, segment := range ParseMarkup(text) {
+		pieces := SplitText(segment.Text, modelInfo.MaxChars)
+		for i, piece := range pieces {
+			job := chunkJob{text: piece, voice: voice}
+			if i == len(pieces)-1 {
+				job.gapMs = segment.PauseMs
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("文本不能为空")
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultChunkConcurrency
+	}
+	logger.Info("TTS 长文本合成: provider=%s, model=%s, voice=%s, chunks=%d", c.provider.Name(), model, voice, len(jobs))
+
+	result, err := c.synthesizeJobs(jobs, model, opts, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = opts.Warnings(caps)
+	logger.Info("TTS 长文本合成完成: format=%s, duration=%dms, characters=%d", result.Format, result.DurationMs, result.Characters)
+	return result, nil
+}
+
+// chunkJob 单个待合成分段
+type chunkJob struct {
+	text    string
+	voice   string
+	speaker string
+	gapMs   int // 该段之后插入的静音时长
+}
+
+// synthesizeJobs 并发合成所有分段，按顺序拼接（插入静音）并计算时间轴
+func (c *Client) synthesizeJobs(jobs []chunkJob, model string, opts SynthesisOptions, concurrency int) (*LongSynthesizeResult, error) {
+	audios, characters, err := c.synthesizeChunks(jobs, model, opts, concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	gapsMs := make([]int, len(jobs))
+	for i, job := range jobs {
+		gapsMs[i] = job.gapMs
+	}
+	audio, infos, gaps, err := ConcatAudioWithGaps(audios, gapsMs)
+	if err != nil {
+		return nil, fmt.Errorf("拼接音频失败: %w", err)
+	}
+
+	result := &LongSynthesizeResult{
+		Audio:      audio,
+		Format:     infos[0].Format,
+		SampleRate: infos[0].SampleRate,
+		Chunks:     make([]ChunkTiming, len(jobs)),
+	}
+	var offset int64
+	for i, info := range infos {
+		result.Chunks[i] = ChunkTiming{
+			Index:      i,
+			Text:       StripMarkup(jobs[i].text),
+			StartMs:    offset,
+			EndMs:      offset + info.DurationMs,
+			DurationMs: info.DurationMs,
+			Characters: characters[i],
+			Speaker:    jobs[i].speaker,
+		}
+		if jobs[i].speaker != "" {
+			result.Chunks[i].Voice = jobs[i].voice
+		}
+		offset += info.DurationMs + gaps[i]
+		result.Characters += characters[i]
+	}
+	result.DurationMs = offset
+	return result, nil
+}
+
+// synthesizeChunks 用 worker 池并发合成各分段，任意一段最终失败则整体失败（避免音频缺段）
+func (c *Client) synthesizeChunks(chunks []chunkJob, model string, opts SynthesisOptions, concurrency int) ([][]byte, []int, error) {
+	audios := make([][]byte, len(chunks))
+	characters := make([]int, len(chunks))
+	errs := make([]error, len(chunks))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(chunks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// 每个 worker 只写自己负责的下标，无需加锁
+				for attempt := 1; attempt <= chunkMaxAttempts; attempt++ {
+					audios[i], characters[i], errs[i] = c.synthesizeAudio(chunks[i].text, chunks[i].voice, model, opts)
+					if errs[i] == nil {
+						break
+					}
+					logger.Warn("TTS 第 %d 段合成失败 (第 %d 次): %v", i+1, attempt, errs[i])
+				}
+			}
+		}()
+	}
+
+	for i := range chunks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, nil, fmt.Errorf("第 %d/%d 段合成失败: %w", i+1, len(chunks), err)
+		}
+	}
+	return audios, characters, nil
+}
@@ -0,0 +1,185 @@
+package tts
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SSML-lite 标记：
+//
+//	<break time="500ms"/>   停顿（也可写 1.5s；省略 time 时为 DefaultBreakMs）
+//	<emphasis>重点</emphasis> 重读
+//
+// 停顿在所有服务商上都通过插入静音实现；重读在支持 SSML 的引擎上原样传递，
+// 其余服务商用语气指令或前后短停顿近似
+const DefaultBreakMs = 500
+
+// 文本内部使用私有区字符标记重读范围，保证分段时不会切开标签
+const (
+	emphasisOpen  = '\uE000'
+	emphasisClose = '\uE001'
+)
+
+var (
+	markupBreakPattern    = regexp.MustCompile(`(?i)<break(?:\s+time\s*=\s*["']?(\d+(?:\.\d+)?)\s*(ms|s)?["']?)?\s*/?>`)
+	markupEmphasisPattern = regexp.MustCompile(`(?i)<emphasis(?:\s[^>]*)?>|</emphasis\s*>`)
+	markupTagPattern      = regexp.MustCompile(`(?i)</?(?:break|emphasis)(?:\s[^>]*)?/?>`)
+)
+
+// MarkupSegment 按停顿切开的一段文本
+type MarkupSegment struct {
+	Text    string // 文本（重读范围以内部标记表示）
+	PauseMs int    // 该段之后的停顿
+}
+
+// ParseMarkup 解析 SSML-lite 标记：按 <break> 切分为若干段，并把 <emphasis> 转换为内部标记。
+// 开头的停顿会被忽略，连续的停顿累加（不超过 MaxPauseMs）
+func ParseMarkup(text string) []MarkupSegment {
+	text = emphasisToMarkers(text)
+
+	var segments []MarkupSegment
+	addPause := func(ms int) {
+		if len(segments) == 0 {
+			return
+		}
+		last := &segments[len(segments)-1]
+		last.PauseMs += ms
+		if last.PauseMs > MaxPauseMs {
+			last.PauseMs = MaxPauseMs
+		}
+	}
+
+	pos := 0
+	for _, m := range markupBreakPattern.FindAllStringSubmatchIndex(text, -1) {
+		if part := strings.TrimSpace(text[pos:m[0]]); hasSpeakableText(part) {
+			segments = append(segments, MarkupSegment{Text: part})
+		}
+		ms := DefaultBreakMs
+		if m[2] >= 0 {
+			value, _ := strconv.ParseFloat(text[m[2]:m[3]], 64)
+			if m[4] >= 0 && strings.EqualFold(text[m[4]:m[5]], "s") {
+				value *= 1000
+			}
+			ms = int(value)
+		}
+		addPause(ms)
+		pos = m[1]
+	}
+	if part := strings.TrimSpace(text[pos:]); hasSpeakableText(part) {
+		segments = append(segments, MarkupSegment{Text: part})
+	}
+	return segments
+}
+
+// emphasisToMarkers 把 <emphasis> 标签替换为内部标记
+func emphasisToMarkers(text string) string {
+	return markupEmphasisPattern.ReplaceAllStringFunc(text, func(tag string) string {
+		if strings.HasPrefix(tag, "</") {
+			return string(emphasisClose)
+		}
+		return string(emphasisOpen)
+	})
+}
+
+// StripMarkup 去掉所有 SSML-lite 标签和内部标记，得到用于字幕和展示的纯文本
+func StripMarkup(text string) string {
+	text = markupTagPattern.ReplaceAllString(text, "")
+	return strings.Map(func(r rune) rune {
+		if r == emphasisOpen || r == emphasisClose {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// renderEmphasis 把内部重读标记转换为服务商可接受的文本，同时返回被重读的词语。
+// ssml 为 true 时输出转义后的 <emphasis> 标签；否则在重读词前后补逗号形成短停顿
+func renderEmphasis(text string, ssml bool) (string, []string) {
+	var b strings.Builder
+	var phrases []string
+	var phrase strings.Builder
+	inEmphasis := false
+	runes := []rune(text)
+
+	for i, r := range runes {
+		switch r {
+		case emphasisOpen:
+			if inEmphasis {
+				continue
+			}
+			inEmphasis = true
+			phrase.Reset()
+			if ssml {
+				b.WriteString("<emphasis>")
+			} else if i > 0 && !isPauseRune(runes[i-1]) {
+				b.WriteString("，")
+			}
+		case emphasisClose:
+			if !inEmphasis {
+				continue
+			}
+			inEmphasis = false
+			if p := strings.TrimSpace(phrase.String()); p != "" {
+				phrases = append(phrases, p)
+			}
+			if ssml {
+				b.WriteString("</emphasis>")
+			} else if i+1 < len(runes) && !isPauseRune(runes[i+1]) {
+				b.WriteString("，")
+			}
+		default:
+			if inEmphasis {
+				phrase.WriteRune(r)
+			}
+			if ssml {
+				b.WriteString(escapeXMLRune(r))
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+
+	// 分段切在重读范围内部时补全闭合标签
+	if inEmphasis {
+		if p := strings.TrimSpace(phrase.String()); p != "" {
+			phrases = append(phrases, p)
+		}
+		if ssml {
+			b.WriteString("</emphasis>")
+		}
+	}
+	return b.String(), phrases
+}
+
+// hasSpeakableText 是否包含可朗读的字符
+func hasSpeakableText(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPauseRune 是否为会产生停顿的标点或空白
+func isPauseRune(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSpace(r) || r == emphasisOpen || r == emphasisClose
+}
+
+func escapeXMLRune(r rune) string {
+	switch r {
+	case '&':
+		return "&amp;"
+	case '<':
+		return "&lt;"
+	case '>':
+		return "&gt;"
+	case '"':
+		return "&quot;"
+	case '\'':
+		return "&apos;"
+	}
+	return string(r)
+}
@@ -0,0 +1,192 @@
+package tts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"copycat/pkg/logger"
+)
+
+// OpenAIDefaultBaseURL OpenAI 官方接口地址
+const OpenAIDefaultBaseURL = "https://api.openai.com/v1"
+
+// OpenAIModels OpenAI 语音合成模型
+var OpenAIModels = []Model{
+	{ID: "gpt-4o-mini-tts", Name: "GPT-4o mini TTS", Description: "支持语气指令，音色最多", VoiceCount: 11, Languages: "多语种", MaxChars: 4096},
+	{ID: "tts-1", Name: "TTS-1", Description: "低延迟", VoiceCount: 9, Languages: "多语种", MaxChars: 4096},
+	{ID: "tts-1-hd", Name: "TTS-1 HD", Description: "高音质", VoiceCount: 9, Languages: "多语种", MaxChars: 4096},
+}
+
+// OpenAIVoices OpenAI 内置音色（均支持中文）
+var OpenAIVoices = []Voice{
+	{ID: "alloy", Name: "Alloy", Description: "中性、平衡", Gender: "女", Languages: "中/英等多语种"},
+	{ID: "ash", Name: "Ash", Description: "清晰、有力", Gender: "男", Languages: "中/英等多语种"},
+	{ID: "coral", Name: "Coral", Description: "温暖、亲切", Gender: "女", Languages: "中/英等多语种"},
+	{ID: "echo", Name: "Echo", Description: "沉稳男声", Gender: "男", Languages: "中/英等多语种"},
+	{ID: "fable", Name: "Fable", Description: "叙事感、英式口音", Gender: "男", Languages: "中/英等多语种"},
+	{ID: "onyx", Name: "Onyx", Description: "低沉浑厚", Gender: "男", Languages: "中/英等多语种"},
+	{ID: "nova", Name: "Nova", Description: "明亮、有活力", Gender: "女", Languages: "中/英等多语种"},
+	{ID: "sage", Name: "Sage", Description: "沉静、知性", Gender: "女", Languages: "中/英等多语种"},
+	{ID: "shimmer", Name: "Shimmer", Description: "柔和、轻快", Gender: "女", Languages: "中/英等多语种"},
+}
+
+// openAIExtraVoices 仅 gpt-4o-mini-tts 支持的音色
+var openAIExtraVoices = []Voice{
+	{ID: "ballad", Name: "Ballad", Description: "抒情、细腻", Gender: "男", Languages: "中/英等多语种"},
+	{ID: "verse", Name: "Verse", Description: "富有表现力", Gender: "男", Languages: "中/英等多语种"},
+}
+
+// OpenAIProvider OpenAI 及兼容 /audio/speech 接口的语音合成服务
+type OpenAIProvider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewOpenAIProvider 创建 OpenAI 兼容语音合成服务商，baseURL 为空时使用官方地址
+func NewOpenAIProvider(apiKey, baseURL string) *OpenAIProvider {
+	if baseURL == "" {
+		baseURL = OpenAIDefaultBaseURL
+	}
+	return &OpenAIProvider{
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+// openAISpeechRequest /audio/speech 请求结构
+type openAISpeechRequest struct {
+	Model          string  `json:"model"`
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice"`
+	ResponseFormat string  `json:"response_format"`
+	Speed          float64 `json:"speed,omitempty"`        // 语速 0.25-4.0
+	Instructions   string  `json:"instructions,omitempty"` // 语气指令（tts-1 系列不支持）
+}
+
+// Name 服务商标识
+func (p *OpenAIProvider) Name() string { return ProviderOpenAI }
+
+// Capabilities 服务商能力：自建兼容服务通常不需要 API Key，且模型和音色名称各不相同
+func (p *OpenAIProvider) Capabilities() Capabilities {
+	return Capabilities{
+		Formats:        []string{FormatMP3, FormatWAV},
+		Rate:           true,
+		Emotion:        true,
+		RequiresAPIKey: p.baseURL == OpenAIDefaultBaseURL,
+		CustomBaseURL:  true,
+		CustomModels:   true,
+		CustomVoices:   true,
+	}
+}
+
+// Models 可用模型
+func (p *OpenAIProvider) Models() []Model {
+	return OpenAIModels
+}
+
+// Voices 获取指定模型的可用音色
+func (p *OpenAIProvider) Voices(model string) []Voice {
+	voices := make([]Voice, 0, len(OpenAIVoices)+len(openAIExtraVoices))
+	voices = append(voices, OpenAIVoices...)
+	if model == "gpt-4o-mini-tts" {
+		voices = append(voices, openAIExtraVoices...)
+	}
+	for i := range voices {
+		voices[i].Model = model
+	}
+	return voices
+}
+
+// Synthesize 调用 /audio/speech 合成一段音频；接口不返回计费信息，按字数计。
+// 情感描述和重读词语通过 instructions 传递
+func (p *OpenAIProvider) Synthesize(req SynthesisRequest) ([]byte, int, error) {
+	model, voice, text := req.Model, req.Voice, req.Text
+	format := req.Options.Format
+	if format == "" {
+		format = FormatMP3
+	}
+	speechReq := openAISpeechRequest{
+		Model:          model,
+		Input:          text,
+		Voice:          voice,
+		ResponseFormat: format,
+		Speed:          req.Options.Rate,
+	}
+	if supportsInstructions(model) {
+		speechReq.Instructions = buildInstructions(req.Options.Emotion, req.Emphasis)
+	}
+	reqBody, err := json.Marshal(speechReq)
+	if err != nil {
+		return nil, 0, fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", p.baseURL+"/audio/speech", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, 0, fmt.Errorf("创建请求失败: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	characters := utf8.RuneCountInString(text)
+	logger.Info("TTS 请求: provider=openai, base_url=%s, model=%s, voice=%s, text_length=%d", p.baseURL, model, voice, characters)
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, 0, fmt.Errorf("请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+			} `json:"error"`
+		}
+		message := strings.TrimSpace(string(body))
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error.Message != "" {
+			message = errResp.Error.Message
+		}
+		logger.Error("TTS API 错误: status=%d, message=%s", resp.StatusCode, message)
+		return nil, 0, fmt.Errorf("TTS API 错误: HTTP %d - %s", resp.StatusCode, message)
+	}
+
+	if DetectFormat(body) == "" {
+		return nil, 0, fmt.Errorf("TTS 响应中没有可识别的音频数据")
+	}
+	return body, characters, nil
+}
+
+// supportsInstructions tts-1 系列不接受 instructions 参数
+func supportsInstructions(model string) bool {
+	return model != "tts-1" && model != "tts-1-hd"
+}
+
+// buildInstructions 由情感描述和重读词语生成语气指令
+func buildInstructions(emotion string, emphasis []string) string {
+	var parts []string
+	if emotion != "" {
+		parts = append(parts, "用"+emotion+"的语气朗读")
+	}
+	if len(emphasis) > 0 {
+		parts = append(parts, "重读以下词语："+strings.Join(emphasis, "、"))
+	}
+	return strings.Join(parts, "；")
+}
@@ -0,0 +1,106 @@
+package tts
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// 合成参数取值范围
+const (
+	MinRate         = 0.5 // 最慢语速倍率
+	MaxRate         = 2.0 // 最快语速倍率
+	MinPitch        = 0.5 // 最低音调倍率
+	MaxPitch        = 2.0 // 最高音调倍率
+	DefaultVolume   = 50  // 默认音量
+	MaxVolume       = 100 // 最大音量
+	MaxEmotionChars = 100 // 情感/语气描述最大字数
+)
+
+// SynthesisOptions 合成参数，零值表示使用服务商默认值
+type SynthesisOptions struct {
+	Rate       float64 `json:"rate,omitempty"`        // 语速倍率 0.5-2.0，如开头钩子 1.2、结尾引导 0.9
+	Pitch      float64 `json:"pitch,omitempty"`       // 音调倍率 0.5-2.0
+	Volume     int     `json:"volume,omitempty"`      // 音量 1-100，默认 50
+	Emotion    string  `json:"emotion,omitempty"`     // 情感/语气描述，如 "兴奋"、"温柔地娓娓道来"
+	Format     string  `json:"format,omitempty"`      // 输出格式 mp3/wav
+	SampleRate int     `json:"sample_rate,omitempty"` // 采样率
+}
+
+// SynthesisRequest 服务商单次合成请求
+type SynthesisRequest struct {
+	Text     string           // 待合成文本；服务商支持 SSML 时包含 <emphasis> 标签，否则为纯文本
+	Voice    string           // 音色
+	Model    string           // 模型
+	Emphasis []string         // 需要重读的词语（可用于生成语气指令）
+	Options  SynthesisOptions // 合成参数
+}
+
+// Validate 校验参数范围，以及输出格式和采样率是否被服务商支持（这两项影响产物，不做近似处理）
+func (o SynthesisOptions) Validate(caps Capabilities) error {
+	if o.Rate != 0 && (o.Rate < MinRate || o.Rate > MaxRate) {
+		return fmt.Errorf("语速需在 %.1f-%.1f 之间", MinRate, MaxRate)
+	}
+	if o.Pitch != 0 && (o.Pitch < MinPitch || o.Pitch > MaxPitch) {
+		return fmt.Errorf("音调需在 %.1f-%.1f 之间", MinPitch, MaxPitch)
+	}
+	if o.Volume < 0 || o.Volume > MaxVolume {
+		return fmt.Errorf("音量需在 1-%d 之间", MaxVolume)
+	}
+	if utf8.RuneCountInString(o.Emotion) > MaxEmotionChars {
+		return fmt.Errorf("情感描述不能超过 %d 字", MaxEmotionChars)
+	}
+	if o.Format != "" && !containsString(caps.Formats, o.Format) {
+		return fmt.Errorf("当前服务商不支持 %s 格式，可选: %v", o.Format, caps.Formats)
+	}
+	if o.SampleRate != 0 && !containsInt(caps.SampleRates, o.SampleRate) {
+		if len(caps.SampleRates) == 0 {
+			return fmt.Errorf("当前服务商不支持指定采样率")
+		}
+		return fmt.Errorf("当前服务商不支持 %d 采样率，可选: %v", o.SampleRate, caps.SampleRates)
+	}
+	return nil
+}
+
+// Warnings 列出服务商不支持、将被忽略或近似处理的参数
+func (o SynthesisOptions) Warnings(caps Capabilities) []string {
+	var warnings []string
+	if o.Rate != 0 && o.Rate != 1 && !caps.Rate {
+		warnings = append(warnings, "当前服务商不支持调节语速，已忽略")
+	}
+	if o.Pitch != 0 && o.Pitch != 1 && !caps.Pitch {
+		warnings = append(warnings, "当前服务商不支持调节音调，已忽略")
+	}
+	if o.Volume != 0 && o.Volume != DefaultVolume && !caps.Volume {
+		warnings = append(warnings, "当前服务商不支持调节音量，WAV 音频通过增益近似调节，MP3 音频已忽略")
+	}
+	if o.Emotion != "" && !caps.Emotion {
+		warnings = append(warnings, "当前服务商不支持情感控制，已忽略")
+	}
+	return warnings
+}
+
+// volumeGain 音量对应的增益倍数（DefaultVolume 为 1 倍）
+func (o SynthesisOptions) volumeGain() float64 {
+	if o.Volume == 0 {
+		return 1
+	}
+	return float64(o.Volume) / DefaultVolume
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package tts
+
+import (
+	"fmt"
+)
+
+// TTS 服务商标识
+const (
+	ProviderDashScope = "dashscope" // 阿里云百炼
+	ProviderOpenAI    = "openai"    // OpenAI 及兼容 /audio/speech 接口的服务
+	ProviderLocal     = "local"     // 本地离线引擎（piper / espeak-ng）
+)
+
+// ProviderTypes 所有支持的服务商（按展示顺序）
+var ProviderTypes = []string{ProviderDashScope, ProviderOpenAI, ProviderLocal}
+
+// providerLabels 服务商展示名称
+var providerLabels = map[string]string{
+	ProviderDashScope: "阿里云百炼",
+	ProviderOpenAI:    "OpenAI 兼容接口",
+	ProviderLocal:     "本地引擎（离线）",
+}
+
+// ProviderLabel 服务商展示名称
+func ProviderLabel(name string) string {
+	if label, ok := providerLabels[name]; ok {
+		return label
+	}
+	return name
+}
+
+// Capabilities 服务商能力说明
+type Capabilities struct {
+	Formats        []string `json:"formats"`          // 可输出的音频格式，第一个为默认格式
+	SampleRates    []int    `json:"sample_rates"`     // 可指定的采样率，为空表示不支持指定
+	Rate           bool     `json:"rate"`             // 是否支持调节语速
+	Pitch          bool     `json:"pitch"`            // 是否支持调节音调
+	Volume         bool     `json:"volume"`           // 是否支持调节音量
+	Emotion        bool     `json:"emotion"`          // 是否支持情感/语气描述
+	SSML           bool     `json:"ssml"`             // 是否原生支持 <emphasis> 等 SSML 标签
+	RequiresAPIKey bool     `json:"requires_api_key"` // 是否需要 API Key
+	CustomBaseURL  bool     `json:"custom_base_url"`  // 是否支持自定义接口地址
+	CustomModels   bool     `json:"custom_models"`    // 是否接受列表之外的模型ID（兼容接口）
+	CustomVoices   bool     `json:"custom_voices"`    // 是否接受列表之外的音色ID（兼容接口）
+	Offline        bool     `json:"offline"`          // 是否离线运行
+}
+
+// Provider TTS 服务商：提供模型/音色列表并完成单次合成（单次文本不超过模型的 MaxChars）
+type Provider interface {
+	// Name 服务商标识
+	Name() string
+	// Capabilities 服务商能力
+	Capabilities() Capabilities
+	// Models 可用模型，第一个为默认模型
+	Models() []Model
+	// Voices 指定模型的可用音色
+	Voices(model string) []Voice
+	// Synthesize 合成一段音频，返回原始音频数据和计费字符数；不支持的参数由服务商忽略
+	Synthesize(req SynthesisRequest) ([]byte, int, error)
+}
+
+// ProviderConfig 创建服务商所需的配置
+type ProviderConfig struct {
+	Type    string      // 服务商标识，为空时使用 dashscope
+	APIKey  string      // API Key
+	BaseURL string      // 自定义接口地址（仅 OpenAI 兼容接口）
+	Local   LocalConfig // 本地引擎配置（由服务端配置文件提供）
+}
+
+// NewProvider 按配置创建服务商
+func NewProvider(cfg ProviderConfig) (Provider, error) {
+	switch cfg.Type {
+	case "", ProviderDashScope:
+		return NewDashScopeProvider(cfg.APIKey), nil
+	case ProviderOpenAI:
+		return NewOpenAIProvider(cfg.APIKey, cfg.BaseURL), nil
+	case ProviderLocal:
+		return NewLocalProvider(cfg.Local), nil
+	}
+	return nil, fmt.Errorf("不支持的语音合成服务商: %s", cfg.Type)
+}
+
+// IsValidProvider 验证服务商标识是否有效
+func IsValidProvider(name string) bool {
+	_, ok := providerLabels[name]
+	return ok
+}
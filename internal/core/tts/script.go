@@ -0,0 +1,333 @@
+package tts
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"copycat/pkg/logger"
+)
+
+// 多角色配音默认参数
+const (
+	NarratorSpeaker       = "旁白" // 没有标注说话人的口播归为旁白
+	DefaultLinePauseMs    = 300  // 同一场景内台词之间的停顿
+	DefaultSegmentPauseMs = 800  // 时间线片段（场景）之间的停顿
+	MaxPauseMs            = 5000 // 停顿上限
+	maxSpeakerNameChars   = 8
+)
+
+// ScriptLine 解析出的单句台词
+type ScriptLine struct {
+	Index        int    `json:"index"`         // 序号，从 0 开始
+	Speaker      string `json:"speaker"`       // 说话人
+	Text         string `json:"text"`          // 台词（已去掉舞台说明）
+	Segment      string `json:"segment"`       // 所属时间线片段，如 "0s-3s"
+	SegmentStart bool   `json:"segment_start"` // 是否为片段的第一句
+}
+
+// ScriptOptions 多角色合成参数
+type ScriptOptions struct {
+	LinePauseMs    int              // 台词之间的停顿
+	SegmentPauseMs int              // 片段之间的停顿
+	Concurrency    int              // 并发合成数
+	Synthesis      SynthesisOptions // 语速/音调/音量等合成参数（所有角色共用）
+}
+
+// 非台词字段：出现在 "标签：内容" 中时整行忽略
+var scriptIgnoredLabels = map[string]bool{
+	"画面": true, "动作": true, "BGM": true, "bgm": true, "音乐": true, "音效": true, "字幕": true, "花字": true,
+	"镜头": true, "景别": true, "运镜": true, "设备": true, "光线": true, "道具": true, "场景": true, "转场": true,
+	"特效": true, "后期": true, "服装": true, "时长": true, "备注": true, "封面": true, "话题": true, "标签": true,
+	"标题": true, "视频类型": true, "视频脚本": true, "拍摄建议": true, "拍摄要点": true, "剪辑建议": true, "提示": true,
+}
+
+// 口播类字段：内容为台词，可能再带 "角色：" 前缀
+var scriptNarrationLabels = map[string]bool{
+	"口播": true, "台词": true, "旁白": true, "配音": true, "解说": true, "文案": true, "对白": true,
+}
+
+var (
+	// [0s-3s]、【3s-8s】、0-3秒 等时间线标记
+	scriptTimelinePattern = regexp.MustCompile(`^\s*[\[【(（]?\s*(\d+(?:\.\d+)?)\s*(?:s|秒)?\s*[-~～—]+\s*(\d+(?:\.\d+)?)\s*(?:s|秒)\s*[\]】)）]?`)
+	// "- 标签：内容"、"小美: 内容"
+	scriptLabelPattern = regexp.MustCompile(`^\s*(?:[-*•·]\s*)?\**([^\s：:*]{1,10})\**\s*[：:]\s*(.*)$`)
+	// "【小美】内容"
+	scriptBracketPattern = regexp.MustCompile(`^\s*【([^】]{1,10})】\s*(.*)$`)
+	// 舞台说明：（笑）、(停顿)、[音效]
+	scriptDirectionPattern = regexp.MustCompile(`[（(][^）)]*[）)]|\[[^\]]*\]`)
+)
+
+// ParseScript 将视频脚本解析为按顺序排列的说话人台词
+// 识别 "口播：xxx"、"口播：角色：xxx"、"角色：xxx"、"【角色】xxx" 几种写法；
+// 画面/BGM/拍摄建议等非台词字段会被忽略。若没有识别到任何台词，整段文本按句作为旁白
+func ParseScript(script string) []ScriptLine {
+	var lines []ScriptLine
+	segment := ""
+	segmentStart := true
+
+	add := func(speaker, text string) {
+		text = cleanScriptText(text)
+		if text == "" || text == "无" || strings.HasPrefix(text, "无口播") {
+			return
+		}
+		lines = append(lines, ScriptLine{
+			Index:        len(lines),
+			Speaker:      speaker,
+			Text:         text,
+			Segment:      segment,
+			SegmentStart: segmentStart,
+		})
+		segmentStart = false
+	}
+
+	for _, raw := range strings.Split(script, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		if m := scriptTimelinePattern.FindStringSubmatch(line); m != nil {
+			segment = m[1] + "s-" + m[2] + "s"
+			segmentStart = true
+			continue
+		}
+
+		label, content, ok := splitScriptLabel(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case scriptIgnoredLabels[label]:
+			continue
+		case scriptNarrationLabels[label]:
+			speaker := NarratorSpeaker
+			if label == "对白" || label == "台词" {
+				speaker = ""
+			}
+			// 口播内容本身可能是 "角色：台词"
+			if inner, text, ok := splitScriptLabel(content); ok && !scriptIgnoredLabels[inner] && !scriptNarrationLabels[inner] {
+				speaker, content = inner, text
+			}
+			if speaker == "" {
+				speaker = NarratorSpeaker
+			}
+			add(speaker, content)
+		default:
+			add(label, content)
+		}
+	}
+
+	if len(lines) == 0 {
+		for _, sentence := range SplitSentences(script) {
+			add(NarratorSpeaker, sentence)
+		}
+	}
+	return lines
+}
+
+// splitScriptLabel 拆分 "标签：内容" 或 "【标签】内容"
+func splitScriptLabel(line string) (string, string, bool) {
+	if m := scriptBracketPattern.FindStringSubmatch(line); m != nil {
+		return strings.TrimSpace(m[1]), m[2], true
+	}
+	if m := scriptLabelPattern.FindStringSubmatch(line); m != nil {
+		label := strings.TrimSpace(m[1])
+		if utf8.RuneCountInString(label) <= maxSpeakerNameChars || scriptIgnoredLabels[label] {
+			return label, m[2], true
+		}
+	}
+	return "", "", false
+}
+
+// cleanScriptText 去掉舞台说明、引号和多余空白
+func cleanScriptText(text string) string {
+	text = scriptDirectionPattern.ReplaceAllString(text, "")
+	text = strings.NewReplacer("“", "", "”", "", "「", "", "」", "", "\"", "", "**", "").Replace(text)
+	return strings.TrimSpace(text)
+}
+
+// ScriptSpeakers 按首次出现顺序返回所有说话人
+func ScriptSpeakers(lines []ScriptLine) []string {
+	seen := make(map[string]bool)
+	var speakers []string
+	for _, l := range lines {
+		if !seen[l.Speaker] {
+			seen[l.Speaker] = true
+			speakers = append(speakers, l.Speaker)
+		}
+	}
+	return speakers
+}
+
+// 根据称呼推断性别的关键字
+var (
+	maleSpeakerHints   = []string{"男", "先生", "爸", "哥", "叔", "爷", "弟", "老公", "儿子", "老板", "小伙"}
+	femaleSpeakerHints = []string{"女", "小姐", "妈", "姐", "妹", "婆", "奶", "姨", "老婆", "女儿", "闺蜜"}
+)
+
+// AssignVoices 为每个说话人分配音色：优先使用 overrides；旁白使用 defaultVoice；
+// 其余角色按称呼推断性别，依次分配该模型下尚未使用的普通话音色（不使用方言音色）
+func (c *Client) AssignVoices(speakers []string, overrides map[string]string, defaultVoice, model string) (map[string]string, error) {
+	modelInfo, err := c.ResolveModel(model)
+	if err != nil {
+		return nil, err
+	}
+	model = modelInfo.ID
+	if err := c.ValidateVoice(defaultVoice, model); err != nil {
+		return nil, err
+	}
+
+	assigned := make(map[string]string, len(speakers))
+	used := map[string]bool{defaultVoice: true}
+	for speaker, voice := range overrides {
+		if err := c.ValidateVoice(voice, model); err != nil {
+			return nil, fmt.Errorf("角色 %s 的音色无效: %w", speaker, err)
+		}
+		assigned[speaker] = voice
+		used[voice] = true
+	}
+
+	var male, female []Voice
+	for _, v := range c.Voices(model) {
+		if !strings.HasPrefix(v.Languages, "中/") {
+			continue
+		}
+		if v.Gender == "男" {
+			male = append(male, v)
+		} else {
+			female = append(female, v)
+		}
+	}
+
+	pick := func(pool []Voice) string {
+		for _, v := range pool {
+			if !used[v.ID] {
+				used[v.ID] = true
+				return v.ID
+			}
+		}
+		return ""
+	}
+
+	// 未能推断性别的角色男女交替分配，保证对话双方音色有区分
+	nextMale := false
+	for _, speaker := range speakers {
+		if _, ok := assigned[speaker]; ok {
+			continue
+		}
+		if speaker == NarratorSpeaker {
+			assigned[speaker] = defaultVoice
+			continue
+		}
+
+		var voice string
+		switch guessSpeakerGender(speaker) {
+		case "男":
+			voice = pick(male)
+		case "女":
+			voice = pick(female)
+		default:
+			if nextMale {
+				voice = pick(male)
+			} else {
+				voice = pick(female)
+			}
+			nextMale = !nextMale
+		}
+		if voice == "" {
+			// 音色用尽时复用默认音色
+			voice = defaultVoice
+		}
+		assigned[speaker] = voice
+	}
+	return assigned, nil
+}
+
+func guessSpeakerGender(speaker string) string {
+	for _, hint := range femaleSpeakerHints {
+		if strings.Contains(speaker, hint) {
+			return "女"
+		}
+	}
+	for _, hint := range maleSpeakerHints {
+		if strings.Contains(speaker, hint) {
+			return "男"
+		}
+	}
+	return ""
+}
+
+// SynthesizeScript 逐句合成多角色台词，并按顺序拼接为一条音轨（台词/片段之间插入停顿）
+func (c *Client) SynthesizeScript(lines []ScriptLine, voices map[string]string, model string, opts ScriptOptions) (*LongSynthesizeResult, error) {
+	modelInfo, err := c.ResolveModel(model)
+	if err != nil {
+		return nil, err
+	}
+	model = modelInfo.ID
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("脚本中没有可合成的台词")
+	}
+	caps := c.provider.Capabilities()
+	if err := opts.Synthesis.Validate(caps); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	var jobs []chunkJob
+	for i, line := range lines {
+		voice, ok := voices[line.Speaker]
+		if !ok {
+			return nil, fmt.Errorf("角色 %s 未分配音色", line.Speaker)
+		}
+		total += utf8.RuneCountInString(StripMarkup(line.Text))
+
+		// 台词内的 <break> 插入对应停顿；单句超过模型限制时拆成多段，同一句内部不插入停顿
+		var lineJobs []chunkJob
+		for _, segment := range ParseMarkup(line.Text) {
+			pieces := SplitText(segment.Text, modelInfo.MaxChars)
+			for j, piece := range pieces {
+				job := chunkJob{text: piece, voice: voice, speaker: line.Speaker}
+				if j == len(pieces)-1 {
+					job.gapMs = segment.PauseMs
+				}
+				lineJobs = append(lineJobs, job)
+			}
+		}
+		if len(lineJobs) == 0 {
+			continue
+		}
+		if i < len(lines)-1 {
+			last := &lineJobs[len(lineJobs)-1]
+			pause := opts.LinePauseMs
+			if lines[i+1].SegmentStart {
+				pause = opts.SegmentPauseMs
+			}
+			if pause > last.gapMs {
+				last.gapMs = pause
+			}
+		}
+		jobs = append(jobs, lineJobs...)
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("脚本中没有可合成的台词")
+	}
+	if total > MaxLongTextChars {
+		return nil, fmt.Errorf("台词总长度 %d 超过上限 %d 字", total, MaxLongTextChars)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultChunkConcurrency
+	}
+	logger.Info("TTS 多角色合成: provider=%s, model=%s, lines=%d, chunks=%d, speakers=%d", c.provider.Name(), model, len(lines), len(jobs), len(voices))
+
+	result, err := c.synthesizeJobs(jobs, model, opts.Synthesis, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = opts.Synthesis.Warnings(caps)
+	logger.Info("TTS 多角色合成完成: format=%s, duration=%dms, characters=%d", result.Format, result.DurationMs, result.Characters)
+	return result, nil
+}
@@ -0,0 +1,200 @@
+package tts
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// 字幕格式
+const (
+	SubtitleSRT = "srt"
+	SubtitleVTT = "vtt"
+)
+
+// DefaultCueMaxChars 单条字幕默认最大字数（竖屏短视频一行约 16 个汉字）
+const DefaultCueMaxChars = 16
+
+// SentenceTiming 服务商返回的句级时间戳（相对于所在分段的开始时间）
+type SentenceTiming struct {
+	Text    string `json:"text"`
+	StartMs int64  `json:"start_ms"`
+	EndMs   int64  `json:"end_ms"`
+}
+
+// Cue 单条字幕
+type Cue struct {
+	Index   int    `json:"index"` // 序号，从 1 开始
+	StartMs int64  `json:"start_ms"`
+	EndMs   int64  `json:"end_ms"`
+	Text    string `json:"text"`
+}
+
+// BuildCues 根据分段时间轴生成字幕：
+// 分段带有服务商句级时间戳时直接使用；否则把分段切成短句，按发音字数比例分配该分段的实测时长
+func BuildCues(chunks []ChunkTiming, maxChars int) []Cue {
+	if maxChars <= 0 {
+		maxChars = DefaultCueMaxChars
+	}
+
+	var cues []Cue
+	for _, chunk := range chunks {
+		if len(chunk.Sentences) > 0 {
+			for _, s := range chunk.Sentences {
+				cues = appendCue(cues, s.Text, chunk.StartMs+s.StartMs, chunk.StartMs+s.EndMs)
+			}
+			continue
+		}
+
+		var lines []string
+		for _, sentence := range SplitSentences(chunk.Text) {
+			lines = append(lines, splitCaption([]rune(sentence), maxChars)...)
+		}
+
+		total := 0
+		weights := make([]int, len(lines))
+		for i, line := range lines {
+			weights[i] = speechWeight(line)
+			total += weights[i]
+		}
+		if total == 0 {
+			continue
+		}
+
+		// 按累计权重计算边界，避免逐条取整造成的误差累积
+		acc := 0
+		for i, line := range lines {
+			start := chunk.StartMs + chunk.DurationMs*int64(acc)/int64(total)
+			acc += weights[i]
+			end := chunk.StartMs + chunk.DurationMs*int64(acc)/int64(total)
+			cues = appendCue(cues, line, start, end)
+		}
+	}
+	return cues
+}
+
+// splitCaption 将句子切成字幕行：先在逗号等停顿处断开并合并相邻短句，
+// 仍超长的部分再平均切分（避免出现一行很长、下一行只有一两个字）
+func splitCaption(sentence []rune, maxChars int) []string {
+	var clauses [][]rune
+	start := 0
+	for i, r := range sentence {
+		if clauseBreakers[r] {
+			clauses = append(clauses, sentence[start:i+1])
+			start = i + 1
+		}
+	}
+	if start < len(sentence) {
+		clauses = append(clauses, sentence[start:])
+	}
+
+	var lines []string
+	var current []rune
+	flush := func() {
+		for _, part := range splitEvenly(current, maxChars) {
+			lines = append(lines, string(part))
+		}
+		current = nil
+	}
+	for _, clause := range clauses {
+		if len(current) > 0 && len(current)+len(clause) > maxChars {
+			flush()
+		}
+		current = append(current, clause...)
+	}
+	if len(current) > 0 {
+		flush()
+	}
+	return lines
+}
+
+// splitEvenly 把超过 maxChars 的文本平均切成若干段
+func splitEvenly(text []rune, maxChars int) [][]rune {
+	if len(text) <= maxChars {
+		return [][]rune{text}
+	}
+	parts := (len(text) + maxChars - 1) / maxChars
+	size := (len(text) + parts - 1) / parts
+	var result [][]rune
+	for len(text) > size {
+		result = append(result, text[:size])
+		text = text[size:]
+	}
+	return append(result, text)
+}
+
+func appendCue(cues []Cue, text string, start, end int64) []Cue {
+	text = trimCueText(text)
+	if text == "" || end <= start {
+		return cues
+	}
+	return append(cues, Cue{Index: len(cues) + 1, StartMs: start, EndMs: end, Text: text})
+}
+
+// speechWeight 估算朗读时长权重：每个发音字符记 2，停顿标点记 1
+func speechWeight(text string) int {
+	weight := 0
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			weight += 2
+		case unicode.IsPunct(r):
+			weight++
+		}
+	}
+	return weight
+}
+
+// trimCueText 去掉字幕首尾空白和句末标点（字幕习惯不显示句号、逗号）
+func trimCueText(text string) string {
+	text = strings.TrimSpace(text)
+	return strings.TrimRightFunc(text, func(r rune) bool {
+		return r == '。' || r == '，' || r == '、' || r == '；' || r == ',' || r == ';' || r == '.' || unicode.IsSpace(r)
+	})
+}
+
+// FormatSubtitles 按指定格式输出字幕文件内容
+func FormatSubtitles(cues []Cue, format string) (string, error) {
+	switch format {
+	case SubtitleSRT:
+		return FormatSRT(cues), nil
+	case SubtitleVTT:
+		return FormatVTT(cues), nil
+	}
+	return "", fmt.Errorf("不支持的字幕格式: %s", format)
+}
+
+// FormatSRT 输出 SubRip (.srt) 字幕
+func FormatSRT(cues []Cue) string {
+	var b strings.Builder
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", cue.Index, formatCueTime(cue.StartMs, ','), formatCueTime(cue.EndMs, ','), cue.Text)
+	}
+	return b.String()
+}
+
+// FormatVTT 输出 WebVTT (.vtt) 字幕
+func FormatVTT(cues []Cue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", cue.Index, formatCueTime(cue.StartMs, '.'), formatCueTime(cue.EndMs, '.'), cue.Text)
+	}
+	return b.String()
+}
+
+// SubtitleContentType 字幕格式对应的 MIME 类型
+func SubtitleContentType(format string) string {
+	if format == SubtitleVTT {
+		return "text/vtt; charset=utf-8"
+	}
+	return "application/x-subrip; charset=utf-8"
+}
+
+// formatCueTime 毫秒转 hh:mm:ss,mmm（SRT）或 hh:mm:ss.mmm（VTT）
+func formatCueTime(ms int64, sep byte) string {
+	h := ms / 3600000
+	m := ms / 60000 % 60
+	s := ms / 1000 % 60
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", h, m, s, sep, ms%1000)
+}
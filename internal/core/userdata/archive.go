@@ -0,0 +1,235 @@
+package userdata
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"copycat/internal/core/audit"
+	"copycat/internal/model"
+	"copycat/pkg/logger"
+	"copycat/pkg/storage"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// analysis 单个项目的分析结果
+type analysis struct {
+	ProjectID   string         `json:"project_id"`
+	SourceURL   string         `json:"source_url"`
+	ContentType string         `json:"content_type"`
+	Result      datatypes.JSON `json:"analysis_result"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// buildArchive 打包用户数据并上传到对象存储，返回 zip 大小。目录结构：
+//
+//	profile.json            个人资料
+//	settings.json           个人设置（API Key 等已脱敏）
+//	projects.json/.csv      项目
+//	analyses.json           分析结果
+//	generations.json/.csv   仿写生成记录
+//	batch_tasks.json        批量任务
+//	assets.json             资源文件清单
+//	audio/、image/           合成音频和生成图片
+func (s *Service) buildArchive(ctx context.Context, userID int64, key string) (int64, error) {
+	tmp, err := os.CreateTemp("", "copycat-export-*.zip")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := zip.NewWriter(tmp)
+	if err := s.writeArchive(ctx, zw, userID); err != nil {
+		return 0, err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finish zip: %w", err)
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get zip size: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to rewind zip: %w", err)
+	}
+	if err := s.store.Put(ctx, key, tmp, size, "application/zip"); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (s *Service) writeArchive(ctx context.Context, zw *zip.Writer, userID int64) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "profile.json", user); err != nil {
+		return err
+	}
+
+	settings, err := s.settings.GetByUserID(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to get user settings: %w", err)
+	}
+	if err := writeJSON(zw, "settings.json", redact(settings)); err != nil {
+		return err
+	}
+
+	projects, err := s.data.ListProjects(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "projects.json", projects); err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(projects))
+	analyses := make([]analysis, 0, len(projects))
+	for _, p := range projects {
+		rows = append(rows, []string{p.ID.String(), strconv.FormatInt(p.WorkspaceID, 10), p.SourceURL, p.ContentType,
+			p.Status, p.NewTopic, p.GeneratedContent, formatTime(p.CreatedAt), formatTime(p.UpdatedAt)})
+		if len(p.AnalysisResult) > 0 {
+			analyses = append(analyses, analysis{p.ID.String(), p.SourceURL, p.ContentType, p.AnalysisResult, p.CreatedAt})
+		}
+	}
+	if err := writeCSV(zw, "projects.csv", []string{"id", "workspace_id", "source_url", "content_type", "status",
+		"new_topic", "generated_content", "created_at", "updated_at"}, rows); err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "analyses.json", analyses); err != nil {
+		return err
+	}
+
+	generations, err := s.data.ListGenerations(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "generations.json", generations); err != nil {
+		return err
+	}
+	rows = make([][]string, 0, len(generations))
+	for _, g := range generations {
+		parentID := ""
+		if g.ParentID != nil {
+			parentID = g.ParentID.String()
+		}
+		rows = append(rows, []string{g.ID.String(), g.ProjectID.String(), parentID, strconv.Itoa(g.VariantIndex),
+			g.NewTopic, g.Instruction, g.Content, formatTime(g.CreatedAt)})
+	}
+	if err := writeCSV(zw, "generations.csv", []string{"id", "project_id", "parent_id", "variant_index",
+		"new_topic", "instruction", "content", "created_at"}, rows); err != nil {
+		return err
+	}
+
+	tasks, err := s.data.ListBatchTasks(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "batch_tasks.json", tasks); err != nil {
+		return err
+	}
+
+	assets, err := s.data.ListAssets(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "assets.json", assets); err != nil {
+		return err
+	}
+	for _, a := range assets {
+		name := fmt.Sprintf("%s/%s.%s", a.Kind, a.ID, a.Format)
+		if err := s.copyObject(ctx, zw, name, a.StorageKey); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				logger.Warn("[Export] asset %s of user %d missing in storage, skipped", a.ID, userID)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// copyObject 把对象存储中的文件写入 zip
+func (s *Service) copyObject(ctx context.Context, zw *zip.Writer, name, key string) error {
+	obj, err := s.store.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: obj.ModTime()}) // 音频和图片已压缩
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := io.Copy(w, obj); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func writeCSV(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	// 写入 UTF-8 BOM，Excel 打开中文不乱码
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// redact 设置按 JSON 字段展开，API Key 等敏感字段只保留是否已配置
+func redact(settings *model.UserSettings) map[string]interface{} {
+	if settings == nil {
+		return nil
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	for k, v := range m {
+		if audit.IsSensitive(k) && v != nil && v != "" {
+			m[k] = audit.Redacted
+		}
+	}
+	return m
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
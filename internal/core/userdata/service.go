@@ -0,0 +1,300 @@
+// Package userdata 账号数据导出（打包为 zip 异步生成）和注销账号（级联删除用户的全部数据和文件）
+package userdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"copycat/internal/model"
+	"copycat/internal/repository"
+	"copycat/pkg/logger"
+	"copycat/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+const (
+	exportTTL     = 7 * 24 * time.Hour // 导出文件可下载的时长
+	exportTimeout = 30 * time.Minute   // 超过该时间仍未完成的任务视为中断（如服务重启），允许重新导出
+)
+
+var (
+	// ErrExportInProgress 已有进行中的导出任务
+	ErrExportInProgress = errors.New("export already in progress")
+	// ErrExportNotFound 没有导出任务
+	ErrExportNotFound = errors.New("export not found")
+	// ErrExportNotReady 导出任务尚未完成或已失败
+	ErrExportNotReady = errors.New("export not ready")
+	// ErrExportExpired 导出文件已过期
+	ErrExportExpired = errors.New("export expired")
+)
+
+// SoleOwnerError 用户是某个仍有其他成员的工作区的唯一所有者，需先转让或删除工作区
+type SoleOwnerError struct {
+	Workspace string
+}
+
+func (e *SoleOwnerError) Error() string {
+	return "sole owner of shared workspace: " + e.Workspace
+}
+
+// Service 账号数据服务
+type Service struct {
+	data       repository.AccountDataRepository
+	exports    repository.ExportJobRepository
+	users      repository.UserRepository
+	settings   *repository.UserSettingsRepository
+	workspaces repository.WorkspaceRepository
+	store      storage.Storage
+
+	mu      sync.Mutex
+	running map[int64]*exportRun // 进行中的导出，按用户ID索引
+}
+
+// exportRun 一个进行中的后台导出
+type exportRun struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewService 创建账号数据服务
+func NewService(data repository.AccountDataRepository, exports repository.ExportJobRepository, users repository.UserRepository,
+	settings *repository.UserSettingsRepository, workspaces repository.WorkspaceRepository, store storage.Storage) *Service {
+	return &Service{
+		data:       data,
+		exports:    exports,
+		users:      users,
+		settings:   settings,
+		workspaces: workspaces,
+		store:      store,
+		running:    make(map[int64]*exportRun),
+	}
+}
+
+// StartExport 创建导出任务并在后台打包，同一用户只保留最近一次导出
+func (s *Service) StartExport(ctx context.Context, userID int64) (*model.ExportJob, error) {
+	previous, err := s.LatestExport(ctx, userID)
+	if err != nil && !errors.Is(err, ErrExportNotFound) {
+		return nil, err
+	}
+	if previous != nil {
+		if previous.Active() && time.Since(previous.CreatedAt) < exportTimeout {
+			return nil, ErrExportInProgress
+		}
+		s.removeExport(ctx, previous)
+	}
+
+	job := &model.ExportJob{UserID: userID, Status: model.ExportJobStatusPending}
+	if err := s.exports.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	run := &exportRun{cancel: cancel, done: make(chan struct{})}
+	s.mu.Lock()
+	s.running[userID] = run
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			cancel()
+			s.mu.Lock()
+			if s.running[userID] == run {
+				delete(s.running, userID)
+			}
+			s.mu.Unlock()
+			close(run.done)
+		}()
+		s.runExport(ctx, job)
+	}()
+	return job, nil
+}
+
+// LatestExport 获取用户最近一次导出任务
+func (s *Service) LatestExport(ctx context.Context, userID int64) (*model.ExportJob, error) {
+	job, err := s.exports.GetLatestByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// OpenExport 打开用户最近一次导出的 zip，调用方负责关闭
+func (s *Service) OpenExport(ctx context.Context, userID int64) (*model.ExportJob, storage.Object, error) {
+	job, err := s.LatestExport(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != model.ExportJobStatusCompleted {
+		return nil, nil, ErrExportNotReady
+	}
+	if job.ExpiresAt != nil && time.Now().After(*job.ExpiresAt) {
+		return nil, nil, ErrExportExpired
+	}
+	obj, err := s.store.Open(ctx, job.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrExportExpired
+		}
+		return nil, nil, err
+	}
+	return job, obj, nil
+}
+
+// DeleteAccount 注销账号：删除用户的项目、生成记录、资源、批量任务、设置、令牌和个人工作区等数据，
+// 用户是唯一成员的工作区一并删除，用户在其他团队工作区中创建的内容转交给该工作区的其他所有者；
+// 数据库删除成功后再清理对象存储中的文件
+func (s *Service) DeleteAccount(ctx context.Context, userID int64) error {
+	// 先停止进行中的导出，避免注销后才上传的 zip 遗留在对象存储中
+	if err := s.stopExport(ctx, userID); err != nil {
+		return err
+	}
+
+	workspaceIDs, err := s.ownedWorkspaces(ctx, userID)
+	if err != nil {
+		return err
+	}
+	keys, err := s.data.ListStorageKeys(ctx, userID, workspaceIDs)
+	if err != nil {
+		return err
+	}
+	if err := s.data.DeleteUser(ctx, userID, workspaceIDs); err != nil {
+		return err
+	}
+
+	// 文件清理失败不回滚，只记录日志
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			logger.Warn("[Account] failed to delete object %s of user %d: %v", key, userID, err)
+		}
+	}
+	logger.Info("[Account] user %d deleted with %d workspaces and %d files", userID, len(workspaceIDs), len(keys))
+	return nil
+}
+
+// ownedWorkspaces 注销时需要删除的工作区：个人工作区和只有该用户一名成员的工作区；
+// 用户是其他成员仍在使用的工作区的唯一所有者时返回 SoleOwnerError
+func (s *Service) ownedWorkspaces(ctx context.Context, userID int64) ([]int64, error) {
+	workspaces, err := s.workspaces.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(workspaces))
+	for _, ws := range workspaces {
+		if ws.Personal && ws.OwnerID == userID {
+			ids = append(ids, ws.ID)
+			continue
+		}
+		members, err := s.workspaces.ListMembers(ctx, ws.ID)
+		if err != nil {
+			return nil, err
+		}
+		var owner bool
+		var others, otherOwners int
+		for _, m := range members {
+			switch {
+			case m.UserID == userID:
+				owner = m.Role == model.WorkspaceRoleOwner
+			case m.Role == model.WorkspaceRoleOwner:
+				others++
+				otherOwners++
+			default:
+				others++
+			}
+		}
+		if others == 0 {
+			ids = append(ids, ws.ID)
+			continue
+		}
+		if owner && otherOwners == 0 {
+			return nil, &SoleOwnerError{Workspace: ws.Name}
+		}
+	}
+	return ids, nil
+}
+
+// stopExport 取消用户进行中的导出并等待其退出
+func (s *Service) stopExport(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	run := s.running[userID]
+	s.mu.Unlock()
+	if run == nil {
+		return nil
+	}
+
+	run.cancel()
+	select {
+	case <-run.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runExport 后台打包导出文件并更新任务状态
+func (s *Service) runExport(ctx context.Context, job *model.ExportJob) {
+	job.Status = model.ExportJobStatusProcessing
+	if err := s.exports.Update(ctx, job); err != nil {
+		logger.Error("[Export] failed to update job %s: %v", job.ID, err)
+		return
+	}
+
+	key := fmt.Sprintf("exports/%d/%s.zip", job.UserID, job.ID)
+	size, err := s.buildArchive(ctx, job.UserID, key)
+	if err == nil {
+		// 打包期间导出被取消或账号已注销（可能由其他实例执行）时删除刚上传的文件
+		if _, userErr := s.users.GetByID(ctx, job.UserID); userErr != nil {
+			err = fmt.Errorf("user no longer available: %w", userErr)
+			s.deleteObject(key)
+		}
+	}
+	if err != nil {
+		logger.Error("[Export] job %s of user %d failed: %v", job.ID, job.UserID, err)
+		job.Status = model.ExportJobStatusFailed
+		job.Error = truncate(err.Error(), 500)
+	} else {
+		expiresAt := time.Now().Add(exportTTL)
+		job.Status = model.ExportJobStatusCompleted
+		job.StorageKey = key
+		job.Size = size
+		job.ExpiresAt = &expiresAt
+		logger.Info("[Export] job %s of user %d completed (%d bytes)", job.ID, job.UserID, size)
+	}
+	if err := s.exports.Update(ctx, job); err != nil {
+		logger.Error("[Export] failed to update job %s: %v", job.ID, err)
+	}
+}
+
+// deleteObject 删除对象存储中的文件，不受已取消的导出上下文影响
+func (s *Service) deleteObject(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := s.store.Delete(ctx, key); err != nil {
+		logger.Warn("[Export] failed to delete archive %s: %v", key, err)
+	}
+}
+
+// removeExport 删除旧的导出任务和文件
+func (s *Service) removeExport(ctx context.Context, job *model.ExportJob) {
+	if job.StorageKey != "" {
+		if err := s.store.Delete(ctx, job.StorageKey); err != nil {
+			logger.Warn("[Export] failed to delete archive %s: %v", job.StorageKey, err)
+		}
+	}
+	if err := s.exports.Delete(ctx, job.ID); err != nil {
+		logger.Warn("[Export] failed to delete job %s: %v", job.ID, err)
+	}
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,258 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"copycat/internal/core/media"
+	"copycat/pkg/logger"
+)
+
+// 默认抽帧参数
+const (
+	defaultBinary         = "ffmpeg"
+	defaultMaxFrames      = 8   // 单个视频最多送给视觉模型的帧数
+	defaultInterval       = 3   // 固定间隔抽帧的最小间隔（秒）
+	defaultSceneThreshold = 0.3 // 场景切换阈值（0-1，越小越敏感）
+	defaultMaxWidth       = 768 // 帧宽度上限（像素）
+	defaultTimeout        = 120 // 下载和抽帧的总超时（秒）
+	defaultMaxSizeMB      = 200 // 视频文件大小上限（MB）
+	minSceneFrames        = 3   // 场景切换帧少于该数量时改用固定间隔抽帧
+	sceneCandidateFactor  = 4   // 场景切换候选帧为 MaxFrames 的倍数，之后均匀筛选
+	userAgent             = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+)
+
+// Config 视频抽帧的服务端配置。ffmpeg 路径只能由服务端配置文件指定，不接受用户输入
+type Config struct {
+	Binary         string  `mapstructure:"binary"`          // ffmpeg 可执行文件路径，为空时在 PATH 中查找
+	MaxFrames      int     `mapstructure:"max_frames"`      // 最多抽取的帧数
+	Interval       int     `mapstructure:"interval"`        // 固定间隔抽帧的最小间隔（秒）
+	SceneThreshold float64 `mapstructure:"scene_threshold"` // 场景切换阈值
+	MaxWidth       int     `mapstructure:"max_width"`       // 帧宽度上限
+	Timeout        int     `mapstructure:"timeout"`         // 下载和抽帧的总超时（秒）
+	MaxSizeMB      int     `mapstructure:"max_size_mb"`     // 视频文件大小上限（MB）
+}
+
+// Frame 关键帧
+type Frame struct {
+	Timestamp float64 // 在视频中的时间点（秒）
+	Data      []byte  // JPEG 数据
+}
+
+// Sampler 视频关键帧抽取器：下载视频后用 ffmpeg 按场景切换抽帧，场景切换过少时按固定间隔抽帧
+type Sampler struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+// ptsTimeRe showinfo 滤镜输出中的帧时间
+var ptsTimeRe = regexp.MustCompile(`pts_time:\s*([0-9.]+)`)
+
+// NewSampler 创建关键帧抽取器，未配置的参数使用默认值
+func NewSampler(cfg Config) *Sampler {
+	if cfg.Binary == "" {
+		cfg.Binary = defaultBinary
+	}
+	if cfg.MaxFrames <= 0 {
+		cfg.MaxFrames = defaultMaxFrames
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.SceneThreshold <= 0 || cfg.SceneThreshold >= 1 {
+		cfg.SceneThreshold = defaultSceneThreshold
+	}
+	if cfg.MaxWidth <= 0 {
+		cfg.MaxWidth = defaultMaxWidth
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxSizeMB <= 0 {
+		cfg.MaxSizeMB = defaultMaxSizeMB
+	}
+	return &Sampler{
+		cfg:        cfg,
+		httpClient: &http.Client{},
+	}
+}
+
+// Available ffmpeg 是否可用
+func (s *Sampler) Available() bool {
+	_, err := exec.LookPath(s.cfg.Binary)
+	return err == nil
+}
+
+// Sample 下载视频并抽取关键帧（按时间顺序），duration 为已知的视频时长（秒），未知时传 0
+func (s *Sampler) Sample(ctx context.Context, videoURL string, duration int) ([]Frame, error) {
+	path, err := exec.LookPath(s.cfg.Binary)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg 不可用: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.Timeout)*time.Second)
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "copycat-video-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input")
+	if err := s.download(ctx, videoURL, input); err != nil {
+		return nil, err
+	}
+
+	// 1. 场景切换抽帧（始终保留首帧，用于分析开头钩子）
+	sceneFilter := fmt.Sprintf("select='eq(n,0)+gt(scene,%.2f)'", s.cfg.SceneThreshold)
+	frames, err := s.extract(ctx, path, input, filepath.Join(dir, "scene"), sceneFilter, s.cfg.MaxFrames*sceneCandidateFactor)
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) >= minSceneFrames {
+		frames = pickEvenly(frames, s.cfg.MaxFrames)
+		logger.Info("视频抽帧完成（场景切换）: %d 帧, url=%s", len(frames), videoURL)
+		return frames, nil
+	}
+
+	// 2. 场景切换过少（固定机位、单镜头等），按固定间隔抽帧，间隔按时长均分
+	interval := s.cfg.Interval
+	if duration > 0 {
+		interval = max(interval, (duration+s.cfg.MaxFrames-1)/s.cfg.MaxFrames)
+	}
+	frames, err = s.extract(ctx, path, input, filepath.Join(dir, "interval"), fmt.Sprintf("fps=1/%d", interval), s.cfg.MaxFrames)
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("未能从视频中抽取到画面")
+	}
+	logger.Info("视频抽帧完成（每 %d 秒）: %d 帧, url=%s", interval, len(frames), videoURL)
+	return frames, nil
+}
+
+// extract 运行 ffmpeg 抽帧：showinfo 输出每帧的时间，缩放到宽度上限后保存为 JPEG
+func (s *Sampler) extract(ctx context.Context, ffmpeg, input, prefix, filter string, limit int) ([]Frame, error) {
+	vf := fmt.Sprintf("%s,showinfo,scale='min(%d,iw)':-2", filter, s.cfg.MaxWidth)
+	args := []string{
+		"-hide_banner", "-nostdin", "-loglevel", "info",
+		"-i", input,
+		"-vf", vf,
+		"-vsync", "vfr",
+		"-frames:v", strconv.Itoa(limit),
+		"-q:v", "3",
+		prefix + "_%03d.jpg",
+	}
+
+	cmd := exec.CommandContext(ctx, ffmpeg, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("视频抽帧超时: %w", ctx.Err())
+		}
+		return nil, fmt.Errorf("ffmpeg 抽帧失败: %w, 输出: %s", err, tail(output, 500))
+	}
+
+	files, err := filepath.Glob(prefix + "_*.jpg")
+	if err != nil {
+		return nil, fmt.Errorf("读取抽帧结果失败: %w", err)
+	}
+	sort.Strings(files)
+
+	var timestamps []float64
+	for _, m := range ptsTimeRe.FindAllSubmatch(output, -1) {
+		if t, err := strconv.ParseFloat(string(m[1]), 64); err == nil {
+			timestamps = append(timestamps, t)
+		}
+	}
+
+	frames := make([]Frame, 0, len(files))
+	for i, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("读取抽帧结果失败: %w", err)
+		}
+		frame := Frame{Data: data}
+		if i < len(timestamps) {
+			frame.Timestamp = timestamps[i]
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// download 带 Referer 下载视频到本地文件，超过大小上限时中止
+func (s *Sampler) download(ctx context.Context, videoURL, dst string) error {
+	u, err := url.Parse(videoURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("无效的视频地址: %s", videoURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", videoURL, nil)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if referer := media.Referer(videoURL); referer != "" {
+		req.Header.Set("Referer", referer)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("下载视频失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("下载视频失败: HTTP %d", resp.StatusCode)
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer f.Close()
+
+	limit := int64(s.cfg.MaxSizeMB) << 20
+	n, err := io.Copy(f, io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return fmt.Errorf("下载视频失败: %w", err)
+	}
+	if n > limit {
+		return fmt.Errorf("视频超过 %d MB", s.cfg.MaxSizeMB)
+	}
+	return nil
+}
+
+// pickEvenly 从按时间排序的帧中均匀选取 n 帧，保留首帧
+func pickEvenly(frames []Frame, n int) []Frame {
+	if len(frames) <= n {
+		return frames
+	}
+	if n == 1 {
+		return frames[:1]
+	}
+	picked := make([]Frame, 0, n)
+	for i := 0; i < n; i++ {
+		picked = append(picked, frames[i*(len(frames)-1)/(n-1)])
+	}
+	return picked
+}
+
+// tail 截取命令输出末尾，便于定位错误
+func tail(output []byte, n int) string {
+	if len(output) > n {
+		output = output[len(output)-n:]
+	}
+	return string(output)
+}
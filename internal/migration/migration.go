@@ -0,0 +1,414 @@
+// Package migration 版本化的数据库迁移：SQL 文件嵌入二进制，文件名为 <版本号>_<名称>.up.sql / .down.sql，
+// 已执行的版本及其 up 脚本的校验和记录在 schema_migrations 表中，每个迁移在独立事务中执行
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"copycat/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// DefaultDir 迁移文件在源码中的目录（create 命令写入的位置）
+const DefaultDir = "internal/migration/sql"
+
+// lockID 执行迁移时持有的 PostgreSQL advisory lock，避免多个实例同时迁移
+const lockID = 7266180001
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+var (
+	// ErrPending 数据库有未执行的迁移
+	ErrPending = errors.New("database has pending migrations")
+	// ErrChecksumMismatch 已执行的迁移文件被修改过
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+)
+
+var (
+	fileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	nameRe = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // up 脚本的 SHA-256
+}
+
+// Status 迁移执行状态
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time // 为空表示未执行
+}
+
+// schemaMigration 已执行的迁移记录
+type schemaMigration struct {
+	Version   int64     `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name;type:varchar(255);not null"`
+	Checksum  string    `gorm:"column:checksum;type:varchar(64);not null;default:''"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null"`
+}
+
+// TableName 指定表名
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New 使用嵌入二进制的迁移文件创建迁移执行器
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load(embedded, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load 读取目录下的迁移文件，按版本号排序；每个版本必须同时有 up 和 down 文件
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	files := make(map[int64]int) // 每个版本已读取的文件数
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		m := fileRe.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(data)
+		} else {
+			mig.Down = string(data)
+		}
+		files[version]++
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if files[mig.Version] != 2 {
+			return nil, fmt.Errorf("migration %d_%s requires both up and down files", mig.Version, mig.Name)
+		}
+		mig.Checksum = Checksum(mig.Up)
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up 按版本顺序执行全部未执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	if err := m.backfillChecksums(ctx); err != nil {
+		return nil, err
+	}
+	records, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksums(m.migrations, records); err != nil {
+		return nil, err
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]Migration, 0, len(pending))
+	for _, mig := range pending {
+		done, err := m.run(ctx, mig, true)
+		if err != nil {
+			return applied, err
+		}
+		if done {
+			applied = append(applied, mig)
+			logger.Info("[Migrate] applied %d_%s", mig.Version, mig.Name)
+		}
+	}
+	return applied, nil
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rolledBack := make([]Migration, 0, steps)
+	for i := len(m.migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		done, err := m.run(ctx, mig, false)
+		if err != nil {
+			return rolledBack, err
+		}
+		if done {
+			rolledBack = append(rolledBack, mig)
+			logger.Info("[Migrate] rolled back %d_%s", mig.Version, mig.Name)
+		}
+	}
+	return rolledBack, nil
+}
+
+// Status 列出全部迁移及其执行时间
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if record, ok := applied[mig.Version]; ok {
+			s.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// Pending 获取未执行的迁移
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending := make([]Migration, 0)
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Check 启动时检查数据库结构是否为最新：已执行的迁移被修改过时返回 ErrChecksumMismatch，有未执行的迁移时返回 ErrPending
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksums(m.migrations, applied); err != nil {
+		return err
+	}
+
+	known := make(map[int64]bool, len(m.migrations))
+	var pending []string
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%d_%s", mig.Version, mig.Name))
+		}
+	}
+	for version := range applied {
+		if !known[version] {
+			logger.Warn("[Migrate] database has migration %d unknown to this build", version)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %s", ErrPending, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// run 在事务中执行一个迁移并更新执行记录；持锁后再次确认状态，其他实例已执行时返回 false
+func (m *Migrator) run(ctx context.Context, mig Migration, up bool) (bool, error) {
+	done := false
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", lockID).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		var count int64
+		if err := tx.Model(&schemaMigration{}).Where("version = ?", mig.Version).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check migration %d: %w", mig.Version, err)
+		}
+		if (count > 0) == up {
+			return nil
+		}
+
+		script := mig.Down
+		if up {
+			script = mig.Up
+		}
+		// 直接交给驱动执行（不经过 GORM 的占位符解析），无参数时支持一次执行多条语句
+		if !blank(script) {
+			if _, err := tx.Statement.ConnPool.ExecContext(ctx, script); err != nil {
+				return fmt.Errorf("failed to run migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+		}
+
+		if up {
+			err := tx.Create(&schemaMigration{Version: mig.Version, Name: mig.Name, Checksum: mig.Checksum, AppliedAt: time.Now()}).Error
+			if err != nil {
+				return fmt.Errorf("failed to record migration %d: %w", mig.Version, err)
+			}
+		} else if err := tx.Delete(&schemaMigration{}, "version = ?", mig.Version).Error; err != nil {
+			return fmt.Errorf("failed to remove migration record %d: %w", mig.Version, err)
+		}
+		done = true
+		return nil
+	})
+	return done, err
+}
+
+// applied 已执行的迁移记录，记录表不存在时视为全部未执行
+func (m *Migrator) applied(ctx context.Context) (map[int64]schemaMigration, error) {
+	db := m.db.WithContext(ctx)
+	applied := make(map[int64]schemaMigration)
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return applied, nil
+	}
+	// 早期版本创建的记录表没有校验和列
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var records []schemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list applied migrations: %w", err)
+	}
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// backfillChecksums 为早期版本执行、没有记录校验和的迁移补上当前文件的校验和
+func (m *Migrator) backfillChecksums(ctx context.Context) error {
+	db := m.db.WithContext(ctx)
+	for _, mig := range m.migrations {
+		err := db.Model(&schemaMigration{}).
+			Where("version = ? AND checksum = ''", mig.Version).
+			Update("checksum", mig.Checksum).Error
+		if err != nil {
+			return fmt.Errorf("failed to backfill checksum of migration %d: %w", mig.Version, err)
+		}
+	}
+	return nil
+}
+
+// verifyChecksums 检查已执行的迁移文件是否被修改过，没有记录校验和的迁移跳过
+func verifyChecksums(migrations []Migration, applied map[int64]schemaMigration) error {
+	for _, mig := range migrations {
+		record, ok := applied[mig.Version]
+		if !ok || record.Checksum == "" {
+			continue
+		}
+		if record.Checksum != mig.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, mig.Version, mig.Name)
+		}
+	}
+	return nil
+}
+
+// Checksum 迁移脚本的 SHA-256（十六进制）
+func Checksum(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	err := m.db.WithContext(ctx).Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name varchar(255) NOT NULL,
+		checksum varchar(64) NOT NULL DEFAULT '',
+		applied_at timestamptz NOT NULL
+	)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	err = m.db.WithContext(ctx).Exec(`ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum varchar(64) NOT NULL DEFAULT ''`).Error
+	if err != nil {
+		return fmt.Errorf("failed to add checksum to schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// Create 在 dir 目录下创建下一个版本的空迁移文件，返回 up 和 down 文件路径
+func Create(dir, name string) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = nameRe.ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return "", "", fmt.Errorf("migration name is required")
+	}
+
+	migrations, err := Load(os.DirFS(dir), ".")
+	if err != nil {
+		return "", "", err
+	}
+	var version int64 = 1
+	if n := len(migrations); n > 0 {
+		version = migrations[n-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%04d_%s", version, name)
+	up := filepath.Join(dir, base+".up.sql")
+	down := filepath.Join(dir, base+".down.sql")
+	if err := os.WriteFile(up, []byte("-- "+name+"\n"), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to create migration: %w", err)
+	}
+	if err := os.WriteFile(down, []byte("-- 回滚 "+name+"\n"), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to create migration: %w", err)
+	}
+	return up, down, nil
+}
+
+// blank SQL 只包含空白和注释
+func blank(script string) bool {
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,213 @@
+package migration
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		versions []int64
+		wantErr  bool
+	}{
+		{
+			name: "按版本号数值排序",
+			fsys: fstest.MapFS{
+				"sql/0010_c.up.sql":   file("C"),
+				"sql/0010_c.down.sql": file("-C"),
+				"sql/0002_b.up.sql":   file("B"),
+				"sql/0002_b.down.sql": file("-B"),
+				"sql/0001_a.up.sql":   file("A"),
+				"sql/0001_a.down.sql": file("-A"),
+				"sql/README.md":       file("ignored"),
+			},
+			versions: []int64{1, 2, 10},
+		},
+		{
+			name: "缺少 down 文件",
+			fsys: fstest.MapFS{
+				"sql/0001_a.up.sql": file("A"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "同一版本号不同名称",
+			fsys: fstest.MapFS{
+				"sql/0001_a.up.sql":   file("A"),
+				"sql/0001_b.down.sql": file("-B"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "文件名不合法",
+			fsys: fstest.MapFS{
+				"sql/add_users.sql": file("A"),
+			},
+			wantErr: true,
+		},
+		{
+			name:     "空目录",
+			fsys:     fstest.MapFS{"sql": &fstest.MapFile{Mode: os.ModeDir}},
+			versions: []int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.fsys, "sql")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if len(migrations) != len(tt.versions) {
+				t.Fatalf("loaded %d migrations, want %d", len(migrations), len(tt.versions))
+			}
+			for i, mig := range migrations {
+				if mig.Version != tt.versions[i] {
+					t.Errorf("migration %d version = %d, want %d", i, mig.Version, tt.versions[i])
+				}
+				if mig.Checksum != Checksum(mig.Up) {
+					t.Errorf("migration %d checksum = %s, want checksum of up script", mig.Version, mig.Checksum)
+				}
+				if mig.Down != "-"+mig.Up {
+					t.Errorf("migration %d up/down mismatched: %q, %q", mig.Version, mig.Up, mig.Down)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := Load(embedded, "sql")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for i, mig := range migrations {
+		if mig.Version != int64(i+1) {
+			t.Errorf("migration %s has version %d, want %d", mig.Name, mig.Version, i+1)
+		}
+		if blank(mig.Up) {
+			t.Errorf("migration %d_%s has an empty up script", mig.Version, mig.Name)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{name: "相同脚本", a: "CREATE TABLE t (id int);", b: "CREATE TABLE t (id int);", same: true},
+		{name: "修改内容", a: "CREATE TABLE t (id int);", b: "CREATE TABLE t (id bigint);"},
+		{name: "只改空白", a: "SELECT 1;", b: "SELECT 1;\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := Checksum(tt.a), Checksum(tt.b)
+			if len(a) != 64 {
+				t.Errorf("checksum length = %d, want 64", len(a))
+			}
+			if (a == b) != tt.same {
+				t.Errorf("Checksum(%q) == Checksum(%q) is %v, want %v", tt.a, tt.b, a == b, tt.same)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	migrations := []Migration{
+		{Version: 1, Name: "a", Checksum: Checksum("A")},
+		{Version: 2, Name: "b", Checksum: Checksum("B")},
+	}
+
+	tests := []struct {
+		name    string
+		applied map[int64]schemaMigration
+		wantErr error
+	}{
+		{
+			name:    "未执行",
+			applied: map[int64]schemaMigration{},
+		},
+		{
+			name: "校验和一致",
+			applied: map[int64]schemaMigration{
+				1: {Version: 1, Checksum: Checksum("A")},
+				2: {Version: 2, Checksum: Checksum("B")},
+			},
+		},
+		{
+			name: "早期版本没有记录校验和",
+			applied: map[int64]schemaMigration{
+				1: {Version: 1},
+			},
+		},
+		{
+			name: "已执行的迁移被修改",
+			applied: map[int64]schemaMigration{
+				1: {Version: 1, Checksum: Checksum("A")},
+				2: {Version: 2, Checksum: Checksum("B2")},
+			},
+			wantErr: ErrChecksumMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyChecksums(migrations, tt.applied)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("verifyChecksums error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		input    string
+		wantBase string
+		wantErr  bool
+	}{
+		{name: "第一个迁移", input: "Add Users", wantBase: "0001_add_users"},
+		{name: "版本号递增", input: "add-index!", wantBase: "0002_add_index"},
+		{name: "名称为空", input: " -- ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, down, err := Create(dir, tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if want := filepath.Join(dir, tt.wantBase+".up.sql"); up != want {
+				t.Errorf("up = %s, want %s", up, want)
+			}
+			if want := filepath.Join(dir, tt.wantBase+".down.sql"); down != want {
+				t.Errorf("down = %s, want %s", down, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+-- 删除全部表（数据不可恢复）
+DROP TABLE IF EXISTS export_jobs;
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS system_settings;
+DROP TABLE IF EXISTS login_throttles;
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS user_identities;
+DROP TABLE IF EXISTS user_tokens;
+DROP TABLE IF EXISTS personal_access_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS workspace_settings;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+DROP TABLE IF EXISTS media_objects;
+DROP TABLE IF EXISTS assets;
+DROP TABLE IF EXISTS generations;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS batch_tasks;
+DROP TABLE IF EXISTS user_settings;
+DROP TABLE IF EXISTS users;
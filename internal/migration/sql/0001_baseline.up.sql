@@ -0,0 +1,569 @@
+-- 基线：本版本全部表结构（此前由 GORM AutoMigrate 创建）。
+-- 全部语句可重复执行，已由 AutoMigrate 建好表的数据库执行 migrate up 后只会补齐缺少的列和索引并记录版本。
+
+-- 用户
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial,
+    email varchar(255) NOT NULL,
+    password varchar(255) NOT NULL,
+    nickname varchar(100),
+    avatar varchar(500),
+    bio text,
+    email_verified boolean NOT NULL DEFAULT false,
+    role varchar(20) NOT NULL DEFAULT 'user',
+    disabled boolean NOT NULL DEFAULT false,
+    token_version bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+-- 由早期版本 AutoMigrate 创建的表缺少后来新增的列
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version bigint NOT NULL DEFAULT 0;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+COMMENT ON COLUMN users.id IS '用户唯一ID(自增)';
+COMMENT ON COLUMN users.email IS '用户邮箱(用于登录)';
+COMMENT ON COLUMN users.password IS '密码哈希';
+COMMENT ON COLUMN users.nickname IS '用户昵称';
+COMMENT ON COLUMN users.avatar IS '头像URL';
+COMMENT ON COLUMN users.bio IS '个人简介';
+COMMENT ON COLUMN users.email_verified IS '邮箱是否已验证';
+COMMENT ON COLUMN users.role IS '系统角色(user/admin)';
+COMMENT ON COLUMN users.disabled IS '是否已被管理员禁用';
+COMMENT ON COLUMN users.token_version IS '令牌版本(修改密码或退出全部设备时递增，旧令牌随即失效)';
+COMMENT ON COLUMN users.created_at IS '创建时间';
+COMMENT ON COLUMN users.updated_at IS '更新时间';
+
+-- 用户设置
+CREATE TABLE IF NOT EXISTS user_settings (
+    id bigserial,
+    user_id bigint NOT NULL,
+    llm_provider varchar(50) DEFAULT 'openai',
+    llm_api_key varchar(500),
+    llm_model varchar(100) DEFAULT 'gpt-3.5-turbo',
+    llm_base_url varchar(500),
+    image_llm_provider varchar(50) DEFAULT 'openai',
+    image_llm_api_key varchar(500),
+    image_llm_model varchar(100) DEFAULT 'gpt-4o',
+    image_llm_base_url varchar(500),
+    video_llm_provider varchar(50) DEFAULT 'openai',
+    video_llm_api_key varchar(500),
+    video_llm_model varchar(100) DEFAULT 'gpt-4o',
+    video_llm_base_url varchar(500),
+    openai_api_key varchar(500),
+    deepseek_api_key varchar(500),
+    moonshot_api_key varchar(500),
+    qwen_api_key varchar(500),
+    hunyuan_api_key varchar(500),
+    doubao_api_key varchar(500),
+    zhipu_api_key varchar(500),
+    anthropic_api_key varchar(500),
+    tts_provider varchar(50) DEFAULT 'dashscope',
+    tts_api_key varchar(500),
+    tts_base_url varchar(500),
+    image_gen_provider varchar(50) DEFAULT 'dashscope',
+    image_gen_api_key varchar(500),
+    image_gen_base_url varchar(500),
+    default_task_type varchar(50) DEFAULT 'contentAnalysis',
+    generate_count bigint DEFAULT 1,
+    originality_threshold decimal DEFAULT 0.5,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_user_settings_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+-- 由早期版本 AutoMigrate 创建的表缺少后来新增的列
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS tts_provider varchar(50) DEFAULT 'dashscope';
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS tts_api_key varchar(500);
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS tts_base_url varchar(500);
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS image_gen_provider varchar(50) DEFAULT 'dashscope';
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS image_gen_api_key varchar(500);
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS image_gen_base_url varchar(500);
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS originality_threshold decimal DEFAULT 0.5;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_settings_user_id ON user_settings (user_id);
+COMMENT ON COLUMN user_settings.id IS '设置ID(自增)';
+COMMENT ON COLUMN user_settings.user_id IS '关联用户ID';
+COMMENT ON COLUMN user_settings.llm_provider IS '文案LLM服务商';
+COMMENT ON COLUMN user_settings.llm_api_key IS '文案LLM API密钥';
+COMMENT ON COLUMN user_settings.llm_model IS '文案LLM模型名称';
+COMMENT ON COLUMN user_settings.llm_base_url IS '文案LLM API基础URL';
+COMMENT ON COLUMN user_settings.image_llm_provider IS '图片LLM服务商';
+COMMENT ON COLUMN user_settings.image_llm_api_key IS '图片LLM API密钥';
+COMMENT ON COLUMN user_settings.image_llm_model IS '图片LLM模型名称';
+COMMENT ON COLUMN user_settings.image_llm_base_url IS '图片LLM API基础URL';
+COMMENT ON COLUMN user_settings.video_llm_provider IS '视频LLM服务商';
+COMMENT ON COLUMN user_settings.video_llm_api_key IS '视频LLM API密钥';
+COMMENT ON COLUMN user_settings.video_llm_model IS '视频LLM模型名称';
+COMMENT ON COLUMN user_settings.video_llm_base_url IS '视频LLM API基础URL';
+COMMENT ON COLUMN user_settings.openai_api_key IS 'OpenAI API密钥';
+COMMENT ON COLUMN user_settings.deepseek_api_key IS 'DeepSeek API密钥';
+COMMENT ON COLUMN user_settings.moonshot_api_key IS 'Moonshot API密钥';
+COMMENT ON COLUMN user_settings.qwen_api_key IS '通义千问 API密钥';
+COMMENT ON COLUMN user_settings.hunyuan_api_key IS '腾讯混元 API密钥';
+COMMENT ON COLUMN user_settings.doubao_api_key IS '豆包 API密钥';
+COMMENT ON COLUMN user_settings.zhipu_api_key IS '智谱 API密钥';
+COMMENT ON COLUMN user_settings.anthropic_api_key IS 'Anthropic API密钥';
+COMMENT ON COLUMN user_settings.tts_provider IS '语音合成服务商(dashscope/openai/local)';
+COMMENT ON COLUMN user_settings.tts_api_key IS '语音合成 API密钥';
+COMMENT ON COLUMN user_settings.tts_base_url IS '语音合成 API基础URL(OpenAI兼容接口)';
+COMMENT ON COLUMN user_settings.image_gen_provider IS '图像生成服务商(dashscope/openai/local)';
+COMMENT ON COLUMN user_settings.image_gen_api_key IS '图像生成 API密钥';
+COMMENT ON COLUMN user_settings.image_gen_base_url IS '图像生成 API基础URL(OpenAI兼容接口)';
+COMMENT ON COLUMN user_settings.default_task_type IS '默认选中的任务类型';
+COMMENT ON COLUMN user_settings.generate_count IS '一次生成的仿写条数(1-10)';
+COMMENT ON COLUMN user_settings.originality_threshold IS '原创度检查相似度阈值(0-1,0表示关闭)';
+COMMENT ON COLUMN user_settings.created_at IS '创建时间';
+COMMENT ON COLUMN user_settings.updated_at IS '更新时间';
+
+-- 批量任务
+CREATE TABLE IF NOT EXISTS batch_tasks (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id bigint NOT NULL,
+    workspace_id bigint NOT NULL DEFAULT 0,
+    total_count bigint NOT NULL,
+    success_count bigint DEFAULT 0,
+    failed_count bigint DEFAULT 0,
+    status varchar(50) DEFAULT 'pending',
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+-- 由早期版本 AutoMigrate 创建的表缺少后来新增的列
+ALTER TABLE batch_tasks ADD COLUMN IF NOT EXISTS workspace_id bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_batch_tasks_created_at ON batch_tasks (created_at desc);
+CREATE INDEX IF NOT EXISTS idx_batch_tasks_status ON batch_tasks (status);
+CREATE INDEX IF NOT EXISTS idx_batch_tasks_workspace_id ON batch_tasks (workspace_id);
+CREATE INDEX IF NOT EXISTS idx_batch_tasks_user_id ON batch_tasks (user_id);
+COMMENT ON COLUMN batch_tasks.id IS '批次任务ID';
+COMMENT ON COLUMN batch_tasks.user_id IS '创建者用户ID';
+COMMENT ON COLUMN batch_tasks.workspace_id IS '所属工作区ID';
+COMMENT ON COLUMN batch_tasks.total_count IS '总链接数';
+COMMENT ON COLUMN batch_tasks.success_count IS '成功数';
+COMMENT ON COLUMN batch_tasks.failed_count IS '失败数';
+COMMENT ON COLUMN batch_tasks.status IS '任务状态(pending/processing/completed/failed)';
+COMMENT ON COLUMN batch_tasks.created_at IS '创建时间';
+COMMENT ON COLUMN batch_tasks.updated_at IS '更新时间';
+
+-- 创作项目
+CREATE TABLE IF NOT EXISTS projects (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id bigint NOT NULL,
+    workspace_id bigint NOT NULL DEFAULT 0,
+    batch_task_id uuid,
+    source_url text,
+    source_content text NOT NULL,
+    content_type varchar(20) DEFAULT 'text',
+    analysis_result JSONB,
+    new_topic varchar(500),
+    generated_content text,
+    status varchar(50) DEFAULT 'draft',
+    batch_error varchar(500),
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+-- 由早期版本 AutoMigrate 创建的表缺少后来新增的列
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS workspace_id bigint NOT NULL DEFAULT 0;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS batch_error varchar(500);
+CREATE INDEX IF NOT EXISTS idx_projects_created_at ON projects (created_at desc);
+CREATE INDEX IF NOT EXISTS idx_projects_status ON projects (status);
+CREATE INDEX IF NOT EXISTS idx_projects_batch_task_id ON projects (batch_task_id);
+CREATE INDEX IF NOT EXISTS idx_projects_workspace_id ON projects (workspace_id);
+CREATE INDEX IF NOT EXISTS idx_projects_user_id ON projects (user_id);
+COMMENT ON COLUMN projects.id IS '项目唯一ID(UUID)';
+COMMENT ON COLUMN projects.user_id IS '创建者用户ID';
+COMMENT ON COLUMN projects.workspace_id IS '所属工作区ID';
+COMMENT ON COLUMN projects.batch_task_id IS '关联批量任务ID(可选)';
+COMMENT ON COLUMN projects.source_url IS '原始文案来源URL(小红书/公众号)';
+COMMENT ON COLUMN projects.source_content IS '爬取/输入的原始文案内容';
+COMMENT ON COLUMN projects.content_type IS '内容类型(text/video/images)';
+COMMENT ON COLUMN projects.analysis_result IS 'LLM分析结果(情绪/结构/关键词)';
+COMMENT ON COLUMN projects.new_topic IS '用户输入的新主题';
+COMMENT ON COLUMN projects.generated_content IS 'LLM生成的仿写文案';
+COMMENT ON COLUMN projects.status IS '项目状态(draft/analyzed/completed/failed)';
+COMMENT ON COLUMN projects.batch_error IS '批量处理失败原因';
+COMMENT ON COLUMN projects.created_at IS '创建时间';
+COMMENT ON COLUMN projects.updated_at IS '更新时间';
+
+-- 仿写生成记录
+CREATE TABLE IF NOT EXISTS generations (
+    id uuid DEFAULT gen_random_uuid(),
+    project_id uuid NOT NULL,
+    user_id bigint NOT NULL,
+    parent_id uuid,
+    variant_index bigint DEFAULT 1,
+    new_topic varchar(500),
+    instruction text,
+    content text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_generations_parent_id ON generations (parent_id);
+CREATE INDEX IF NOT EXISTS idx_generations_user_id ON generations (user_id);
+CREATE INDEX IF NOT EXISTS idx_generations_project_id ON generations (project_id);
+COMMENT ON COLUMN generations.id IS '生成记录ID(UUID)';
+COMMENT ON COLUMN generations.project_id IS '关联项目ID';
+COMMENT ON COLUMN generations.user_id IS '关联用户ID';
+COMMENT ON COLUMN generations.parent_id IS '父版本ID(精修版本指向被精修的版本)';
+COMMENT ON COLUMN generations.variant_index IS '同批次生成中的序号(从1开始)';
+COMMENT ON COLUMN generations.new_topic IS '生成时使用的新主题';
+COMMENT ON COLUMN generations.instruction IS '精修指令(原始生成为空)';
+COMMENT ON COLUMN generations.content IS '生成的文案内容';
+COMMENT ON COLUMN generations.created_at IS '创建时间';
+COMMENT ON COLUMN generations.updated_at IS '更新时间';
+
+-- 项目资源文件（音频、图片）
+CREATE TABLE IF NOT EXISTS assets (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id bigint NOT NULL,
+    project_id uuid,
+    generation_id uuid,
+    kind varchar(20) NOT NULL,
+    storage_key varchar(500) NOT NULL,
+    content_type varchar(100),
+    format varchar(20),
+    size bigint,
+    duration_ms bigint,
+    meta JSONB,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_assets_kind ON assets (kind);
+CREATE INDEX IF NOT EXISTS idx_assets_generation_id ON assets (generation_id);
+CREATE INDEX IF NOT EXISTS idx_assets_project_id ON assets (project_id);
+CREATE INDEX IF NOT EXISTS idx_assets_user_id ON assets (user_id);
+COMMENT ON COLUMN assets.id IS '资源ID(UUID)';
+COMMENT ON COLUMN assets.user_id IS '所属用户ID';
+COMMENT ON COLUMN assets.project_id IS '关联项目ID(可选)';
+COMMENT ON COLUMN assets.generation_id IS '关联生成记录ID(可选)';
+COMMENT ON COLUMN assets.kind IS '资源类型(audio/image)';
+COMMENT ON COLUMN assets.storage_key IS '对象存储中的键';
+COMMENT ON COLUMN assets.content_type IS 'MIME类型';
+COMMENT ON COLUMN assets.format IS '文件格式(mp3/wav/png/jpeg)';
+COMMENT ON COLUMN assets.size IS '文件大小(字节)';
+COMMENT ON COLUMN assets.duration_ms IS '时长(毫秒)';
+COMMENT ON COLUMN assets.meta IS '附加信息(音色/模型/分段时间轴等)';
+COMMENT ON COLUMN assets.created_at IS '创建时间';
+
+-- 笔记图片缓存
+CREATE TABLE IF NOT EXISTS media_objects (
+    hash varchar(64),
+    source_url text,
+    content_type varchar(100),
+    format varchar(20),
+    width bigint,
+    height bigint,
+    size bigint,
+    storage_key varchar(500) NOT NULL,
+    vision_key varchar(500),
+    vision_size bigint,
+    created_at timestamptz,
+    PRIMARY KEY (hash)
+);
+CREATE INDEX IF NOT EXISTS idx_media_objects_source_url ON media_objects (source_url);
+COMMENT ON COLUMN media_objects.hash IS '原图内容SHA-256(十六进制)';
+COMMENT ON COLUMN media_objects.source_url IS '首次下载的来源URL';
+COMMENT ON COLUMN media_objects.content_type IS 'MIME类型';
+COMMENT ON COLUMN media_objects.format IS '图片格式(jpeg/png/webp/gif)';
+COMMENT ON COLUMN media_objects.width IS '宽度(像素)';
+COMMENT ON COLUMN media_objects.height IS '高度(像素)';
+COMMENT ON COLUMN media_objects.size IS '原图大小(字节)';
+COMMENT ON COLUMN media_objects.storage_key IS '原图在对象存储中的键';
+COMMENT ON COLUMN media_objects.vision_key IS '压缩图在对象存储中的键(为空表示直接使用原图)';
+COMMENT ON COLUMN media_objects.vision_size IS '压缩图大小(字节)';
+COMMENT ON COLUMN media_objects.created_at IS '创建时间';
+
+-- 工作区
+CREATE TABLE IF NOT EXISTS workspaces (
+    id bigserial,
+    name varchar(100) NOT NULL,
+    owner_id bigint NOT NULL,
+    personal boolean DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_workspaces_owner_id ON workspaces (owner_id);
+COMMENT ON COLUMN workspaces.id IS '工作区ID(自增)';
+COMMENT ON COLUMN workspaces.name IS '工作区名称';
+COMMENT ON COLUMN workspaces.owner_id IS '创建者用户ID';
+COMMENT ON COLUMN workspaces.personal IS '是否为个人工作区(注册时自动创建,不可删除)';
+COMMENT ON COLUMN workspaces.created_at IS '创建时间';
+COMMENT ON COLUMN workspaces.updated_at IS '更新时间';
+
+-- 工作区成员
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id bigint,
+    user_id bigint,
+    role varchar(20) NOT NULL DEFAULT 'viewer',
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (workspace_id,user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user_id ON workspace_members (user_id);
+COMMENT ON COLUMN workspace_members.workspace_id IS '工作区ID';
+COMMENT ON COLUMN workspace_members.user_id IS '成员用户ID';
+COMMENT ON COLUMN workspace_members.role IS '角色(owner/editor/viewer)';
+COMMENT ON COLUMN workspace_members.created_at IS '加入时间';
+COMMENT ON COLUMN workspace_members.updated_at IS '更新时间';
+
+-- 工作区共享 API Key
+CREATE TABLE IF NOT EXISTS workspace_settings (
+    id bigserial,
+    workspace_id bigint NOT NULL,
+    openai_api_key varchar(500),
+    deepseek_api_key varchar(500),
+    moonshot_api_key varchar(500),
+    qwen_api_key varchar(500),
+    hunyuan_api_key varchar(500),
+    doubao_api_key varchar(500),
+    zhipu_api_key varchar(500),
+    anthropic_api_key varchar(500),
+    tts_api_key varchar(500),
+    image_gen_api_key varchar(500),
+    updated_by bigint,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workspace_settings_workspace_id ON workspace_settings (workspace_id);
+COMMENT ON COLUMN workspace_settings.id IS '设置ID(自增)';
+COMMENT ON COLUMN workspace_settings.workspace_id IS '关联工作区ID';
+COMMENT ON COLUMN workspace_settings.openai_api_key IS 'OpenAI API密钥';
+COMMENT ON COLUMN workspace_settings.deepseek_api_key IS 'DeepSeek API密钥';
+COMMENT ON COLUMN workspace_settings.moonshot_api_key IS 'Moonshot API密钥';
+COMMENT ON COLUMN workspace_settings.qwen_api_key IS '通义千问 API密钥';
+COMMENT ON COLUMN workspace_settings.hunyuan_api_key IS '腾讯混元 API密钥';
+COMMENT ON COLUMN workspace_settings.doubao_api_key IS '豆包 API密钥';
+COMMENT ON COLUMN workspace_settings.zhipu_api_key IS '智谱 API密钥';
+COMMENT ON COLUMN workspace_settings.anthropic_api_key IS 'Anthropic API密钥';
+COMMENT ON COLUMN workspace_settings.tts_api_key IS '语音合成 API密钥';
+COMMENT ON COLUMN workspace_settings.image_gen_api_key IS '图像生成 API密钥';
+COMMENT ON COLUMN workspace_settings.updated_by IS '最后修改人用户ID';
+COMMENT ON COLUMN workspace_settings.created_at IS '创建时间';
+COMMENT ON COLUMN workspace_settings.updated_at IS '更新时间';
+
+-- 刷新令牌
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id bigserial,
+    user_id bigint NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    family_id varchar(36) NOT NULL,
+    token_version bigint NOT NULL DEFAULT 0,
+    user_agent varchar(500),
+    client_ip varchar(64),
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens (expires_at);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+COMMENT ON COLUMN refresh_tokens.id IS '主键ID';
+COMMENT ON COLUMN refresh_tokens.user_id IS '用户ID';
+COMMENT ON COLUMN refresh_tokens.token_hash IS '令牌SHA-256(十六进制)';
+COMMENT ON COLUMN refresh_tokens.family_id IS '令牌家族ID(同一次登录轮换出的令牌共享)';
+COMMENT ON COLUMN refresh_tokens.token_version IS '签发时的用户令牌版本';
+COMMENT ON COLUMN refresh_tokens.user_agent IS '签发时的客户端UA';
+COMMENT ON COLUMN refresh_tokens.client_ip IS '签发时的客户端IP';
+COMMENT ON COLUMN refresh_tokens.expires_at IS '过期时间';
+COMMENT ON COLUMN refresh_tokens.revoked_at IS '作废时间(轮换或退出登录)';
+COMMENT ON COLUMN refresh_tokens.created_at IS '创建时间';
+
+-- 个人访问令牌
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id bigserial,
+    user_id bigint NOT NULL,
+    name varchar(100) NOT NULL,
+    prefix varchar(20) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    scopes JSONB,
+    last_used_at timestamptz,
+    expires_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_personal_access_tokens_token_hash ON personal_access_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens (user_id);
+COMMENT ON COLUMN personal_access_tokens.id IS '主键ID';
+COMMENT ON COLUMN personal_access_tokens.user_id IS '所属用户ID';
+COMMENT ON COLUMN personal_access_tokens.name IS '令牌名称';
+COMMENT ON COLUMN personal_access_tokens.prefix IS '令牌前缀(用于辨认)';
+COMMENT ON COLUMN personal_access_tokens.token_hash IS '令牌SHA-256(十六进制)';
+COMMENT ON COLUMN personal_access_tokens.scopes IS '权限范围';
+COMMENT ON COLUMN personal_access_tokens.last_used_at IS '最后使用时间';
+COMMENT ON COLUMN personal_access_tokens.expires_at IS '过期时间(为空表示永不过期)';
+COMMENT ON COLUMN personal_access_tokens.revoked_at IS '吊销时间';
+COMMENT ON COLUMN personal_access_tokens.created_at IS '创建时间';
+
+-- 邮件一次性令牌
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id bigserial,
+    user_id bigint NOT NULL,
+    purpose varchar(30) NOT NULL,
+    email varchar(255) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_tokens_token_hash ON user_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id ON user_tokens (user_id);
+COMMENT ON COLUMN user_tokens.id IS '主键ID';
+COMMENT ON COLUMN user_tokens.user_id IS '用户ID';
+COMMENT ON COLUMN user_tokens.purpose IS '用途(email_verify/password_reset)';
+COMMENT ON COLUMN user_tokens.email IS '签发时的邮箱(邮箱变更后旧令牌失效)';
+COMMENT ON COLUMN user_tokens.token_hash IS '令牌SHA-256(十六进制)';
+COMMENT ON COLUMN user_tokens.expires_at IS '过期时间';
+COMMENT ON COLUMN user_tokens.used_at IS '使用时间(为空表示未使用)';
+COMMENT ON COLUMN user_tokens.created_at IS '创建时间';
+
+-- 单点登录身份
+CREATE TABLE IF NOT EXISTS user_identities (
+    id bigserial,
+    user_id bigint NOT NULL,
+    issuer varchar(255) NOT NULL,
+    subject varchar(255) NOT NULL,
+    email varchar(255),
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identity_issuer_subject ON user_identities (issuer,subject);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+COMMENT ON COLUMN user_identities.id IS '主键ID';
+COMMENT ON COLUMN user_identities.user_id IS '本地用户ID';
+COMMENT ON COLUMN user_identities.issuer IS '身份提供方(iss)';
+COMMENT ON COLUMN user_identities.subject IS '身份提供方中的用户标识(sub)';
+COMMENT ON COLUMN user_identities.email IS '最近一次登录时的邮箱';
+COMMENT ON COLUMN user_identities.created_at IS '关联时间';
+COMMENT ON COLUMN user_identities.updated_at IS '最近登录时间';
+
+-- 登录记录
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id bigserial,
+    user_id bigint NOT NULL DEFAULT 0,
+    email varchar(255) NOT NULL,
+    ip varchar(64) NOT NULL,
+    user_agent varchar(500),
+    success boolean NOT NULL DEFAULT false,
+    result varchar(30) NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_created_at ON login_attempts (created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts (ip);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email ON login_attempts (email);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user_id ON login_attempts (user_id);
+COMMENT ON COLUMN login_attempts.id IS '主键ID';
+COMMENT ON COLUMN login_attempts.user_id IS '用户ID(邮箱未注册时为0)';
+COMMENT ON COLUMN login_attempts.email IS '登录邮箱(小写)';
+COMMENT ON COLUMN login_attempts.ip IS '客户端IP';
+COMMENT ON COLUMN login_attempts.user_agent IS '客户端UA';
+COMMENT ON COLUMN login_attempts.success IS '是否登录成功';
+COMMENT ON COLUMN login_attempts.result IS '结果(success/bad_password/unknown_user/throttled/email_not_verified/disabled)';
+COMMENT ON COLUMN login_attempts.created_at IS '登录时间';
+
+-- 登录限流
+CREATE TABLE IF NOT EXISTS login_throttles (
+    key varchar(320),
+    failures bigint NOT NULL DEFAULT 0,
+    last_failure_at timestamptz,
+    locked_until timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (key)
+);
+CREATE INDEX IF NOT EXISTS idx_login_throttles_locked_until ON login_throttles (locked_until);
+COMMENT ON COLUMN login_throttles.key IS '限流键(email:xxx/ip:xxx)';
+COMMENT ON COLUMN login_throttles.failures IS '统计窗口内的连续失败次数';
+COMMENT ON COLUMN login_throttles.last_failure_at IS '最近一次失败时间';
+COMMENT ON COLUMN login_throttles.locked_until IS '锁定截止时间';
+COMMENT ON COLUMN login_throttles.updated_at IS '更新时间';
+
+-- 系统设置
+CREATE TABLE IF NOT EXISTS system_settings (
+    id bigserial,
+    default_llm_provider varchar(50) NOT NULL DEFAULT 'openai',
+    default_llm_model varchar(100) NOT NULL DEFAULT 'gpt-3.5-turbo',
+    default_image_llm_model varchar(100) NOT NULL DEFAULT 'gpt-4o',
+    default_video_llm_model varchar(100) NOT NULL DEFAULT 'gpt-4o',
+    default_generate_count bigint NOT NULL DEFAULT 1,
+    max_generate_count bigint NOT NULL DEFAULT 10,
+    batch_concurrency bigint NOT NULL DEFAULT 2,
+    updated_by bigint NOT NULL DEFAULT 0,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+COMMENT ON COLUMN system_settings.id IS '固定为1';
+COMMENT ON COLUMN system_settings.default_llm_provider IS '默认LLM服务商';
+COMMENT ON COLUMN system_settings.default_llm_model IS '默认文案LLM模型';
+COMMENT ON COLUMN system_settings.default_image_llm_model IS '默认图片LLM模型';
+COMMENT ON COLUMN system_settings.default_video_llm_model IS '默认视频LLM模型';
+COMMENT ON COLUMN system_settings.default_generate_count IS '默认一次生成的仿写条数';
+COMMENT ON COLUMN system_settings.max_generate_count IS '一次生成的仿写条数上限';
+COMMENT ON COLUMN system_settings.batch_concurrency IS '批量任务并发处理的链接数';
+COMMENT ON COLUMN system_settings.updated_by IS '最后修改的管理员ID';
+COMMENT ON COLUMN system_settings.updated_at IS '更新时间';
+
+-- 审计日志
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id bigserial,
+    actor_id bigint NOT NULL DEFAULT 0,
+    workspace_id bigint NOT NULL DEFAULT 0,
+    action varchar(100) NOT NULL,
+    target_type varchar(50),
+    target_id varchar(100),
+    ip varchar(64),
+    user_agent varchar(500),
+    changes JSONB,
+    details JSONB,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target_id ON audit_logs (target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_workspace_id ON audit_logs (workspace_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+COMMENT ON COLUMN audit_logs.id IS '主键ID';
+COMMENT ON COLUMN audit_logs.actor_id IS '操作人用户ID';
+COMMENT ON COLUMN audit_logs.workspace_id IS '所属工作区ID(与工作区无关的操作为0)';
+COMMENT ON COLUMN audit_logs.action IS '操作(如project.delete)';
+COMMENT ON COLUMN audit_logs.target_type IS '操作对象类型';
+COMMENT ON COLUMN audit_logs.target_id IS '操作对象ID';
+COMMENT ON COLUMN audit_logs.ip IS '客户端IP';
+COMMENT ON COLUMN audit_logs.user_agent IS '客户端UA';
+COMMENT ON COLUMN audit_logs.changes IS '变更内容(敏感字段已脱敏)';
+COMMENT ON COLUMN audit_logs.details IS '附加信息';
+COMMENT ON COLUMN audit_logs.created_at IS '操作时间';
+
+-- 数据导出任务
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id bigint NOT NULL,
+    status varchar(20) NOT NULL DEFAULT 'pending',
+    storage_key varchar(500),
+    size bigint,
+    error varchar(500),
+    expires_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_export_jobs_user_id ON export_jobs (user_id);
+COMMENT ON COLUMN export_jobs.id IS '导出任务ID(UUID)';
+COMMENT ON COLUMN export_jobs.user_id IS '所属用户ID';
+COMMENT ON COLUMN export_jobs.status IS '任务状态(pending/processing/completed/failed)';
+COMMENT ON COLUMN export_jobs.storage_key IS 'zip在对象存储中的键';
+COMMENT ON COLUMN export_jobs.size IS 'zip大小(字节)';
+COMMENT ON COLUMN export_jobs.error IS '失败原因';
+COMMENT ON COLUMN export_jobs.expires_at IS '下载过期时间';
+COMMENT ON COLUMN export_jobs.created_at IS '创建时间';
+COMMENT ON COLUMN export_jobs.updated_at IS '更新时间';
@@ -0,0 +1 @@
+-- 数据回填无需回滚：个人工作区和项目归属保持不变
@@ -0,0 +1,23 @@
+-- 为还没有个人工作区的用户创建个人工作区，并把未归属工作区的项目和批量任务迁入创建者的个人工作区
+-- （此前在每次启动时由 WorkspaceRepository.BackfillPersonal 执行）
+INSERT INTO workspaces (name, owner_id, personal, created_at, updated_at)
+SELECT COALESCE(NULLIF(u.nickname, ''), u.email) || ' 的工作区', u.id, true, NOW(), NOW()
+FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM workspaces w WHERE w.owner_id = u.id AND w.personal);
+
+-- 创建者同时成为所有者
+INSERT INTO workspace_members (workspace_id, user_id, role, created_at, updated_at)
+SELECT w.id, w.owner_id, 'owner', NOW(), NOW()
+FROM workspaces w
+WHERE w.personal
+  AND NOT EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id AND m.user_id = w.owner_id);
+
+UPDATE projects p
+SET workspace_id = w.id
+FROM workspaces w
+WHERE p.workspace_id = 0 AND w.owner_id = p.user_id AND w.personal;
+
+UPDATE batch_tasks t
+SET workspace_id = w.id
+FROM workspaces w
+WHERE t.workspace_id = 0 AND w.owner_id = t.user_id AND w.personal;
@@ -0,0 +1 @@
+ALTER TABLE generations DROP COLUMN IF EXISTS platform;
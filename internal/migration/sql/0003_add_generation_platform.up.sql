@@ -0,0 +1,3 @@
+-- 生成记录保存目标发布平台，精修时沿用生成时的平台校验
+ALTER TABLE generations ADD COLUMN IF NOT EXISTS platform varchar(20);
+COMMENT ON COLUMN generations.platform IS '目标发布平台';
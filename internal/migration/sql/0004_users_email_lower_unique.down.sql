@@ -0,0 +1,3 @@
+-- 已转为小写的邮箱保持不变
+DROP INDEX IF EXISTS idx_users_email_lower;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
@@ -0,0 +1,14 @@
+-- 邮箱不区分大小写：已有邮箱统一转小写去空格，唯一索引改为建在 lower(email) 上，
+-- 避免用大小写变体重复注册同一邮箱（如冒充 admin.emails 中的管理员邮箱）
+DROP INDEX IF EXISTS idx_users_email;
+
+-- 只规范化不会与其他账号冲突的邮箱；仍有大小写重复的账号时创建索引失败，需人工合并或删除后重新执行
+UPDATE users u
+SET email = lower(trim(u.email))
+WHERE u.email <> lower(trim(u.email))
+  AND NOT EXISTS (
+    SELECT 1 FROM users o
+    WHERE o.id <> u.id AND lower(trim(o.email)) = lower(trim(u.email))
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Asset 项目资源文件（合成音频、生成图片等），文件本体保存在对象存储中
+type Asset struct {
+	ID           uuid.UUID      `gorm:"column:id;type:uuid;primaryKey;default:gen_random_uuid();comment:资源ID(UUID)" json:"id"`
+	UserID       int64          `gorm:"column:user_id;not null;index;comment:所属用户ID" json:"user_id"`
+	ProjectID    *uuid.UUID     `gorm:"column:project_id;type:uuid;index;comment:关联项目ID(可选)" json:"project_id,omitempty"`
+	GenerationID *uuid.UUID     `gorm:"column:generation_id;type:uuid;index;comment:关联生成记录ID(可选)" json:"generation_id,omitempty"`
+	Kind         string         `gorm:"column:kind;type:varchar(20);not null;index;comment:资源类型(audio/image)" json:"kind"`
+	StorageKey   string         `gorm:"column:storage_key;type:varchar(500);not null;comment:对象存储中的键" json:"-"`
+	ContentType  string         `gorm:"column:content_type;type:varchar(100);comment:MIME类型" json:"content_type"`
+	Format       string         `gorm:"column:format;type:varchar(20);comment:文件格式(mp3/wav/png/jpeg)" json:"format"`
+	Size         int64          `gorm:"column:size;comment:文件大小(字节)" json:"size"`
+	DurationMs   int64          `gorm:"column:duration_ms;comment:时长(毫秒)" json:"duration_ms"`
+	Meta         datatypes.JSON `gorm:"column:meta;type:jsonb;comment:附加信息(音色/模型/分段时间轴等)" json:"meta"`
+	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+}
+
+// TableName 指定表名
+func (Asset) TableName() string {
+	return "assets"
+}
+
+// AssetKind 资源类型常量
+const (
+	AssetKindAudio = "audio" // 合成音频
+	AssetKindImage = "image" // 生成图片
+)
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrAuditLogImmutable 审计日志只能追加，不能修改或删除
+var ErrAuditLogImmutable = errors.New("audit logs are append-only")
+
+// 审计操作
+const (
+	AuditUserPasswordChange = "user.password_change"
+	AuditUserPasswordReset  = "user.password_reset"
+	AuditUserProfileUpdate  = "user.profile_update"
+	AuditUserLogoutAll      = "user.logout_all"
+	AuditUserDataExport     = "user.data_export"
+	AuditUserAccountDelete  = "user.account_delete"
+
+	AuditTokenCreate = "token.create"
+	AuditTokenRevoke = "token.revoke"
+
+	AuditWorkspaceCreate       = "workspace.create"
+	AuditWorkspaceUpdate       = "workspace.update"
+	AuditWorkspaceDelete       = "workspace.delete"
+	AuditWorkspaceMemberAdd    = "workspace.member_add"
+	AuditWorkspaceMemberUpdate = "workspace.member_update"
+	AuditWorkspaceMemberRemove = "workspace.member_remove"
+	AuditWorkspaceSettings     = "workspace.settings_update"
+
+	AuditProjectUpdate      = "project.update"
+	AuditProjectDelete      = "project.delete"
+	AuditProjectBatchDelete = "project.batch_delete"
+	AuditSpeechDelete       = "speech.delete"
+	AuditImageDelete        = "image.delete"
+
+	AuditSettingsApiConfig      = "settings.api_config"
+	AuditSettingsModelConfig    = "settings.model_config"
+	AuditSettingsGenerateConfig = "settings.generate_config"
+	AuditSettingsTaskType       = "settings.task_type"
+	AuditSettingsTTSConfig      = "settings.tts_config"
+	AuditSettingsImageGenConfig = "settings.image_gen_config"
+
+	AuditAdminUserUpdate     = "admin.user_update"
+	AuditAdminSystemSettings = "admin.system_settings_update"
+	AuditAdminLoginUnlock    = "admin.login_unlock"
+)
+
+// AuditLog 审计日志（只追加）：记录操作人、操作、对象、来源 IP 和脱敏后的变更内容
+type AuditLog struct {
+	ID          int64          `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	ActorID     int64          `gorm:"column:actor_id;not null;default:0;index;comment:操作人用户ID" json:"actor_id"`
+	WorkspaceID int64          `gorm:"column:workspace_id;not null;default:0;index;comment:所属工作区ID(与工作区无关的操作为0)" json:"workspace_id"`
+	Action      string         `gorm:"column:action;type:varchar(100);not null;index;comment:操作(如project.delete)" json:"action"`
+	TargetType  string         `gorm:"column:target_type;type:varchar(50);comment:操作对象类型" json:"target_type"`
+	TargetID    string         `gorm:"column:target_id;type:varchar(100);index;comment:操作对象ID" json:"target_id"`
+	IP          string         `gorm:"column:ip;type:varchar(64);comment:客户端IP" json:"ip"`
+	UserAgent   string         `gorm:"column:user_agent;type:varchar(500);comment:客户端UA" json:"user_agent"`
+	Changes     datatypes.JSON `gorm:"column:changes;type:jsonb;comment:变更内容(敏感字段已脱敏)" json:"changes,omitempty"`
+	Details     datatypes.JSON `gorm:"column:details;type:jsonb;comment:附加信息" json:"details,omitempty"`
+	CreatedAt   time.Time      `gorm:"column:created_at;autoCreateTime;index;comment:操作时间" json:"created_at"`
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// BeforeUpdate 禁止修改审计日志
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+// BeforeDelete 禁止删除审计日志
+func (AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
@@ -9,7 +9,8 @@ import (
 // BatchTask 批量分析任务模型
 type BatchTask struct {
 	ID           uuid.UUID `gorm:"column:id;type:uuid;primaryKey;default:gen_random_uuid();comment:批次任务ID" json:"id"`
-	UserID       int64     `gorm:"column:user_id;not null;index;comment:关联用户ID" json:"user_id"`
+	UserID       int64     `gorm:"column:user_id;not null;index;comment:创建者用户ID" json:"user_id"`
+	WorkspaceID  int64     `gorm:"column:workspace_id;not null;default:0;index;comment:所属工作区ID" json:"workspace_id"`
 	TotalCount   int       `gorm:"column:total_count;not null;comment:总链接数" json:"total_count"`
 	SuccessCount int       `gorm:"column:success_count;default:0;comment:成功数" json:"success_count"`
 	FailedCount  int       `gorm:"column:failed_count;default:0;comment:失败数" json:"failed_count"`
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportJob 账号数据导出任务，打包好的 zip 保存在对象存储中，过期后不再提供下载
+type ExportJob struct {
+	ID         uuid.UUID  `gorm:"column:id;type:uuid;primaryKey;default:gen_random_uuid();comment:导出任务ID(UUID)" json:"id"`
+	UserID     int64      `gorm:"column:user_id;not null;index;comment:所属用户ID" json:"user_id"`
+	Status     string     `gorm:"column:status;type:varchar(20);not null;default:pending;comment:任务状态(pending/processing/completed/failed)" json:"status"`
+	StorageKey string     `gorm:"column:storage_key;type:varchar(500);comment:zip在对象存储中的键" json:"-"`
+	Size       int64      `gorm:"column:size;comment:zip大小(字节)" json:"size"`
+	Error      string     `gorm:"column:error;type:varchar(500);comment:失败原因" json:"error,omitempty"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at;comment:下载过期时间" json:"expires_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ExportJob) TableName() string {
+	return "export_jobs"
+}
+
+// 导出任务状态常量
+const (
+	ExportJobStatusPending    = "pending"    // 等待处理
+	ExportJobStatusProcessing = "processing" // 打包中
+	ExportJobStatusCompleted  = "completed"  // 可下载
+	ExportJobStatusFailed     = "failed"     // 失败
+)
+
+// Active 任务是否仍在进行中
+func (j *ExportJob) Active() bool {
+	return j.Status == ExportJobStatusPending || j.Status == ExportJobStatusProcessing
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Generation 仿写生成记录（每条变体一行，精修版本通过 ParentID 挂在原版本下）
+type Generation struct {
+	ID           uuid.UUID  `gorm:"column:id;type:uuid;primaryKey;default:gen_random_uuid();comment:生成记录ID(UUID)" json:"id"`
+	ProjectID    uuid.UUID  `gorm:"column:project_id;type:uuid;not null;index;comment:关联项目ID" json:"project_id"`
+	UserID       int64      `gorm:"column:user_id;not null;index;comment:关联用户ID" json:"user_id"`
+	ParentID     *uuid.UUID `gorm:"column:parent_id;type:uuid;index;comment:父版本ID(精修版本指向被精修的版本)" json:"parent_id,omitempty"`
+	VariantIndex int        `gorm:"column:variant_index;default:1;comment:同批次生成中的序号(从1开始)" json:"variant_index"`
+	NewTopic     string     `gorm:"column:new_topic;type:varchar(500);comment:生成时使用的新主题" json:"new_topic"`
+	Instruction  string     `gorm:"column:instruction;type:text;comment:精修指令(原始生成为空)" json:"instruction,omitempty"`
+	Content      string     `gorm:"column:content;type:text;not null;comment:生成的文案内容" json:"content"`
+	Platform     string     `gorm:"column:platform;type:varchar(20);comment:目标发布平台" json:"platform,omitempty"`
+	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Generation) TableName() string {
+	return "generations"
+}
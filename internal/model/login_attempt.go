@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+// 登录结果
+const (
+	LoginResultSuccess     = "success"
+	LoginResultBadPassword = "bad_password"
+	LoginResultUnknownUser = "unknown_user"
+	LoginResultThrottled   = "throttled"
+	LoginResultNotVerified = "email_not_verified"
+	LoginResultDisabled    = "disabled"
+)
+
+// LoginAttempt 登录记录（成功和失败都记录，用于审计和排查暴力破解）
+type LoginAttempt struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	UserID    int64     `gorm:"column:user_id;not null;default:0;index;comment:用户ID(邮箱未注册时为0)" json:"user_id"`
+	Email     string    `gorm:"column:email;type:varchar(255);not null;index;comment:登录邮箱(小写)" json:"email"`
+	IP        string    `gorm:"column:ip;type:varchar(64);not null;index;comment:客户端IP" json:"ip"`
+	UserAgent string    `gorm:"column:user_agent;type:varchar(500);comment:客户端UA" json:"user_agent"`
+	Success   bool      `gorm:"column:success;not null;default:false;comment:是否登录成功" json:"success"`
+	Result    string    `gorm:"column:result;type:varchar(30);not null;comment:结果(success/bad_password/unknown_user/throttled/email_not_verified/disabled)" json:"result"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;index;comment:登录时间" json:"created_at"`
+}
+
+// TableName 指定表名
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// LoginThrottle 按账号或 IP 统计的连续失败次数和锁定状态，键为 email:<邮箱> 或 ip:<IP>
+type LoginThrottle struct {
+	Key           string     `gorm:"column:key;type:varchar(320);primaryKey;comment:限流键(email:xxx/ip:xxx)" json:"key"`
+	Failures      int        `gorm:"column:failures;not null;default:0;comment:统计窗口内的连续失败次数" json:"failures"`
+	LastFailureAt time.Time  `gorm:"column:last_failure_at;comment:最近一次失败时间" json:"last_failure_at"`
+	LockedUntil   *time.Time `gorm:"column:locked_until;index;comment:锁定截止时间" json:"locked_until"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// MediaObject 缓存的笔记图片，按原图内容的 SHA-256 去重，文件本体保存在对象存储中
+type MediaObject struct {
+	Hash        string    `gorm:"column:hash;type:varchar(64);primaryKey;comment:原图内容SHA-256(十六进制)" json:"hash"`
+	SourceURL   string    `gorm:"column:source_url;type:text;index;comment:首次下载的来源URL" json:"source_url"`
+	ContentType string    `gorm:"column:content_type;type:varchar(100);comment:MIME类型" json:"content_type"`
+	Format      string    `gorm:"column:format;type:varchar(20);comment:图片格式(jpeg/png/webp/gif)" json:"format"`
+	Width       int       `gorm:"column:width;comment:宽度(像素)" json:"width"`
+	Height      int       `gorm:"column:height;comment:高度(像素)" json:"height"`
+	Size        int64     `gorm:"column:size;comment:原图大小(字节)" json:"size"`
+	StorageKey  string    `gorm:"column:storage_key;type:varchar(500);not null;comment:原图在对象存储中的键" json:"-"`
+	VisionKey   string    `gorm:"column:vision_key;type:varchar(500);comment:压缩图在对象存储中的键(为空表示直接使用原图)" json:"-"`
+	VisionSize  int64     `gorm:"column:vision_size;comment:压缩图大小(字节)" json:"vision_size"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+}
+
+// TableName 指定表名
+func (MediaObject) TableName() string {
+	return "media_objects"
+}
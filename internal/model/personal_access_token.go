@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// 个人访问令牌的权限范围
+const (
+	ScopeProjectsRead  = "projects:read"  // 查看项目、生成记录、音频和图片
+	ScopeProjectsWrite = "projects:write" // 创建、修改和删除项目
+	ScopeAnalyze       = "analyze"        // 爬取、分析、生成仿写和合规检查
+	ScopeBatch         = "batch"          // 创建和查看批量分析任务
+	ScopeSpeech        = "speech"         // 语音合成
+	ScopeImages        = "images"         // 图像生成
+)
+
+// AllScopes 全部可授予的权限范围
+var AllScopes = []string{ScopeProjectsRead, ScopeProjectsWrite, ScopeAnalyze, ScopeBatch, ScopeSpeech, ScopeImages}
+
+// PersonalAccessToken 个人访问令牌，供脚本和内部系统调用 API。明文只在创建时返回一次，库中只保存 SHA-256 哈希
+type PersonalAccessToken struct {
+	ID         int64                       `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	UserID     int64                       `gorm:"column:user_id;not null;index;comment:所属用户ID" json:"user_id"`
+	Name       string                      `gorm:"column:name;type:varchar(100);not null;comment:令牌名称" json:"name"`
+	Prefix     string                      `gorm:"column:prefix;type:varchar(20);not null;comment:令牌前缀(用于辨认)" json:"prefix"`
+	TokenHash  string                      `gorm:"column:token_hash;type:varchar(64);not null;uniqueIndex;comment:令牌SHA-256(十六进制)" json:"-"`
+	Scopes     datatypes.JSONSlice[string] `gorm:"column:scopes;type:jsonb;comment:权限范围" json:"scopes"`
+	LastUsedAt *time.Time                  `gorm:"column:last_used_at;comment:最后使用时间" json:"last_used_at"`
+	ExpiresAt  *time.Time                  `gorm:"column:expires_at;comment:过期时间(为空表示永不过期)" json:"expires_at"`
+	RevokedAt  *time.Time                  `gorm:"column:revoked_at;comment:吊销时间" json:"revoked_at"`
+	CreatedAt  time.Time                   `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+}
+
+// TableName 指定表名
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
+
+// HasScope 令牌是否包含指定权限范围
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidScope 是否为有效的权限范围
+func ValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
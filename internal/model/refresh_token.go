@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新令牌，只保存 SHA-256 哈希。每次刷新都会轮换：旧令牌作废并签发同一家族的新令牌，
+// 已作废的令牌被再次使用时视为泄露，整个家族一起吊销
+type RefreshToken struct {
+	ID           int64      `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	UserID       int64      `gorm:"column:user_id;not null;index;comment:用户ID" json:"user_id"`
+	TokenHash    string     `gorm:"column:token_hash;type:varchar(64);not null;uniqueIndex;comment:令牌SHA-256(十六进制)" json:"-"`
+	FamilyID     string     `gorm:"column:family_id;type:varchar(36);not null;index;comment:令牌家族ID(同一次登录轮换出的令牌共享)" json:"-"`
+	TokenVersion int        `gorm:"column:token_version;not null;default:0;comment:签发时的用户令牌版本" json:"-"`
+	UserAgent    string     `gorm:"column:user_agent;type:varchar(500);comment:签发时的客户端UA" json:"user_agent"`
+	ClientIP     string     `gorm:"column:client_ip;type:varchar(64);comment:签发时的客户端IP" json:"client_ip"`
+	ExpiresAt    time.Time  `gorm:"column:expires_at;not null;index;comment:过期时间" json:"expires_at"`
+	RevokedAt    *time.Time `gorm:"column:revoked_at;comment:作废时间(轮换或退出登录)" json:"revoked_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+}
+
+// TableName 指定表名
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
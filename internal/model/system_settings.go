@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+)
+
+// SystemSettingsID 系统设置只有一行
+const SystemSettingsID int64 = 1
+
+// SystemSettings 全局默认设置（管理员维护），用户没有自己的设置时使用
+type SystemSettings struct {
+	ID                   int64     `gorm:"column:id;primaryKey;comment:固定为1" json:"-"`
+	DefaultLLMProvider   string    `gorm:"column:default_llm_provider;type:varchar(50);not null;default:openai;comment:默认LLM服务商" json:"default_llm_provider"`
+	DefaultLLMModel      string    `gorm:"column:default_llm_model;type:varchar(100);not null;default:gpt-3.5-turbo;comment:默认文案LLM模型" json:"default_llm_model"`
+	DefaultImageLLMModel string    `gorm:"column:default_image_llm_model;type:varchar(100);not null;default:gpt-4o;comment:默认图片LLM模型" json:"default_image_llm_model"`
+	DefaultVideoLLMModel string    `gorm:"column:default_video_llm_model;type:varchar(100);not null;default:gpt-4o;comment:默认视频LLM模型" json:"default_video_llm_model"`
+	DefaultGenerateCount int       `gorm:"column:default_generate_count;not null;default:1;comment:默认一次生成的仿写条数" json:"default_generate_count"`
+	MaxGenerateCount     int       `gorm:"column:max_generate_count;not null;default:10;comment:一次生成的仿写条数上限" json:"max_generate_count"`
+	BatchConcurrency     int       `gorm:"column:batch_concurrency;not null;default:2;comment:批量任务并发处理的链接数" json:"batch_concurrency"`
+	UpdatedBy            int64     `gorm:"column:updated_by;not null;default:0;comment:最后修改的管理员ID" json:"updated_by"`
+	UpdatedAt            time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SystemSettings) TableName() string {
+	return "system_settings"
+}
+
+// DefaultSystemSettings 未保存过系统设置时的默认值（与表字段默认值一致）
+func DefaultSystemSettings() *SystemSettings {
+	return &SystemSettings{
+		ID:                   SystemSettingsID,
+		DefaultLLMProvider:   LLMProviderOpenAI,
+		DefaultLLMModel:      "gpt-3.5-turbo",
+		DefaultImageLLMModel: "gpt-4o",
+		DefaultVideoLLMModel: "gpt-4o",
+		DefaultGenerateCount: 1,
+		MaxGenerateCount:     10,
+		BatchConcurrency:     2,
+	}
+}
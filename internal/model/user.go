@@ -6,17 +6,44 @@ import (
 
 // User 用户模型
 type User struct {
-	ID        int64     `gorm:"column:id;primaryKey;autoIncrement;comment:用户唯一ID(自增)" json:"id"`
-	Email     string    `gorm:"column:email;type:varchar(255);uniqueIndex;not null;comment:用户邮箱(用于登录)" json:"email"`
-	Password  string    `gorm:"column:password;type:varchar(255);not null;comment:密码哈希" json:"-"` // json:"-" 防止密码泄露
-	Nickname  string    `gorm:"column:nickname;type:varchar(100);comment:用户昵称" json:"nickname"`
-	Avatar    string    `gorm:"column:avatar;type:varchar(500);comment:头像URL" json:"avatar"`
-	Bio       string    `gorm:"column:bio;type:text;comment:个人简介" json:"bio"`
-	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
-	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+	ID            int64     `gorm:"column:id;primaryKey;autoIncrement;comment:用户唯一ID(自增)" json:"id"`
+	Email         string    `gorm:"column:email;type:varchar(255);not null;comment:用户邮箱(用于登录，小写；唯一索引建在 lower(email) 上)" json:"email"`
+	Password      string    `gorm:"column:password;type:varchar(255);not null;comment:密码哈希" json:"-"` // json:"-" 防止密码泄露
+	Nickname      string    `gorm:"column:nickname;type:varchar(100);comment:用户昵称" json:"nickname"`
+	Avatar        string    `gorm:"column:avatar;type:varchar(500);comment:头像URL" json:"avatar"`
+	Bio           string    `gorm:"column:bio;type:text;comment:个人简介" json:"bio"`
+	EmailVerified bool      `gorm:"column:email_verified;not null;default:false;comment:邮箱是否已验证" json:"email_verified"`
+	Role          string    `gorm:"column:role;type:varchar(20);not null;default:user;comment:系统角色(user/admin)" json:"role"`
+	Disabled      bool      `gorm:"column:disabled;not null;default:false;comment:是否已被管理员禁用" json:"disabled"`
+	TokenVersion  int       `gorm:"column:token_version;not null;default:0;comment:令牌版本(修改密码或退出全部设备时递增，旧令牌随即失效)" json:"-"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
 }
 
 // TableName 指定表名
 func (User) TableName() string {
 	return "users"
 }
+
+// 系统角色（与工作区角色无关）
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
+// IsAdmin 是否为系统管理员
+func (u *User) IsAdmin() bool {
+	return u.Role == UserRoleAdmin
+}
+
+// UserUsage 用户用量统计（管理后台展示）
+type UserUsage struct {
+	Projects         int64            `json:"projects"`
+	Generations      int64            `json:"generations"`
+	Assets           int64            `json:"assets"`
+	AssetBytes       int64            `json:"asset_bytes"`
+	BatchTasks       map[string]int64 `json:"batch_tasks"` // 按状态统计的批量任务数
+	BatchItems       int64            `json:"batch_items"`
+	BatchFailedItems int64            `json:"batch_failed_items"`
+	LastLoginAt      *time.Time       `json:"last_login_at"`
+}
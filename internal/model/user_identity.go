@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// UserIdentity 外部身份（OIDC 单点登录）与本地用户的关联，按签发方和 sub 唯一确定
+type UserIdentity struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	UserID    int64     `gorm:"column:user_id;not null;index;comment:本地用户ID" json:"user_id"`
+	Issuer    string    `gorm:"column:issuer;type:varchar(255);not null;uniqueIndex:idx_identity_issuer_subject;comment:身份提供方(iss)" json:"issuer"`
+	Subject   string    `gorm:"column:subject;type:varchar(255);not null;uniqueIndex:idx_identity_issuer_subject;comment:身份提供方中的用户标识(sub)" json:"subject"`
+	Email     string    `gorm:"column:email;type:varchar(255);comment:最近一次登录时的邮箱" json:"email"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;comment:关联时间" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;comment:最近登录时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -37,10 +37,23 @@ type UserSettings struct {
 	ZhipuApiKey     string `gorm:"column:zhipu_api_key;type:varchar(500);comment:智谱 API密钥" json:"zhipu_api_key"`
 	AnthropicApiKey string `gorm:"column:anthropic_api_key;type:varchar(500);comment:Anthropic API密钥" json:"anthropic_api_key"`
 
+	// 语音合成配置：未填写 TTSApiKey 时，百炼沿用通义千问 API Key，OpenAI 沿用 OpenAI API Key
+	TTSProvider string `gorm:"column:tts_provider;type:varchar(50);default:dashscope;comment:语音合成服务商(dashscope/openai/local)" json:"tts_provider"`
+	TTSApiKey   string `gorm:"column:tts_api_key;type:varchar(500);comment:语音合成 API密钥" json:"tts_api_key"`
+	TTSBaseURL  string `gorm:"column:tts_base_url;type:varchar(500);comment:语音合成 API基础URL(OpenAI兼容接口)" json:"tts_base_url"`
+
+	// 图像生成配置：未填写 ImageGenApiKey 时，百炼沿用通义千问 API Key，OpenAI 沿用 OpenAI API Key
+	ImageGenProvider string `gorm:"column:image_gen_provider;type:varchar(50);default:dashscope;comment:图像生成服务商(dashscope/openai/local)" json:"image_gen_provider"`
+	ImageGenApiKey   string `gorm:"column:image_gen_api_key;type:varchar(500);comment:图像生成 API密钥" json:"image_gen_api_key"`
+	ImageGenBaseURL  string `gorm:"column:image_gen_base_url;type:varchar(500);comment:图像生成 API基础URL(OpenAI兼容接口)" json:"image_gen_base_url"`
+
 	// 仿写生成配置
 	DefaultTaskType string `gorm:"column:default_task_type;type:varchar(50);default:contentAnalysis;comment:默认选中的任务类型" json:"default_task_type"`
 	GenerateCount   int    `gorm:"column:generate_count;default:1;comment:一次生成的仿写条数(1-10)" json:"generate_count"`
 
+	// 原创度配置：生成内容与原文的相似度超过阈值时自动重新生成
+	OriginalityThreshold float64 `gorm:"column:originality_threshold;default:0.5;comment:原创度检查相似度阈值(0-1,0表示关闭)" json:"originality_threshold"`
+
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
 	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
 
@@ -64,3 +77,6 @@ const (
 	LLMProviderDoubao    = "doubao"
 	LLMProviderZhipu     = "zhipu"
 )
+
+// DefaultOriginalityThreshold 默认原创度阈值
+const DefaultOriginalityThreshold = 0.5
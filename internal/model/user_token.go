@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// 一次性令牌用途
+const (
+	UserTokenEmailVerify   = "email_verify"   // 邮箱验证
+	UserTokenPasswordReset = "password_reset" // 重置密码
+)
+
+// UserToken 邮件中发送的一次性令牌（邮箱验证、重置密码），只保存 SHA-256 哈希，使用后或过期即失效
+type UserToken struct {
+	ID        int64      `gorm:"column:id;primaryKey;autoIncrement;comment:主键ID" json:"id"`
+	UserID    int64      `gorm:"column:user_id;not null;index;comment:用户ID" json:"user_id"`
+	Purpose   string     `gorm:"column:purpose;type:varchar(30);not null;comment:用途(email_verify/password_reset)" json:"purpose"`
+	Email     string     `gorm:"column:email;type:varchar(255);not null;comment:签发时的邮箱(邮箱变更后旧令牌失效)" json:"email"`
+	TokenHash string     `gorm:"column:token_hash;type:varchar(64);not null;uniqueIndex;comment:令牌SHA-256(十六进制)" json:"-"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;not null;comment:过期时间" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at;comment:使用时间(为空表示未使用)" json:"used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+}
+
+// TableName 指定表名
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
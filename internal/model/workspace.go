@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+)
+
+// Workspace 工作区（团队）模型，项目和批量任务归属于工作区
+type Workspace struct {
+	ID        int64     `gorm:"column:id;primaryKey;autoIncrement;comment:工作区ID(自增)" json:"id"`
+	Name      string    `gorm:"column:name;type:varchar(100);not null;comment:工作区名称" json:"name"`
+	OwnerID   int64     `gorm:"column:owner_id;not null;index;comment:创建者用户ID" json:"owner_id"`
+	Personal  bool      `gorm:"column:personal;default:false;comment:是否为个人工作区(注册时自动创建,不可删除)" json:"personal"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Workspace) TableName() string {
+	return "workspaces"
+}
+
+// WorkspaceMember 工作区成员
+type WorkspaceMember struct {
+	WorkspaceID int64     `gorm:"column:workspace_id;primaryKey;comment:工作区ID" json:"workspace_id"`
+	UserID      int64     `gorm:"column:user_id;primaryKey;index;comment:成员用户ID" json:"user_id"`
+	Role        string    `gorm:"column:role;type:varchar(20);not null;default:viewer;comment:角色(owner/editor/viewer)" json:"role"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime;comment:加入时间" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+
+	// 关联关系（仅用于代码层面加载，不创建数据库外键）
+	User *User `gorm:"-" json:"user,omitempty"`
+}
+
+// TableName 指定表名
+func (WorkspaceMember) TableName() string {
+	return "workspace_members"
+}
+
+// 工作区角色
+const (
+	WorkspaceRoleOwner  = "owner"  // 所有者：管理成员、共享密钥和工作区
+	WorkspaceRoleEditor = "editor" // 编辑者：创建、分析、生成和删除项目
+	WorkspaceRoleViewer = "viewer" // 查看者：只读
+)
+
+// WorkspaceSettings 工作区共享的服务商 API Key，成员在该工作区内调用模型时优先使用
+// （服务商和模型仍按成员自己的设置选择）
+type WorkspaceSettings struct {
+	ID          int64 `gorm:"column:id;primaryKey;autoIncrement;comment:设置ID(自增)" json:"id"`
+	WorkspaceID int64 `gorm:"column:workspace_id;uniqueIndex;not null;comment:关联工作区ID" json:"workspace_id"`
+
+	OpenAIApiKey    string `gorm:"column:openai_api_key;type:varchar(500);comment:OpenAI API密钥" json:"openai_api_key"`
+	DeepSeekApiKey  string `gorm:"column:deepseek_api_key;type:varchar(500);comment:DeepSeek API密钥" json:"deepseek_api_key"`
+	MoonshotApiKey  string `gorm:"column:moonshot_api_key;type:varchar(500);comment:Moonshot API密钥" json:"moonshot_api_key"`
+	QwenApiKey      string `gorm:"column:qwen_api_key;type:varchar(500);comment:通义千问 API密钥" json:"qwen_api_key"`
+	HunyuanApiKey   string `gorm:"column:hunyuan_api_key;type:varchar(500);comment:腾讯混元 API密钥" json:"hunyuan_api_key"`
+	DoubaoApiKey    string `gorm:"column:doubao_api_key;type:varchar(500);comment:豆包 API密钥" json:"doubao_api_key"`
+	ZhipuApiKey     string `gorm:"column:zhipu_api_key;type:varchar(500);comment:智谱 API密钥" json:"zhipu_api_key"`
+	AnthropicApiKey string `gorm:"column:anthropic_api_key;type:varchar(500);comment:Anthropic API密钥" json:"anthropic_api_key"`
+	TTSApiKey       string `gorm:"column:tts_api_key;type:varchar(500);comment:语音合成 API密钥" json:"tts_api_key"`
+	ImageGenApiKey  string `gorm:"column:image_gen_api_key;type:varchar(500);comment:图像生成 API密钥" json:"image_gen_api_key"`
+
+	UpdatedBy int64     `gorm:"column:updated_by;comment:最后修改人用户ID" json:"updated_by"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime;comment:创建时间" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime;comment:更新时间" json:"updated_at"`
+}
+
+// TableName 指定表名
+func (WorkspaceSettings) TableName() string {
+	return "workspace_settings"
+}
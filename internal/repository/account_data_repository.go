@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// AccountDataRepository 账号数据仓库接口（跨表读取和删除用户的全部数据，用于数据导出和注销账号）
+type AccountDataRepository interface {
+	ListProjects(ctx context.Context, userID int64) ([]*model.Project, error)
+	ListGenerations(ctx context.Context, userID int64) ([]*model.Generation, error)
+	ListAssets(ctx context.Context, userID int64) ([]*model.Asset, error)
+	ListBatchTasks(ctx context.Context, userID int64) ([]*model.BatchTask, error)
+	ListStorageKeys(ctx context.Context, userID int64, workspaceIDs []int64) ([]string, error)
+	DeleteUser(ctx context.Context, userID int64, workspaceIDs []int64) error
+}
+
+// accountDataRepository 账号数据仓库实现
+type accountDataRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountDataRepository 创建账号数据仓库实例
+func NewAccountDataRepository(db *gorm.DB) AccountDataRepository {
+	return &accountDataRepository{db: db}
+}
+
+// ListProjects 获取用户创建的项目
+func (r *accountDataRepository) ListProjects(ctx context.Context, userID int64) ([]*model.Project, error) {
+	var projects []*model.Project
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to list user projects: %w", err)
+	}
+	return projects, nil
+}
+
+// ListGenerations 获取用户的生成记录
+func (r *accountDataRepository) ListGenerations(ctx context.Context, userID int64) ([]*model.Generation, error) {
+	var generations []*model.Generation
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&generations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list user generations: %w", err)
+	}
+	return generations, nil
+}
+
+// ListAssets 获取用户的资源文件记录
+func (r *accountDataRepository) ListAssets(ctx context.Context, userID int64) ([]*model.Asset, error) {
+	var assets []*model.Asset
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to list user assets: %w", err)
+	}
+	return assets, nil
+}
+
+// ListBatchTasks 获取用户创建的批量任务
+func (r *accountDataRepository) ListBatchTasks(ctx context.Context, userID int64) ([]*model.BatchTask, error) {
+	var tasks []*model.BatchTask
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list user batch tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// ListStorageKeys 获取注销账号时需要从对象存储删除的文件：用户及待删除项目的资源文件（转交给其他所有者的项目中的除外）、导出的 zip
+func (r *accountDataRepository) ListStorageKeys(ctx context.Context, userID int64, workspaceIDs []int64) ([]string, error) {
+	db := r.db.WithContext(ctx)
+
+	var keys []string
+	if err := db.Model(&model.Asset{}).
+		Where("project_id IS NULL OR project_id NOT IN (?)", r.retainedProjectIDs(db, userID, workspaceIDs)).
+		Where("user_id = ? OR project_id IN (?)", userID, r.projectIDs(db, userID, workspaceIDs)).
+		Pluck("storage_key", &keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list asset storage keys: %w", err)
+	}
+
+	var exports []string
+	if err := db.Model(&model.ExportJob{}).
+		Where("user_id = ? AND storage_key <> ''", userID).
+		Pluck("storage_key", &exports).Error; err != nil {
+		return nil, fmt.Errorf("failed to list export storage keys: %w", err)
+	}
+	return append(keys, exports...), nil
+}
+
+// DeleteUser 在同一事务中删除用户及其项目、生成记录、资源记录、批量任务、设置、令牌、登录身份、导出任务，
+// 以及 workspaceIDs 指定的工作区（含其中其他成员创建的内容）；审计日志和登录记录保留。
+// 用户在保留的团队工作区中创建的项目和批量任务（及这些项目下用户的生成记录和资源）转交给该工作区的其他所有者，不删除
+func (r *accountDataRepository) DeleteUser(ctx context.Context, userID int64, workspaceIDs []int64) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := r.reassign(tx, userID, workspaceIDs); err != nil {
+			return err
+		}
+
+		projectIDs := r.projectIDs(tx, userID, workspaceIDs)
+		steps := []struct {
+			model interface{}
+			query string
+			args  []interface{}
+		}{
+			{&model.Generation{}, "user_id = ? OR project_id IN (?)", []interface{}{userID, projectIDs}},
+			{&model.Asset{}, "user_id = ? OR project_id IN (?)", []interface{}{userID, projectIDs}},
+			{&model.Project{}, "user_id = ? OR workspace_id IN ?", []interface{}{userID, workspaceIDs}},
+			{&model.BatchTask{}, "user_id = ? OR workspace_id IN ?", []interface{}{userID, workspaceIDs}},
+			{&model.WorkspaceSettings{}, "workspace_id IN ?", []interface{}{workspaceIDs}},
+			{&model.WorkspaceMember{}, "user_id = ? OR workspace_id IN ?", []interface{}{userID, workspaceIDs}},
+			{&model.Workspace{}, "id IN ?", []interface{}{workspaceIDs}},
+			{&model.UserSettings{}, "user_id = ?", []interface{}{userID}},
+			{&model.RefreshToken{}, "user_id = ?", []interface{}{userID}},
+			{&model.PersonalAccessToken{}, "user_id = ?", []interface{}{userID}},
+			{&model.UserToken{}, "user_id = ?", []interface{}{userID}},
+			{&model.UserIdentity{}, "user_id = ?", []interface{}{userID}},
+			{&model.ExportJob{}, "user_id = ?", []interface{}{userID}},
+			{&model.User{}, "id = ?", []interface{}{userID}},
+		}
+		for _, step := range steps {
+			if err := tx.Where(step.query, step.args...).Delete(step.model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user data: %w", err)
+	}
+	return nil
+}
+
+// newOwnerSQL 工作区中除注销用户外最早加入的所有者，%s 为工作区ID所在的列
+const newOwnerSQL = `(SELECT m.user_id FROM workspace_members m
+	WHERE m.workspace_id = %s AND m.user_id <> @user AND m.role = @owner
+	ORDER BY m.created_at, m.user_id LIMIT 1)`
+
+// reassign 把保留的团队工作区中属于注销用户的内容转交给工作区的其他所有者
+func (r *accountDataRepository) reassign(tx *gorm.DB, userID int64, workspaceIDs []int64) error {
+	args := map[string]interface{}{
+		"user":     userID,
+		"owner":    model.WorkspaceRoleOwner,
+		"retained": r.retainedWorkspaceIDs(tx, userID, workspaceIDs),
+	}
+	statements := []string{
+		`UPDATE workspaces SET owner_id = ` + fmt.Sprintf(newOwnerSQL, "workspaces.id") +
+			` WHERE owner_id = @user AND id IN (@retained)`,
+		`UPDATE projects SET user_id = ` + fmt.Sprintf(newOwnerSQL, "projects.workspace_id") +
+			` WHERE user_id = @user AND workspace_id IN (@retained)`,
+		`UPDATE batch_tasks SET user_id = ` + fmt.Sprintf(newOwnerSQL, "batch_tasks.workspace_id") +
+			` WHERE user_id = @user AND workspace_id IN (@retained)`,
+		// 保留项目下用户的生成记录和资源归项目创建者
+		`UPDATE generations SET user_id = p.user_id FROM projects p
+			WHERE generations.project_id = p.id AND generations.user_id = @user AND p.workspace_id IN (@retained)`,
+		`UPDATE assets SET user_id = p.user_id FROM projects p
+			WHERE assets.project_id = p.id AND assets.user_id = @user AND p.workspace_id IN (@retained)`,
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement, args).Error; err != nil {
+			return fmt.Errorf("failed to reassign shared content: %w", err)
+		}
+	}
+	return nil
+}
+
+// projectIDs 注销时删除的项目ID子查询：用户创建的以及待删除工作区内的项目，保留的团队工作区中的项目除外
+func (r *accountDataRepository) projectIDs(db *gorm.DB, userID int64, workspaceIDs []int64) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&model.Project{}).
+		Select("id").
+		Where("user_id = ? OR workspace_id IN ?", userID, workspaceIDs).
+		Where("workspace_id NOT IN (?)", r.retainedWorkspaceIDs(db, userID, workspaceIDs))
+}
+
+// retainedProjectIDs 保留的团队工作区中的项目ID子查询
+func (r *accountDataRepository) retainedProjectIDs(db *gorm.DB, userID int64, workspaceIDs []int64) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&model.Project{}).
+		Select("id").
+		Where("workspace_id IN (?)", r.retainedWorkspaceIDs(db, userID, workspaceIDs))
+}
+
+// retainedWorkspaceIDs 注销后保留的工作区ID子查询：不在待删除列表中且有其他所有者的工作区
+func (r *accountDataRepository) retainedWorkspaceIDs(db *gorm.DB, userID int64, workspaceIDs []int64) *gorm.DB {
+	query := db.Session(&gorm.Session{NewDB: true}).Model(&model.WorkspaceMember{}).
+		Select("workspace_id").
+		Where("user_id <> ? AND role = ?", userID, model.WorkspaceRoleOwner)
+	if len(workspaceIDs) > 0 {
+		query = query.Where("workspace_id NOT IN ?", workspaceIDs)
+	}
+	return query
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"copycat/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AssetRepository 资源文件数据仓库接口
+type AssetRepository interface {
+	Create(ctx context.Context, asset *model.Asset) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Asset, error)
+	ListByProjectID(ctx context.Context, projectID uuid.UUID, kind string) ([]*model.Asset, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// assetRepository 资源文件数据仓库实现
+type assetRepository struct {
+	db *gorm.DB
+}
+
+// NewAssetRepository 创建资源文件仓库实例
+func NewAssetRepository(db *gorm.DB) AssetRepository {
+	return &assetRepository{db: db}
+}
+
+// Create 创建资源记录
+func (r *assetRepository) Create(ctx context.Context, asset *model.Asset) error {
+	if asset.ID == uuid.Nil {
+		asset.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(asset).Error; err != nil {
+		return fmt.Errorf("failed to create asset: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取资源记录
+func (r *assetRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Asset, error) {
+	var asset model.Asset
+	if err := r.db.WithContext(ctx).First(&asset, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get asset by id: %w", err)
+	}
+	return &asset, nil
+}
+
+// ListByProjectID 获取项目下的资源（kind 为空时返回全部类型），按创建时间倒序
+func (r *assetRepository) ListByProjectID(ctx context.Context, projectID uuid.UUID, kind string) ([]*model.Asset, error) {
+	var assets []*model.Asset
+	query := r.db.WithContext(ctx).Where("project_id = ?", projectID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if err := query.Order("created_at DESC").Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to list assets by project id: %w", err)
+	}
+	return assets, nil
+}
+
+// Delete 删除资源记录
+func (r *assetRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Delete(&model.Asset{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete asset: %w", err)
+	}
+	return nil
+}
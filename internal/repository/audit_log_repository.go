@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter 审计日志查询条件（零值表示不筛选）
+type AuditLogFilter struct {
+	ActorID     int64
+	WorkspaceID int64
+	Action      string
+	TargetType  string
+	TargetID    string
+	From        *time.Time
+	To          *time.Time
+}
+
+// AuditLogRepository 审计日志数据仓库接口（只追加，不提供修改和删除）
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *model.AuditLog) error
+	List(ctx context.Context, filter AuditLogFilter, page, pageSize int) ([]*model.AuditLog, int64, error)
+}
+
+// auditLogRepository 审计日志数据仓库实现
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository 创建审计日志仓库实例
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// Create 追加审计记录
+func (r *auditLogRepository) Create(ctx context.Context, log *model.AuditLog) error {
+	if err := r.db.WithContext(ctx).Create(log).Error; err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// List 按条件分页查询审计记录（按时间倒序）
+func (r *auditLogRepository) List(ctx context.Context, filter AuditLogFilter, page, pageSize int) ([]*model.AuditLog, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.AuditLog{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.WorkspaceID != 0 {
+		query = query.Where("workspace_id = ?", filter.WorkspaceID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	var logs []*model.AuditLog
+	if err := query.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	return logs, total, nil
+}
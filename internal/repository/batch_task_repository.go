@@ -48,17 +48,37 @@ func (r *BatchTaskRepository) FindByIDWithProjects(id uuid.UUID) (*model.BatchTa
 	return &task, nil
 }
 
-// FindByUserID 根据用户ID查询批量任务列表
-func (r *BatchTaskRepository) FindByUserID(userID int64, limit, offset int) ([]model.BatchTask, int64, error) {
+// FindByWorkspaceID 根据工作区ID查询批量任务列表
+func (r *BatchTaskRepository) FindByWorkspaceID(workspaceID int64, limit, offset int) ([]model.BatchTask, int64, error) {
 	var tasks []model.BatchTask
 	var total int64
 
 	// 统计总数
-	if err := r.db.Model(&model.BatchTask{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := r.db.Model(&model.BatchTask{}).Where("workspace_id = ?", workspaceID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 查询列表
+	if err := r.db.Where("workspace_id = ?", workspaceID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&tasks).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
+// FindByUserID 根据创建者查询批量任务列表（跨工作区，管理后台使用）
+func (r *BatchTaskRepository) FindByUserID(userID int64, limit, offset int) ([]model.BatchTask, int64, error) {
+	var tasks []model.BatchTask
+	var total int64
+
+	if err := r.db.Model(&model.BatchTask{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	if err := r.db.Where("user_id = ?", userID).
 		Order("created_at DESC").
 		Limit(limit).
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"copycat/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExportJobRepository 数据导出任务仓库接口
+type ExportJobRepository interface {
+	Create(ctx context.Context, job *model.ExportJob) error
+	GetLatestByUserID(ctx context.Context, userID int64) (*model.ExportJob, error)
+	Update(ctx context.Context, job *model.ExportJob) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// exportJobRepository 数据导出任务仓库实现
+type exportJobRepository struct {
+	db *gorm.DB
+}
+
+// NewExportJobRepository 创建数据导出任务仓库实例
+func NewExportJobRepository(db *gorm.DB) ExportJobRepository {
+	return &exportJobRepository{db: db}
+}
+
+// Create 创建导出任务
+func (r *exportJobRepository) Create(ctx context.Context, job *model.ExportJob) error {
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+	return nil
+}
+
+// GetLatestByUserID 获取用户最近一次导出任务
+func (r *exportJobRepository) GetLatestByUserID(ctx context.Context, userID int64) (*model.ExportJob, error) {
+	var job model.ExportJob
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").First(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to get latest export job: %w", err)
+	}
+	return &job, nil
+}
+
+// Update 更新导出任务
+func (r *exportJobRepository) Update(ctx context.Context, job *model.ExportJob) error {
+	if err := r.db.WithContext(ctx).Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除导出任务记录
+func (r *exportJobRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Delete(&model.ExportJob{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete export job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"copycat/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GenerationRepository 生成记录数据仓库接口
+type GenerationRepository interface {
+	Create(ctx context.Context, generation *model.Generation) error
+	CreateBatch(ctx context.Context, generations []*model.Generation) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Generation, error)
+	ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]*model.Generation, error)
+}
+
+// generationRepository 生成记录数据仓库实现
+type generationRepository struct {
+	db *gorm.DB
+}
+
+// NewGenerationRepository 创建生成记录仓库实例
+func NewGenerationRepository(db *gorm.DB) GenerationRepository {
+	return &generationRepository{db: db}
+}
+
+// Create 创建生成记录
+func (r *generationRepository) Create(ctx context.Context, generation *model.Generation) error {
+	if generation.ID == uuid.Nil {
+		generation.ID = uuid.New()
+	}
+	if err := r.db.WithContext(ctx).Create(generation).Error; err != nil {
+		return fmt.Errorf("failed to create generation: %w", err)
+	}
+	return nil
+}
+
+// CreateBatch 批量创建生成记录
+func (r *generationRepository) CreateBatch(ctx context.Context, generations []*model.Generation) error {
+	if len(generations) == 0 {
+		return nil
+	}
+	for _, g := range generations {
+		if g.ID == uuid.Nil {
+			g.ID = uuid.New()
+		}
+	}
+	if err := r.db.WithContext(ctx).Create(&generations).Error; err != nil {
+		return fmt.Errorf("failed to create generations: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取生成记录
+func (r *generationRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Generation, error) {
+	var generation model.Generation
+	if err := r.db.WithContext(ctx).First(&generation, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get generation by id: %w", err)
+	}
+	return &generation, nil
+}
+
+// ListByProjectID 获取项目下的全部生成记录（按序号、创建时间排序）
+func (r *generationRepository) ListByProjectID(ctx context.Context, projectID uuid.UUID) ([]*model.Generation, error) {
+	var generations []*model.Generation
+	if err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("variant_index ASC, created_at ASC").
+		Find(&generations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list generations by project id: %w", err)
+	}
+	return generations, nil
+}
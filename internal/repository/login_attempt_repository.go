@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptRepository 登录记录和登录限流数据仓库接口
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *model.LoginAttempt) error
+	List(ctx context.Context, email, ip string, limit int) ([]*model.LoginAttempt, error)
+
+	LockThrottles(ctx context.Context, keys []string, fn func(throttles []*model.LoginThrottle) (bool, error)) error
+	RefundThrottle(ctx context.Context, key string, maxFailures int) error
+	DeleteThrottle(ctx context.Context, key string) (bool, error)
+	ListLocked(ctx context.Context) ([]*model.LoginThrottle, error)
+}
+
+// loginAttemptRepository 登录记录数据仓库实现
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptRepository 创建登录记录仓库实例
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+// Create 保存登录记录
+func (r *loginAttemptRepository) Create(ctx context.Context, attempt *model.LoginAttempt) error {
+	if err := r.db.WithContext(ctx).Create(attempt).Error; err != nil {
+		return fmt.Errorf("failed to create login attempt: %w", err)
+	}
+	return nil
+}
+
+// List 按邮箱和 IP 筛选最近的登录记录（参数为空表示不筛选）
+func (r *loginAttemptRepository) List(ctx context.Context, email, ip string, limit int) ([]*model.LoginAttempt, error) {
+	query := r.db.WithContext(ctx).Model(&model.LoginAttempt{})
+	if email != "" {
+		query = query.Where("email = ?", email)
+	}
+	if ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	var attempts []*model.LoginAttempt
+	if err := query.Order("created_at DESC").Limit(limit).Find(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list login attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+// LockThrottles 在事务中锁定各键的限流记录（不存在时创建空记录）后调用 fn，fn 返回 true 时保存修改。
+// 同一键的并发调用串行执行，throttles 与 keys 顺序一致
+func (r *loginAttemptRepository) LockThrottles(ctx context.Context, keys []string, fn func(throttles []*model.LoginThrottle) (bool, error)) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 按键排序加锁，避免并发事务交叉等待
+		sorted := append([]string(nil), keys...)
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.LoginThrottle{Key: key}).Error; err != nil {
+				return fmt.Errorf("failed to create login throttle: %w", err)
+			}
+		}
+		var locked []*model.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key IN ?", sorted).
+			Order("key").
+			Find(&locked).Error; err != nil {
+			return fmt.Errorf("failed to lock login throttles: %w", err)
+		}
+		byKey := make(map[string]*model.LoginThrottle, len(locked))
+		for _, t := range locked {
+			byKey[t.Key] = t
+		}
+		throttles := make([]*model.LoginThrottle, len(keys))
+		for i, key := range keys {
+			if throttles[i] = byKey[key]; throttles[i] == nil {
+				return fmt.Errorf("login throttle %s not found", key)
+			}
+		}
+
+		save, err := fn(throttles)
+		if err != nil || !save {
+			return err
+		}
+		for _, t := range throttles {
+			if err := tx.Save(t).Error; err != nil {
+				return fmt.Errorf("failed to save login throttle: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// RefundThrottle 退还一次预先计入的失败次数，退还后低于 maxFailures 时解除该次计数造成的锁定
+func (r *loginAttemptRepository) RefundThrottle(ctx context.Context, key string, maxFailures int) error {
+	err := r.db.WithContext(ctx).Model(&model.LoginThrottle{}).
+		Where("key = ? AND failures > 0", key).
+		Updates(map[string]interface{}{
+			"failures":     gorm.Expr("failures - 1"),
+			"locked_until": gorm.Expr("CASE WHEN failures - 1 < ? THEN NULL ELSE locked_until END", maxFailures),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to refund login throttle: %w", err)
+	}
+	return nil
+}
+
+// DeleteThrottle 清除限流状态（登录成功或管理员解锁），记录不存在时返回 false
+func (r *loginAttemptRepository) DeleteThrottle(ctx context.Context, key string) (bool, error) {
+	result := r.db.WithContext(ctx).Delete(&model.LoginThrottle{}, "key = ?", key)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete login throttle: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListLocked 获取当前处于锁定状态的账号和 IP
+func (r *loginAttemptRepository) ListLocked(ctx context.Context) ([]*model.LoginThrottle, error) {
+	var throttles []*model.LoginThrottle
+	if err := r.db.WithContext(ctx).
+		Where("locked_until > ?", time.Now()).
+		Order("locked_until DESC").
+		Find(&throttles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list locked login throttles: %w", err)
+	}
+	return throttles, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MediaObjectRepository 媒体缓存数据仓库接口
+type MediaObjectRepository interface {
+	Create(ctx context.Context, obj *model.MediaObject) error
+	GetByHash(ctx context.Context, hash string) (*model.MediaObject, error)
+	GetBySourceURL(ctx context.Context, url string) (*model.MediaObject, error)
+	UpdateVision(ctx context.Context, hash, visionKey string, visionSize int64) error
+}
+
+// mediaObjectRepository 媒体缓存数据仓库实现
+type mediaObjectRepository struct {
+	db *gorm.DB
+}
+
+// NewMediaObjectRepository 创建媒体缓存仓库实例
+func NewMediaObjectRepository(db *gorm.DB) MediaObjectRepository {
+	return &mediaObjectRepository{db: db}
+}
+
+// Create 创建缓存记录，相同哈希已存在时忽略
+func (r *mediaObjectRepository) Create(ctx context.Context, obj *model.MediaObject) error {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(obj).Error; err != nil {
+		return fmt.Errorf("failed to create media object: %w", err)
+	}
+	return nil
+}
+
+// GetByHash 根据内容哈希获取缓存记录
+func (r *mediaObjectRepository) GetByHash(ctx context.Context, hash string) (*model.MediaObject, error) {
+	var obj model.MediaObject
+	if err := r.db.WithContext(ctx).First(&obj, "hash = ?", hash).Error; err != nil {
+		return nil, fmt.Errorf("failed to get media object by hash: %w", err)
+	}
+	return &obj, nil
+}
+
+// GetBySourceURL 根据来源 URL 获取缓存记录
+func (r *mediaObjectRepository) GetBySourceURL(ctx context.Context, url string) (*model.MediaObject, error) {
+	var obj model.MediaObject
+	if err := r.db.WithContext(ctx).Where("source_url = ?", url).Order("created_at DESC").First(&obj).Error; err != nil {
+		return nil, fmt.Errorf("failed to get media object by source url: %w", err)
+	}
+	return &obj, nil
+}
+
+// UpdateVision 记录压缩图
+func (r *mediaObjectRepository) UpdateVision(ctx context.Context, hash, visionKey string, visionSize int64) error {
+	err := r.db.WithContext(ctx).Model(&model.MediaObject{}).Where("hash = ?", hash).
+		Updates(map[string]interface{}{"vision_key": visionKey, "vision_size": visionSize}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update media object: %w", err)
+	}
+	return nil
+}
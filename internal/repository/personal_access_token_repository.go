@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// PersonalAccessTokenRepository 个人访问令牌数据仓库接口
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, token *model.PersonalAccessToken) error
+	GetByHash(ctx context.Context, hash string) (*model.PersonalAccessToken, error)
+	ListByUserID(ctx context.Context, userID int64) ([]*model.PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID, id int64) (bool, error)
+	TouchLastUsed(ctx context.Context, id int64, at time.Time) error
+}
+
+// personalAccessTokenRepository 个人访问令牌数据仓库实现
+type personalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewPersonalAccessTokenRepository 创建个人访问令牌仓库实例
+func NewPersonalAccessTokenRepository(db *gorm.DB) PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{db: db}
+}
+
+// Create 保存个人访问令牌
+func (r *personalAccessTokenRepository) Create(ctx context.Context, token *model.PersonalAccessToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create personal access token: %w", err)
+	}
+	return nil
+}
+
+// GetByHash 根据令牌哈希获取个人访问令牌
+func (r *personalAccessTokenRepository) GetByHash(ctx context.Context, hash string) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("failed to get personal access token: %w", err)
+	}
+	return &token, nil
+}
+
+// ListByUserID 获取用户的个人访问令牌（包含已吊销的）
+func (r *personalAccessTokenRepository) ListByUserID(ctx context.Context, userID int64) ([]*model.PersonalAccessToken, error) {
+	var tokens []*model.PersonalAccessToken
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to list personal access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke 吊销用户的个人访问令牌，令牌不存在或已吊销时返回 false
+func (r *personalAccessTokenRepository) Revoke(ctx context.Context, userID, id int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to revoke personal access token: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// TouchLastUsed 更新最后使用时间
+func (r *personalAccessTokenRepository) TouchLastUsed(ctx context.Context, id int64, at time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&model.PersonalAccessToken{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error; err != nil {
+		return fmt.Errorf("failed to update personal access token last used: %w", err)
+	}
+	return nil
+}
@@ -14,11 +14,14 @@ import (
 type ProjectRepository interface {
 	Create(ctx context.Context, project *model.Project) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.Project, error)
-	GetByUserID(ctx context.Context, userID int64, page, pageSize int) ([]*model.Project, int64, error)
-	GetBySourceURL(ctx context.Context, userID int64, sourceURL string) (*model.Project, error)
+	GetByWorkspaceID(ctx context.Context, workspaceID int64, page, pageSize int) ([]*model.Project, int64, error)
+	GetBySourceURL(ctx context.Context, workspaceID int64, sourceURL string) (*model.Project, error)
 	Update(ctx context.Context, project *model.Project) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+	ListRecentByWorkspaceID(ctx context.Context, workspaceID int64, excludeID uuid.UUID, limit int) ([]*model.Project, error)
+	CountByWorkspaceID(ctx context.Context, workspaceID int64) (int64, error)
+	ListBatchFailures(ctx context.Context, userID int64, page, pageSize int) ([]*model.Project, int64, error)
 }
 
 // projectRepository 项目数据仓库实现
@@ -51,25 +54,25 @@ func (r *projectRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.P
 	return &project, nil
 }
 
-// GetByUserID 根据用户 ID 获取项目列表 (分页)
-func (r *projectRepository) GetByUserID(ctx context.Context, userID int64, page, pageSize int) ([]*model.Project, int64, error) {
+// GetByWorkspaceID 根据工作区 ID 获取项目列表 (分页)
+func (r *projectRepository) GetByWorkspaceID(ctx context.Context, workspaceID int64, page, pageSize int) ([]*model.Project, int64, error) {
 	var projects []*model.Project
 	var total int64
 
 	// 查询总数
-	if err := r.db.WithContext(ctx).Model(&model.Project{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&model.Project{}).Where("workspace_id = ?", workspaceID).Count(&total).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to count projects: %w", err)
 	}
 
 	// 分页查询
 	offset := (page - 1) * pageSize
 	if err := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
+		Where("workspace_id = ?", workspaceID).
 		Order("created_at DESC").
 		Offset(offset).
 		Limit(pageSize).
 		Find(&projects).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to get projects by user id: %w", err)
+		return nil, 0, fmt.Errorf("failed to get projects by workspace id: %w", err)
 	}
 
 	return projects, total, nil
@@ -83,9 +86,18 @@ func (r *projectRepository) Update(ctx context.Context, project *model.Project)
 	return nil
 }
 
-// Delete 删除项目
+// Delete 删除项目及其生成记录（含精修版本）和资源记录，资源文件由调用方从对象存储中删除
 func (r *projectRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	if err := r.db.WithContext(ctx).Delete(&model.Project{}, "id = ?", id).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.Asset{}, "project_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.Generation{}, "project_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Project{}, "id = ?", id).Error
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete project: %w", err)
 	}
 	return nil
@@ -99,15 +111,62 @@ func (r *projectRepository) UpdateStatus(ctx context.Context, id uuid.UUID, stat
 	return nil
 }
 
-// GetBySourceURL 根据用户ID和来源URL获取项目（查找已有的分析结果）
-func (r *projectRepository) GetBySourceURL(ctx context.Context, userID int64, sourceURL string) (*model.Project, error) {
+// GetBySourceURL 根据工作区ID和来源URL获取项目（查找已有的分析结果）
+func (r *projectRepository) GetBySourceURL(ctx context.Context, workspaceID int64, sourceURL string) (*model.Project, error) {
 	var project model.Project
-	// 查找用户已分析的同链接项目（有分析结果的优先）
+	// 查找工作区内已分析的同链接项目（有分析结果的优先）
 	if err := r.db.WithContext(ctx).
-		Where("user_id = ? AND source_url = ? AND analysis_result IS NOT NULL", userID, sourceURL).
+		Where("workspace_id = ? AND source_url = ? AND analysis_result IS NOT NULL", workspaceID, sourceURL).
 		Order("created_at DESC").
 		First(&project).Error; err != nil {
 		return nil, err
 	}
 	return &project, nil
 }
+
+// ListRecentByWorkspaceID 获取工作区最近的其他项目（用于原创度比对）
+func (r *projectRepository) ListRecentByWorkspaceID(ctx context.Context, workspaceID int64, excludeID uuid.UUID, limit int) ([]*model.Project, error) {
+	var projects []*model.Project
+	if err := r.db.WithContext(ctx).
+		Select("id", "source_content", "generated_content").
+		Where("workspace_id = ? AND id <> ?", workspaceID, excludeID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to list recent projects: %w", err)
+	}
+	return projects, nil
+}
+
+// CountByWorkspaceID 统计工作区的项目数
+func (r *projectRepository) CountByWorkspaceID(ctx context.Context, workspaceID int64) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Project{}).Where("workspace_id = ?", workspaceID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count projects: %w", err)
+	}
+	return count, nil
+}
+
+// ListBatchFailures 分页查询批量任务中处理失败的项目，userID 为 0 时查询所有用户
+func (r *projectRepository) ListBatchFailures(ctx context.Context, userID int64, page, pageSize int) ([]*model.Project, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.Project{}).
+		Where("batch_task_id IS NOT NULL").
+		Where("status = ? OR batch_error <> ''", model.ProjectStatusFailed)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count batch failures: %w", err)
+	}
+
+	var projects []*model.Project
+	if err := query.Order("updated_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&projects).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list batch failures: %w", err)
+	}
+	return projects, total, nil
+}
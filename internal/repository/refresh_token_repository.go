@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ErrRefreshTokenUsed 轮换时旧令牌已被作废（并发刷新或令牌重放）
+var ErrRefreshTokenUsed = errors.New("refresh token already used")
+
+// RefreshTokenRepository 刷新令牌数据仓库接口
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *model.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error)
+	Rotate(ctx context.Context, old *model.RefreshToken, next *model.RefreshToken) error
+	Revoke(ctx context.Context, id int64) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllByUserID(ctx context.Context, userID int64) error
+	DeleteExpired(ctx context.Context, userID int64) error
+}
+
+// refreshTokenRepository 刷新令牌数据仓库实现
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository 创建刷新令牌仓库实例
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Create 保存刷新令牌
+func (r *refreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
+
+// GetByHash 根据令牌哈希获取刷新令牌
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// Rotate 作废旧令牌并保存新令牌；旧令牌已被作废时返回 ErrRefreshTokenUsed
+func (r *refreshTokenRepository) Rotate(ctx context.Context, old *model.RefreshToken, next *model.RefreshToken) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenUsed
+		}
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	return nil
+}
+
+// Revoke 作废单个刷新令牌
+func (r *refreshTokenRepository) Revoke(ctx context.Context, id int64) error {
+	if err := r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	return nil
+}
+
+// RevokeFamily 作废同一家族的全部刷新令牌
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	if err := r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllByUserID 作废用户的全部刷新令牌
+func (r *refreshTokenRepository) RevokeAllByUserID(ctx context.Context, userID int64) error {
+	if err := r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired 清理用户已过期的刷新令牌
+func (r *refreshTokenRepository) DeleteExpired(ctx context.Context, userID int64) error {
+	if err := r.db.WithContext(ctx).
+		Delete(&model.RefreshToken{}, "user_id = ? AND expires_at < ?", userID, time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+	return nil
+}
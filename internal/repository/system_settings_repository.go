@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// SystemSettingsRepository 系统设置仓库接口
+type SystemSettingsRepository interface {
+	Get(ctx context.Context) (*model.SystemSettings, error)
+	Save(ctx context.Context, settings *model.SystemSettings) error
+}
+
+// systemSettingsRepository 系统设置仓库实现
+type systemSettingsRepository struct {
+	db *gorm.DB
+}
+
+// NewSystemSettingsRepository 创建系统设置仓库实例
+func NewSystemSettingsRepository(db *gorm.DB) SystemSettingsRepository {
+	return &systemSettingsRepository{db: db}
+}
+
+// Get 获取系统设置，未保存过时返回默认值
+func (r *systemSettingsRepository) Get(ctx context.Context) (*model.SystemSettings, error) {
+	var settings model.SystemSettings
+	if err := r.db.WithContext(ctx).First(&settings, model.SystemSettingsID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.DefaultSystemSettings(), nil
+		}
+		return nil, fmt.Errorf("failed to get system settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// Save 创建或更新系统设置
+func (r *systemSettingsRepository) Save(ctx context.Context, settings *model.SystemSettings) error {
+	settings.ID = model.SystemSettingsID
+	if err := r.db.WithContext(ctx).Save(settings).Error; err != nil {
+		return fmt.Errorf("failed to save system settings: %w", err)
+	}
+	return nil
+}
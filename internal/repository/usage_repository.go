@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// UsageRepository 用量统计仓库接口（跨表聚合，管理后台使用）
+type UsageRepository interface {
+	GetUserUsage(ctx context.Context, userID int64) (*model.UserUsage, error)
+}
+
+// usageRepository 用量统计仓库实现
+type usageRepository struct {
+	db *gorm.DB
+}
+
+// NewUsageRepository 创建用量统计仓库实例
+func NewUsageRepository(db *gorm.DB) UsageRepository {
+	return &usageRepository{db: db}
+}
+
+// GetUserUsage 统计用户创建的项目、生成记录、资源文件和批量任务
+func (r *usageRepository) GetUserUsage(ctx context.Context, userID int64) (*model.UserUsage, error) {
+	db := r.db.WithContext(ctx)
+	usage := &model.UserUsage{BatchTasks: make(map[string]int64)}
+
+	if err := db.Model(&model.Project{}).Where("user_id = ?", userID).Count(&usage.Projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to count projects: %w", err)
+	}
+	if err := db.Model(&model.Generation{}).Where("user_id = ?", userID).Count(&usage.Generations).Error; err != nil {
+		return nil, fmt.Errorf("failed to count generations: %w", err)
+	}
+
+	var assets struct {
+		Count int64
+		Bytes int64
+	}
+	if err := db.Model(&model.Asset{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(size), 0) AS bytes").
+		Where("user_id = ?", userID).
+		Scan(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to count assets: %w", err)
+	}
+	usage.Assets, usage.AssetBytes = assets.Count, assets.Bytes
+
+	var batches []struct {
+		Status string
+		Count  int64
+		Items  int64
+		Failed int64
+	}
+	if err := db.Model(&model.BatchTask{}).
+		Select("status, COUNT(*) AS count, COALESCE(SUM(total_count), 0) AS items, COALESCE(SUM(failed_count), 0) AS failed").
+		Where("user_id = ?", userID).
+		Group("status").
+		Scan(&batches).Error; err != nil {
+		return nil, fmt.Errorf("failed to count batch tasks: %w", err)
+	}
+	for _, b := range batches {
+		usage.BatchTasks[b.Status] = b.Count
+		usage.BatchItems += b.Items
+		usage.BatchFailedItems += b.Failed
+	}
+
+	var lastLogin struct {
+		At *time.Time
+	}
+	if err := db.Model(&model.LoginAttempt{}).
+		Select("MAX(created_at) AS at").
+		Where("user_id = ? AND success = ?", userID, true).
+		Scan(&lastLogin).Error; err != nil {
+		return nil, fmt.Errorf("failed to get last login: %w", err)
+	}
+	usage.LastLoginAt = lastLogin.At
+
+	return usage, nil
+}
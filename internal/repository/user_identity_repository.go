@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository 外部身份数据仓库接口
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *model.UserIdentity) error
+	GetByIssuerSubject(ctx context.Context, issuer, subject string) (*model.UserIdentity, error)
+	Update(ctx context.Context, identity *model.UserIdentity) error
+}
+
+// userIdentityRepository 外部身份数据仓库实现
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityRepository 创建外部身份仓库实例
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+// Create 关联外部身份
+func (r *userIdentityRepository) Create(ctx context.Context, identity *model.UserIdentity) error {
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+	return nil
+}
+
+// GetByIssuerSubject 根据签发方和 sub 获取外部身份
+func (r *userIdentityRepository) GetByIssuerSubject(ctx context.Context, issuer, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	if err := r.db.WithContext(ctx).
+		Where("issuer = ? AND subject = ?", issuer, subject).
+		First(&identity).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+	return &identity, nil
+}
+
+// Update 更新外部身份
+func (r *userIdentityRepository) Update(ctx context.Context, identity *model.UserIdentity) error {
+	if err := r.db.WithContext(ctx).Save(identity).Error; err != nil {
+		return fmt.Errorf("failed to update user identity: %w", err)
+	}
+	return nil
+}
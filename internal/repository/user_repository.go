@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"copycat/internal/model"
 
@@ -15,7 +16,11 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id int64) (*model.User, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
+	UpdateProfile(ctx context.Context, user *model.User) error
 	Delete(ctx context.Context, id int64) error
+	IncrementTokenVersion(ctx context.Context, id int64) error
+	List(ctx context.Context, keyword string, page, pageSize int) ([]*model.User, int64, error)
+	PromoteAdmins(ctx context.Context, emails []string) (int64, error)
 }
 
 // userRepository 用户数据仓库实现
@@ -45,10 +50,10 @@ func (r *userRepository) GetByID(ctx context.Context, id int64) (*model.User, er
 	return &user, nil
 }
 
-// GetByEmail 根据邮箱获取用户
+// GetByEmail 根据邮箱获取用户（不区分大小写）
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("lower(email) = ?", normalizeEmail(email)).First(&user).Error; err != nil {
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 	return &user, nil
@@ -62,6 +67,14 @@ func (r *userRepository) Update(ctx context.Context, user *model.User) error {
 	return nil
 }
 
+// UpdateProfile 只更新昵称、头像和简介，不会覆盖同时被修改的令牌版本、角色等字段
+func (r *userRepository) UpdateProfile(ctx context.Context, user *model.User) error {
+	if err := r.db.WithContext(ctx).Model(user).Select("nickname", "avatar", "bio", "updated_at").Updates(user).Error; err != nil {
+		return fmt.Errorf("failed to update user profile: %w", err)
+	}
+	return nil
+}
+
 // Delete 删除用户
 func (r *userRepository) Delete(ctx context.Context, id int64) error {
 	if err := r.db.WithContext(ctx).Delete(&model.User{}, id).Error; err != nil {
@@ -69,3 +82,66 @@ func (r *userRepository) Delete(ctx context.Context, id int64) error {
 	}
 	return nil
 }
+
+// IncrementTokenVersion 递增用户令牌版本，使已签发的访问令牌全部失效
+func (r *userRepository) IncrementTokenVersion(ctx context.Context, id int64) error {
+	if err := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ?", id).
+		Update("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		return fmt.Errorf("failed to increment token version: %w", err)
+	}
+	return nil
+}
+
+// List 分页查询用户，keyword 按邮箱或昵称模糊匹配（为空表示不筛选）
+func (r *userRepository) List(ctx context.Context, keyword string, page, pageSize int) ([]*model.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.User{})
+	if keyword = strings.TrimSpace(keyword); keyword != "" {
+		pattern := "%" + escapeLike(keyword) + "%"
+		query = query.Where("email ILIKE ? OR nickname ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	var users []*model.User
+	if err := query.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	return users, total, nil
+}
+
+// escapeLike 转义 LIKE 通配符，关键字按字面匹配
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// PromoteAdmins 把邮箱已验证且在列表中的用户设为管理员，返回新提升的用户数
+func (r *userRepository) PromoteAdmins(ctx context.Context, emails []string) (int64, error) {
+	normalized := make([]string, 0, len(emails))
+	for _, email := range emails {
+		if email = normalizeEmail(email); email != "" {
+			normalized = append(normalized, email)
+		}
+	}
+	if len(normalized) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("lower(email) IN ? AND email_verified AND role <> ?", normalized, model.UserRoleAdmin).
+		Update("role", model.UserRoleAdmin)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to promote admins: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// normalizeEmail 邮箱统一转小写去空格
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
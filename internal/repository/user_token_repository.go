@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// UserTokenRepository 一次性令牌数据仓库接口
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *model.UserToken) error
+	Consume(ctx context.Context, purpose, hash string) (*model.UserToken, error)
+	InvalidateByUserID(ctx context.Context, userID int64, purpose string) error
+	LatestCreatedAt(ctx context.Context, userID int64, purpose string) (*time.Time, error)
+}
+
+// userTokenRepository 一次性令牌数据仓库实现
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository 创建一次性令牌仓库实例
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+// Create 保存一次性令牌
+func (r *userTokenRepository) Create(ctx context.Context, token *model.UserToken) error {
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create user token: %w", err)
+	}
+	return nil
+}
+
+// Consume 标记令牌为已使用并返回令牌；令牌不存在、已使用或已过期时返回 gorm.ErrRecordNotFound
+func (r *userTokenRepository) Consume(ctx context.Context, purpose, hash string) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.UserToken{}).
+			Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("token_hash = ?", hash).First(&token).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume user token: %w", err)
+	}
+	return &token, nil
+}
+
+// InvalidateByUserID 作废用户某一用途的全部未使用令牌
+func (r *userTokenRepository) InvalidateByUserID(ctx context.Context, userID int64, purpose string) error {
+	if err := r.db.WithContext(ctx).Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to invalidate user tokens: %w", err)
+	}
+	return nil
+}
+
+// LatestCreatedAt 用户某一用途最近一次签发令牌的时间（用于限制发信频率），没有记录时返回 nil
+func (r *userTokenRepository) LatestCreatedAt(ctx context.Context, userID int64, purpose string) (*time.Time, error) {
+	var tokens []model.UserToken
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND purpose = ?", userID, purpose).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to get latest user token: %w", err)
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return &tokens[0].CreatedAt, nil
+}
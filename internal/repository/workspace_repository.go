@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"copycat/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkspaceRepository 工作区数据仓库接口
+type WorkspaceRepository interface {
+	Create(ctx context.Context, workspace *model.Workspace) error
+	GetByID(ctx context.Context, id int64) (*model.Workspace, error)
+	ListByUserID(ctx context.Context, userID int64) ([]*model.Workspace, error)
+	Update(ctx context.Context, workspace *model.Workspace) error
+	Delete(ctx context.Context, id int64) error
+	GetPersonal(ctx context.Context, userID int64) (*model.Workspace, error)
+	EnsurePersonal(ctx context.Context, user *model.User) (*model.Workspace, error)
+
+	GetMember(ctx context.Context, workspaceID, userID int64) (*model.WorkspaceMember, error)
+	ListMembers(ctx context.Context, workspaceID int64) ([]*model.WorkspaceMember, error)
+	AddMember(ctx context.Context, member *model.WorkspaceMember) error
+	UpdateMemberRole(ctx context.Context, workspaceID, userID int64, role string) error
+	RemoveMember(ctx context.Context, workspaceID, userID int64) error
+	CountOwners(ctx context.Context, workspaceID int64) (int64, error)
+
+	GetSettings(ctx context.Context, workspaceID int64) (*model.WorkspaceSettings, error)
+	UpsertSettings(ctx context.Context, settings *model.WorkspaceSettings) error
+}
+
+// workspaceRepository 工作区数据仓库实现
+type workspaceRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkspaceRepository 创建工作区仓库实例
+func NewWorkspaceRepository(db *gorm.DB) WorkspaceRepository {
+	return &workspaceRepository{db: db}
+}
+
+// Create 创建工作区，创建者同时成为所有者
+func (r *workspaceRepository) Create(ctx context.Context, workspace *model.Workspace) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(workspace).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.WorkspaceMember{
+			WorkspaceID: workspace.ID,
+			UserID:      workspace.OwnerID,
+			Role:        model.WorkspaceRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取工作区
+func (r *workspaceRepository) GetByID(ctx context.Context, id int64) (*model.Workspace, error) {
+	var workspace model.Workspace
+	if err := r.db.WithContext(ctx).First(&workspace, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("failed to get workspace by id: %w", err)
+	}
+	return &workspace, nil
+}
+
+// ListByUserID 获取用户加入的全部工作区（个人工作区在前）
+func (r *workspaceRepository) ListByUserID(ctx context.Context, userID int64) ([]*model.Workspace, error) {
+	var workspaces []*model.Workspace
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspaces.personal DESC, workspaces.created_at ASC").
+		Find(&workspaces).Error; err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	return workspaces, nil
+}
+
+// Update 更新工作区
+func (r *workspaceRepository) Update(ctx context.Context, workspace *model.Workspace) error {
+	if err := r.db.WithContext(ctx).Save(workspace).Error; err != nil {
+		return fmt.Errorf("failed to update workspace: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除工作区及其成员和共享设置（项目需由调用方先行处理）
+func (r *workspaceRepository) Delete(ctx context.Context, id int64) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.WorkspaceMember{}, "workspace_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.WorkspaceSettings{}, "workspace_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Workspace{}, "id = ?", id).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete workspace: %w", err)
+	}
+	return nil
+}
+
+// GetPersonal 获取用户的个人工作区
+func (r *workspaceRepository) GetPersonal(ctx context.Context, userID int64) (*model.Workspace, error) {
+	var workspace model.Workspace
+	if err := r.db.WithContext(ctx).Where("owner_id = ? AND personal = ?", userID, true).First(&workspace).Error; err != nil {
+		return nil, fmt.Errorf("failed to get personal workspace: %w", err)
+	}
+	return &workspace, nil
+}
+
+// EnsurePersonal 获取用户的个人工作区，不存在时创建
+func (r *workspaceRepository) EnsurePersonal(ctx context.Context, user *model.User) (*model.Workspace, error) {
+	workspace, err := r.GetPersonal(ctx, user.ID)
+	if err == nil {
+		return workspace, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	name := user.Nickname
+	if name == "" {
+		name = user.Email
+	}
+	workspace = &model.Workspace{Name: name + " 的工作区", OwnerID: user.ID, Personal: true}
+	if err := r.Create(ctx, workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// GetMember 获取工作区成员
+func (r *workspaceRepository) GetMember(ctx context.Context, workspaceID, userID int64) (*model.WorkspaceMember, error) {
+	var member model.WorkspaceMember
+	if err := r.db.WithContext(ctx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		First(&member).Error; err != nil {
+		return nil, fmt.Errorf("failed to get workspace member: %w", err)
+	}
+	return &member, nil
+}
+
+// ListMembers 获取工作区成员列表（包含用户信息）
+func (r *workspaceRepository) ListMembers(ctx context.Context, workspaceID int64) ([]*model.WorkspaceMember, error) {
+	var members []*model.WorkspaceMember
+	if err := r.db.WithContext(ctx).
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at ASC").
+		Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to list workspace members: %w", err)
+	}
+	if len(members) == 0 {
+		return members, nil
+	}
+
+	// 手动加载用户信息（不使用外键约束）
+	userIDs := make([]int64, len(members))
+	for i, m := range members {
+		userIDs[i] = m.UserID
+	}
+	var users []*model.User
+	if err := r.db.WithContext(ctx).Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load workspace member users: %w", err)
+	}
+	byID := make(map[int64]*model.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+	for _, m := range members {
+		m.User = byID[m.UserID]
+	}
+	return members, nil
+}
+
+// AddMember 添加工作区成员
+func (r *workspaceRepository) AddMember(ctx context.Context, member *model.WorkspaceMember) error {
+	if err := r.db.WithContext(ctx).Create(member).Error; err != nil {
+		return fmt.Errorf("failed to add workspace member: %w", err)
+	}
+	return nil
+}
+
+// UpdateMemberRole 修改成员角色
+func (r *workspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID, userID int64, role string) error {
+	if err := r.db.WithContext(ctx).Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Update("role", role).Error; err != nil {
+		return fmt.Errorf("failed to update workspace member role: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember 移除工作区成员
+func (r *workspaceRepository) RemoveMember(ctx context.Context, workspaceID, userID int64) error {
+	if err := r.db.WithContext(ctx).
+		Delete(&model.WorkspaceMember{}, "workspace_id = ? AND user_id = ?", workspaceID, userID).Error; err != nil {
+		return fmt.Errorf("failed to remove workspace member: %w", err)
+	}
+	return nil
+}
+
+// CountOwners 统计工作区所有者数量（防止移除最后一个所有者）
+func (r *workspaceRepository) CountOwners(ctx context.Context, workspaceID int64) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, model.WorkspaceRoleOwner).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count workspace owners: %w", err)
+	}
+	return count, nil
+}
+
+// GetSettings 获取工作区共享设置
+func (r *workspaceRepository) GetSettings(ctx context.Context, workspaceID int64) (*model.WorkspaceSettings, error) {
+	var settings model.WorkspaceSettings
+	if err := r.db.WithContext(ctx).Where("workspace_id = ?", workspaceID).First(&settings).Error; err != nil {
+		return nil, fmt.Errorf("failed to get workspace settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// UpsertSettings 创建或更新工作区共享设置
+func (r *workspaceRepository) UpsertSettings(ctx context.Context, settings *model.WorkspaceSettings) error {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "workspace_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"openai_api_key", "deepseek_api_key", "moonshot_api_key", "qwen_api_key",
+			"hunyuan_api_key", "doubao_api_key", "zhipu_api_key", "anthropic_api_key",
+			"tts_api_key", "image_gen_api_key", "updated_by", "updated_at",
+		}),
+	}).Create(settings).Error; err != nil {
+		return fmt.Errorf("failed to save workspace settings: %w", err)
+	}
+	return nil
+}
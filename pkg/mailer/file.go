@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"copycat/pkg/logger"
+)
+
+// DefaultMailDir log 驱动默认的邮件保存目录
+const DefaultMailDir = "data/mail"
+
+// FileMailer 本地测试用：邮件不真正发出，写成 .eml 文件并记录日志（正文中的链接可直接从日志复制）
+type FileMailer struct {
+	from string
+	dir  string
+}
+
+// NewFileMailer 创建文件邮件实例，目录不存在时自动创建
+func NewFileMailer(from, dir string) (*FileMailer, error) {
+	if dir == "" {
+		dir = DefaultMailDir
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail dir: %w", err)
+	}
+	return &FileMailer{from: from, dir: dir}, nil
+}
+
+// Send 保存邮件到文件
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	data, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102-150405.000000"), recipient)
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+
+	logger.Info("[Mail] to=%s subject=%s file=%s\n%s", msg.To, msg.Subject, path, msg.Body)
+	return nil
+}
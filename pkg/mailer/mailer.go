@@ -0,0 +1,89 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// 邮件驱动
+const (
+	DriverLog  = "log"
+	DriverSMTP = "smtp"
+)
+
+// Message 纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config 邮件配置
+type Config struct {
+	Driver              string     `mapstructure:"driver"`               // log（默认，写入本地文件供测试）或 smtp
+	From                string     `mapstructure:"from"`                 // 发件人，如 "CopyCat <noreply@example.com>"
+	LinkBaseURL         string     `mapstructure:"link_base_url"`        // 邮件中验证/重置链接的前端地址
+	RequireVerification bool       `mapstructure:"require_verification"` // 登录前是否必须验证邮箱
+	Dir                 string     `mapstructure:"dir"`                  // log 驱动的邮件保存目录，默认 data/mail
+	SMTP                SMTPConfig `mapstructure:"smtp"`
+}
+
+// SMTPConfig SMTP 服务器配置
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"` // 465 使用隐式 TLS，其他端口在服务器支持时使用 STARTTLS
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// New 根据配置创建邮件发送实例
+func New(cfg Config) (Mailer, error) {
+	if cfg.From == "" {
+		cfg.From = "CopyCat <noreply@localhost>"
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("invalid mail from address: %w", err)
+	}
+	switch cfg.Driver {
+	case "", DriverLog:
+		return NewFileMailer(cfg.From, cfg.Dir)
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.From, cfg.SMTP)
+	}
+	return nil, fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+}
+
+// buildMessage 生成 RFC 5322 邮件内容，主题和正文按 UTF-8 编码
+func buildMessage(from string, msg Message) ([]byte, error) {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, fmt.Errorf("invalid mail header")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes(), nil
+}
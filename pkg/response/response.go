@@ -2,6 +2,8 @@ package response
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,6 +22,7 @@ const (
 	CodeUnauthorized = 401
 	CodeForbidden    = 403
 	CodeNotFound     = 404
+	CodeTooMany      = 429
 	CodeServerError  = 500
 )
 
@@ -70,6 +73,14 @@ func NotFound(c *gin.Context, msg string) {
 	Error(c, http.StatusNotFound, CodeNotFound, msg)
 }
 
+// TooManyRequests 429 错误，retryAfter 大于 0 时设置 Retry-After 头（秒，向上取整）
+func TooManyRequests(c *gin.Context, msg string, retryAfter time.Duration) {
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+	}
+	Error(c, http.StatusTooManyRequests, CodeTooMany, msg)
+}
+
 // ServerError 500 错误
 func ServerError(c *gin.Context, msg string) {
 	Error(c, http.StatusInternalServerError, CodeServerError, msg)
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// 存储驱动
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage 对象存储接口
+type Storage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open 打开对象用于读取，返回的 Object 支持 Seek（用于 HTTP Range 请求）
+	Open(ctx context.Context, key string) (Object, error)
+	// Delete 删除对象，对象不存在时不报错
+	Delete(ctx context.Context, key string) error
+}
+
+// Object 可随机读取的存储对象
+type Object interface {
+	io.ReadSeekCloser
+	Size() int64
+	ModTime() time.Time
+}
+
+// Config 存储配置
+type Config struct {
+	Driver string      `mapstructure:"driver"` // local（默认）或 s3
+	Local  LocalConfig `mapstructure:"local"`
+	S3     S3Config    `mapstructure:"s3"`
+}
+
+// LocalConfig 本地文件系统存储配置
+type LocalConfig struct {
+	Dir string `mapstructure:"dir"` // 存储根目录，默认 data/storage
+}
+
+// S3Config S3 兼容存储配置（AWS S3 / MinIO / 阿里云 OSS 等）
+type S3Config struct {
+	Endpoint     string `mapstructure:"endpoint"`       // 如 https://s3.amazonaws.com 或 http://minio:9000
+	Region       string `mapstructure:"region"`         // 签名使用的区域，默认 us-east-1
+	Bucket       string `mapstructure:"bucket"`         // 存储桶
+	AccessKey    string `mapstructure:"access_key"`     // Access Key ID
+	SecretKey    string `mapstructure:"secret_key"`     // Secret Access Key
+	UsePathStyle bool   `mapstructure:"use_path_style"` // 使用 endpoint/bucket/key 形式（MinIO 需开启）
+}
+
+// New 根据配置创建存储实例
+func New(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return NewLocalStorage(cfg.Local.Dir)
+	case DriverS3:
+		return NewS3Storage(cfg.S3)
+	}
+	return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+}
+
+// CleanKey 规范化对象键，拒绝跳出根目录的路径
+func CleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + strings.ReplaceAll(key, "\\", "/"))
+	cleaned = strings.TrimPrefix(cleaned, "/")
+	if cleaned == "" || cleaned == "." {
+		return "", fmt.Errorf("invalid storage key: %q", key)
+	}
+	return cleaned, nil
+}